@@ -10,6 +10,7 @@ import (
 	"github.com/inclusi-blog/gola-utils/golaerror"
 	"github.com/inclusi-blog/gola-utils/http/request"
 	"github.com/inclusi-blog/gola-utils/logging"
+	"github.com/inclusi-blog/gola-utils/tracing/propagation"
 	"go.opencensus.io/plugin/ochttp"
 )
 
@@ -68,7 +69,7 @@ func mapToEmailRequest(emailDetails models.EmailDetails, encodedContent string,
 
 func sendEmail(context context.Context, emailRequest models.EmailRequest, gatewayURL string) *golaerror.Error {
 	logger := logging.GetLogger(context)
-	httpClient := &http.Client{Transport: &ochttp.Transport{}}
+	httpClient := &http.Client{Transport: &ochttp.Transport{Propagation: propagation.Default()}}
 	responseError := request.NewHttpRequestBuilder(httpClient).
 		NewRequest().
 		WithContext(context).
@@ -83,7 +84,7 @@ func sendEmail(context context.Context, emailRequest models.EmailRequest, gatewa
 
 func sendEmailWithLoggingContext(context context.Context, emailRequest models.EmailRequest, gatewayURL string) *golaerror.Error {
 	logger := logging.GetLogger(context)
-	httpClient := &http.Client{Transport: &ochttp.Transport{}}
+	httpClient := &http.Client{Transport: &ochttp.Transport{Propagation: propagation.Default()}}
 	responseError := request.NewHttpRequestBuilder(httpClient).
 		NewRequest().
 		WithContext(context).
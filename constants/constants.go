@@ -4,11 +4,17 @@ const (
 	TRACE_ID_HTTP_HEADER         = "X-B3-Traceid"
 	LOGGER_KEY                   = "logger"
 	TRACE_KEY                    = "traceID"
-	JSON_FORMATTER_HTTP_HEADER   = "JSON"
+	JSON_FORMATTER_HTTP_HEADER   = "JSON" // no longer read, see logging.ConfigureFormatter
 	NO_TRACE_ID                  = "no-trace-id"
 	JSON                         = "json"
 	TRACING_SESSION_HEADER_KEY   = "Session-Tracing-ID"
 	TRACE_CONFIG_MAX_ANNOTATIONS = 128
+	REQUEST_TIMEOUT_HTTP_HEADER  = "X-Request-Timeout"
+	DEBUG_LOG_HTTP_HEADER        = "X-Debug-Log"
+	LOGFMT                       = "logfmt"
+	CONSOLE                      = "console"
+	LOG_FORMAT_ENV               = "LOG_FORMAT"
+	TRACE_PROPAGATION_ENV        = "TRACE_PROPAGATION"
 
 	TRACING_CLIENT_PUBLIC_IP_HEADER = "X-Original-Forwarded-For"
 	TRACING_CLIENT_PUBLIC_IP        = "client-public-ip"
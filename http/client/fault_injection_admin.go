@@ -0,0 +1,40 @@
+package client
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/inclusi-blog/gola-utils/golaerror"
+	"github.com/inclusi-blog/gola-utils/logging"
+)
+
+const invalidFaultInjectionConfigErrorCode = "ERR_INVALID_FAULT_INJECTION_CONFIG"
+
+// FaultInjectionAdminHandler exposes the configuration of a FaultInjectingClient.
+// GET returns the current configuration, PUT and POST replace it, which can be used
+// to switch injection on and off at runtime.
+func FaultInjectionAdminHandler(client FaultInjectingClient) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		logger := logging.GetLogger(c)
+		switch c.Request.Method {
+		case http.MethodGet:
+			c.JSON(http.StatusOK, client.Config())
+		case http.MethodPut, http.MethodPost:
+			var config FaultInjectionConfig
+			if err := c.ShouldBindJSON(&config); err != nil {
+				logger.Error("fault injection admin - invalid request body ", err)
+				c.AbortWithStatusJSON(http.StatusBadRequest, golaerror.New(invalidFaultInjectionConfigErrorCode, err.Error(), nil))
+				return
+			}
+			if err := client.UpdateConfig(config); err != nil {
+				logger.Error("fault injection admin - invalid configuration ", err)
+				c.AbortWithStatusJSON(http.StatusBadRequest, golaerror.New(invalidFaultInjectionConfigErrorCode, err.Error(), nil))
+				return
+			}
+			logger.Warnf("fault injection admin - configuration updated, enabled: %v, rules: %d", config.Enabled, len(config.Rules))
+			c.JSON(http.StatusOK, client.Config())
+		default:
+			c.AbortWithStatus(http.StatusMethodNotAllowed)
+		}
+	}
+}
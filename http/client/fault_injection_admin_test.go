@@ -0,0 +1,91 @@
+package client
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/inclusi-blog/gola-utils/golaerror"
+	"github.com/inclusi-blog/gola-utils/http/client/mocks"
+	"github.com/stretchr/testify/suite"
+)
+
+type FaultInjectionAdminTest struct {
+	suite.Suite
+	mockCtrl *gomock.Controller
+	client   FaultInjectingClient
+	engine   *gin.Engine
+	recorder *httptest.ResponseRecorder
+}
+
+func TestFaultInjectionAdminTestSuite(t *testing.T) {
+	suite.Run(t, new(FaultInjectionAdminTest))
+}
+
+func (suite *FaultInjectionAdminTest) SetupTest() {
+	suite.mockCtrl = gomock.NewController(suite.T())
+	suite.client, _ = NewFaultInjectingClient(mocks.NewMockHttpClient(suite.mockCtrl), FaultInjectionConfig{
+		Rules: []FaultRule{{Name: "broken", Probability: 1, Fault: ErrorFault}},
+	})
+	suite.engine = gin.New()
+	suite.engine.Any("/admin/fault-injection", FaultInjectionAdminHandler(suite.client))
+	suite.recorder = httptest.NewRecorder()
+}
+
+func (suite *FaultInjectionAdminTest) TearDownTest() {
+	suite.mockCtrl.Finish()
+}
+
+func (suite *FaultInjectionAdminTest) serve(method string, body []byte) {
+	request, _ := http.NewRequest(method, "/admin/fault-injection", bytes.NewReader(body))
+	request.Header.Set("Content-Type", "application/json")
+	suite.engine.ServeHTTP(suite.recorder, request)
+}
+
+func (suite *FaultInjectionAdminTest) TestShouldReturnCurrentConfig() {
+	suite.serve(http.MethodGet, nil)
+
+	var config FaultInjectionConfig
+	_ = json.Unmarshal(suite.recorder.Body.Bytes(), &config)
+	suite.Equal(http.StatusOK, suite.recorder.Code)
+	suite.Equal(suite.client.Config(), config)
+}
+
+func (suite *FaultInjectionAdminTest) TestShouldUpdateConfig() {
+	body, _ := json.Marshal(FaultInjectionConfig{
+		Enabled: true,
+		Rules:   []FaultRule{{Name: "unavailable", Probability: 0.5, Fault: StatusFault, StatusCode: 503}},
+	})
+
+	suite.serve(http.MethodPut, body)
+
+	suite.Equal(http.StatusOK, suite.recorder.Code)
+	suite.True(suite.client.Config().Enabled)
+	suite.Equal("unavailable", suite.client.Config().Rules[0].Name)
+}
+
+func (suite *FaultInjectionAdminTest) TestShouldRejectInvalidConfig() {
+	suite.serve(http.MethodPost, []byte(`{"enabled":true,"rules":[{"name":"status","probability":1,"fault":"status"}]}`))
+
+	var actualError golaerror.Error
+	_ = json.Unmarshal(suite.recorder.Body.Bytes(), &actualError)
+	suite.Equal(http.StatusBadRequest, suite.recorder.Code)
+	suite.Equal(invalidFaultInjectionConfigErrorCode, actualError.ErrorCode)
+	suite.False(suite.client.Config().Enabled)
+}
+
+func (suite *FaultInjectionAdminTest) TestShouldRejectMalformedBody() {
+	suite.serve(http.MethodPut, []byte(`{"enabled":`))
+
+	suite.Equal(http.StatusBadRequest, suite.recorder.Code)
+}
+
+func (suite *FaultInjectionAdminTest) TestShouldNotAllowOtherMethods() {
+	suite.serve(http.MethodDelete, nil)
+
+	suite.Equal(http.StatusMethodNotAllowed, suite.recorder.Code)
+}
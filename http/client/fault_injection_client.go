@@ -0,0 +1,307 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math/rand"
+	"net"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/inclusi-blog/gola-utils/logging"
+	"go.opencensus.io/trace"
+)
+
+type FaultType string
+
+const (
+	LatencyFault         FaultType = "latency"
+	ErrorFault           FaultType = "error"
+	StatusFault          FaultType = "status"
+	TruncatedBodyFault   FaultType = "truncated_body"
+	ConnectionResetFault FaultType = "connection_reset"
+)
+
+const (
+	FaultInjectedHeader    = "X-Fault-Injected"
+	FaultInjectedAttribute = "fault_injected"
+	FaultTypeAttribute     = "fault_type"
+	FaultRuleAttribute     = "fault_rule"
+
+	defaultInjectedErrorMessage = "fault injection: injected error"
+)
+
+type routeTemplateKey struct{}
+
+type loggerKey struct{}
+
+// WithRouteTemplate records the unexpanded URL template of an outbound call so that
+// fault rules can match on it. HttpRequest does this for every call it makes.
+func WithRouteTemplate(ctx context.Context, routeTemplate string) context.Context {
+	return context.WithValue(ctx, routeTemplateKey{}, routeTemplate)
+}
+
+func routeTemplateFrom(ctx context.Context) string {
+	routeTemplate, _ := ctx.Value(routeTemplateKey{}).(string)
+	return routeTemplate
+}
+
+// WithLogger records the logger of the caller of an outbound call, its context is not
+// always the one the request is sent with. HttpRequest does this for every call it makes.
+func WithLogger(ctx context.Context, logger logging.Logger) context.Context {
+	return context.WithValue(ctx, loggerKey{}, logger)
+}
+
+func loggerFrom(ctx context.Context) logging.Logger {
+	if logger, ok := ctx.Value(loggerKey{}).(logging.Logger); ok {
+		return logger
+	}
+	return logging.GetLogger(ctx)
+}
+
+// FaultRule injects a fault into matching calls with the given probability (0 to 1).
+// Host and RouteTemplate are glob patterns, RouteTemplate is matched against the path
+// of the template ("/api/users/{id}" or "/api/users/*"). A header value of "*" only
+// requires the header to be present. Empty matchers match every call.
+type FaultRule struct {
+	Name            string            `json:"name"`
+	Host            string            `json:"host"`
+	RouteTemplate   string            `json:"routeTemplate"`
+	Method          string            `json:"method"`
+	Headers         map[string]string `json:"headers"`
+	Probability     float64           `json:"probability" validate:"min=0,max=1"`
+	Fault           FaultType         `json:"fault" validate:"required,oneof=latency error status truncated_body connection_reset"`
+	LatencyInMillis int               `json:"latencyInMillis"`
+	StatusCode      int               `json:"statusCode"`
+	ResponseBody    string            `json:"responseBody"`
+	TruncateAtBytes int               `json:"truncateAtBytes"`
+	ErrorMessage    string            `json:"errorMessage"`
+}
+
+type FaultInjectionConfig struct {
+	Enabled bool        `json:"enabled"`
+	Rules   []FaultRule `json:"rules" validate:"dive"`
+}
+
+// FaultInjectingClient is an HttpClient meant for non-production environments that
+// injects latency, errors, status codes, truncated bodies and connection resets into
+// outbound calls. Every injected fault is annotated on the current span and logged.
+type FaultInjectingClient interface {
+	HttpClient
+	Config() FaultInjectionConfig
+	UpdateConfig(config FaultInjectionConfig) error
+}
+
+type faultInjectingClient struct {
+	base   HttpClient
+	mutex  sync.RWMutex
+	config FaultInjectionConfig
+	random func() float64
+}
+
+func NewFaultInjectingClient(base HttpClient, config FaultInjectionConfig) (FaultInjectingClient, error) {
+	client := &faultInjectingClient{base: base, random: lockedRandom()}
+	if err := client.UpdateConfig(config); err != nil {
+		return nil, err
+	}
+	return client, nil
+}
+
+func lockedRandom() func() float64 {
+	var mutex sync.Mutex
+	source := rand.New(rand.NewSource(time.Now().UnixNano()))
+	return func() float64 {
+		mutex.Lock()
+		defer mutex.Unlock()
+		return source.Float64()
+	}
+}
+
+func (c *faultInjectingClient) Config() FaultInjectionConfig {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	rules := make([]FaultRule, len(c.config.Rules))
+	copy(rules, c.config.Rules)
+	return FaultInjectionConfig{Enabled: c.config.Enabled, Rules: rules}
+}
+
+func (c *faultInjectingClient) UpdateConfig(config FaultInjectionConfig) error {
+	for _, rule := range config.Rules {
+		if err := rule.validate(); err != nil {
+			return err
+		}
+	}
+	rules := make([]FaultRule, len(config.Rules))
+	copy(rules, config.Rules)
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.config = FaultInjectionConfig{Enabled: config.Enabled, Rules: rules}
+	return nil
+}
+
+func (c *faultInjectingClient) Do(req *http.Request) (*http.Response, error) {
+	rule, isInjected := c.selectRule(req)
+	if !isInjected {
+		return c.base.Do(req)
+	}
+	markInjectedFault(req, rule)
+
+	switch rule.Fault {
+	case LatencyFault:
+		select {
+		case <-req.Context().Done():
+			return nil, req.Context().Err()
+		case <-time.After(time.Duration(rule.LatencyInMillis) * time.Millisecond):
+		}
+		return c.base.Do(req)
+	case ErrorFault:
+		message := rule.ErrorMessage
+		if message == "" {
+			message = defaultInjectedErrorMessage
+		}
+		return nil, errors.New(message)
+	case StatusFault:
+		return injectedResponse(req, rule), nil
+	case TruncatedBodyFault:
+		response, err := c.base.Do(req)
+		if err != nil {
+			return response, err
+		}
+		response.Body = &truncatedBody{body: response.Body, remaining: int64(rule.TruncateAtBytes)}
+		if response.Header == nil {
+			response.Header = http.Header{}
+		}
+		response.Header.Set(FaultInjectedHeader, rule.Name)
+		return response, nil
+	default:
+		return nil, &net.OpError{Op: "read", Net: "tcp", Err: syscall.ECONNRESET}
+	}
+}
+
+func (c *faultInjectingClient) selectRule(req *http.Request) (FaultRule, bool) {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	if !c.config.Enabled {
+		return FaultRule{}, false
+	}
+	for _, rule := range c.config.Rules {
+		if rule.matches(req) && c.random() < rule.Probability {
+			return rule, true
+		}
+	}
+	return FaultRule{}, false
+}
+
+func (rule FaultRule) validate() error {
+	if rule.Probability < 0 || rule.Probability > 1 {
+		return fmt.Errorf("fault rule %q: probability must be between 0 and 1", rule.Name)
+	}
+	switch rule.Fault {
+	case LatencyFault, ErrorFault, TruncatedBodyFault, ConnectionResetFault:
+	case StatusFault:
+		if rule.StatusCode < 100 || rule.StatusCode > 599 {
+			return fmt.Errorf("fault rule %q: invalid status code %d", rule.Name, rule.StatusCode)
+		}
+	default:
+		return fmt.Errorf("fault rule %q: not a valid fault type %q", rule.Name, rule.Fault)
+	}
+	for _, pattern := range []string{rule.Host, rule.RouteTemplate} {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("fault rule %q: invalid pattern %q", rule.Name, pattern)
+		}
+	}
+	return nil
+}
+
+func (rule FaultRule) matches(req *http.Request) bool {
+	if rule.Method != "" && !strings.EqualFold(rule.Method, req.Method) {
+		return false
+	}
+	if rule.Host != "" {
+		if matched, _ := path.Match(rule.Host, req.URL.Hostname()); !matched {
+			return false
+		}
+	}
+	if rule.RouteTemplate != "" && !matchesRouteTemplate(rule.RouteTemplate, req) {
+		return false
+	}
+	for key, value := range rule.Headers {
+		actual, isPresent := req.Header[http.CanonicalHeaderKey(key)]
+		if !isPresent || (value != "*" && (len(actual) == 0 || actual[0] != value)) {
+			return false
+		}
+	}
+	return true
+}
+
+func matchesRouteTemplate(pattern string, req *http.Request) bool {
+	routeTemplate := routeTemplateFrom(req.Context())
+	if routeTemplate == "" {
+		routeTemplate = req.URL.Path
+	} else if parsedTemplate, err := url.Parse(routeTemplate); err == nil && parsedTemplate.Path != "" {
+		routeTemplate = parsedTemplate.Path
+	}
+	matched, _ := path.Match(pattern, routeTemplate)
+	return matched
+}
+
+func markInjectedFault(req *http.Request, rule FaultRule) {
+	span := trace.FromContext(req.Context())
+	span.AddAttributes(trace.BoolAttribute(FaultInjectedAttribute, true))
+	span.Annotate([]trace.Attribute{
+		trace.StringAttribute(FaultTypeAttribute, string(rule.Fault)),
+		trace.StringAttribute(FaultRuleAttribute, rule.Name),
+	}, "fault injected")
+	loggerFrom(req.Context()).
+		WithField(FaultTypeAttribute, rule.Fault).
+		WithField(FaultRuleAttribute, rule.Name).
+		Warnf("fault injection - injecting %s into %s %s", rule.Fault, req.Method, req.URL.String())
+}
+
+func injectedResponse(req *http.Request, rule FaultRule) *http.Response {
+	header := http.Header{}
+	header.Set(FaultInjectedHeader, rule.Name)
+	return &http.Response{
+		Status:        strconv.Itoa(rule.StatusCode) + " " + http.StatusText(rule.StatusCode),
+		StatusCode:    rule.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          ioutil.NopCloser(bytes.NewBufferString(rule.ResponseBody)),
+		ContentLength: int64(len(rule.ResponseBody)),
+		Request:       req,
+	}
+}
+
+type truncatedBody struct {
+	body      io.ReadCloser
+	remaining int64
+}
+
+func (t *truncatedBody) Read(p []byte) (int, error) {
+	if t.remaining <= 0 {
+		return 0, io.ErrUnexpectedEOF
+	}
+	if int64(len(p)) > t.remaining {
+		p = p[:t.remaining]
+	}
+	n, err := t.body.Read(p)
+	t.remaining -= int64(n)
+	return n, err
+}
+
+func (t *truncatedBody) Close() error {
+	return t.body.Close()
+}
@@ -0,0 +1,222 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/inclusi-blog/gola-utils/constants"
+	"github.com/inclusi-blog/gola-utils/http/client/mocks"
+	"github.com/inclusi-blog/gola-utils/logging"
+	"github.com/sirupsen/logrus"
+	logrusTest "github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/suite"
+)
+
+type FaultInjectionClientTest struct {
+	suite.Suite
+	mockCtrl       *gomock.Controller
+	mockHttpClient *mocks.MockHttpClient
+}
+
+func TestFaultInjectionClientTestSuite(t *testing.T) {
+	suite.Run(t, new(FaultInjectionClientTest))
+}
+
+func (suite *FaultInjectionClientTest) SetupTest() {
+	suite.mockCtrl = gomock.NewController(suite.T())
+	suite.mockHttpClient = mocks.NewMockHttpClient(suite.mockCtrl)
+}
+
+func (suite *FaultInjectionClientTest) TearDownTest() {
+	suite.mockCtrl.Finish()
+}
+
+func (suite *FaultInjectionClientTest) newClient(rules ...FaultRule) FaultInjectingClient {
+	client, err := NewFaultInjectingClient(suite.mockHttpClient, FaultInjectionConfig{Enabled: true, Rules: rules})
+	suite.Nil(err)
+	return client
+}
+
+func (suite *FaultInjectionClientTest) newRequest(method, url string) *http.Request {
+	request, _ := http.NewRequest(method, url, nil)
+	return request
+}
+
+func (suite *FaultInjectionClientTest) okResponse(body string) *http.Response {
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{},
+		Body:       ioutil.NopCloser(bytes.NewBufferString(body)),
+	}
+}
+
+func (suite *FaultInjectionClientTest) TestShouldReturnInjectedStatus() {
+	client := suite.newClient(FaultRule{Name: "unavailable", Probability: 1, Fault: StatusFault, StatusCode: 503, ResponseBody: `{"errorCode":"ERR"}`})
+
+	response, err := client.Do(suite.newRequest("GET", "http://user-service/api/users/1"))
+
+	suite.Nil(err)
+	suite.Equal(http.StatusServiceUnavailable, response.StatusCode)
+	suite.Equal("unavailable", response.Header.Get(FaultInjectedHeader))
+	body, _ := ioutil.ReadAll(response.Body)
+	suite.Equal(`{"errorCode":"ERR"}`, string(body))
+}
+
+func (suite *FaultInjectionClientTest) TestShouldReturnInjectedError() {
+	client := suite.newClient(FaultRule{Name: "broken", Probability: 1, Fault: ErrorFault, ErrorMessage: "dial tcp: no such host"})
+
+	response, err := client.Do(suite.newRequest("GET", "http://user-service/api/users/1"))
+
+	suite.Nil(response)
+	suite.EqualError(err, "dial tcp: no such host")
+}
+
+func (suite *FaultInjectionClientTest) TestShouldReturnConnectionReset() {
+	client := suite.newClient(FaultRule{Name: "reset", Probability: 1, Fault: ConnectionResetFault})
+
+	_, err := client.Do(suite.newRequest("GET", "http://user-service/api/users/1"))
+
+	var opError *net.OpError
+	suite.True(errors.As(err, &opError))
+	suite.Equal(syscall.ECONNRESET, opError.Err)
+}
+
+func (suite *FaultInjectionClientTest) TestShouldTruncateResponseBody() {
+	suite.mockHttpClient.EXPECT().Do(gomock.Any()).Return(suite.okResponse("0123456789"), nil)
+	client := suite.newClient(FaultRule{Name: "truncated", Probability: 1, Fault: TruncatedBodyFault, TruncateAtBytes: 4})
+
+	response, err := client.Do(suite.newRequest("GET", "http://user-service/api/users/1"))
+
+	suite.Nil(err)
+	body, readError := ioutil.ReadAll(response.Body)
+	suite.Equal("0123", string(body))
+	suite.Equal(io.ErrUnexpectedEOF, readError)
+	suite.Equal("truncated", response.Header.Get(FaultInjectedHeader))
+}
+
+func (suite *FaultInjectionClientTest) TestShouldDelayCallWithInjectedLatency() {
+	suite.mockHttpClient.EXPECT().Do(gomock.Any()).Return(suite.okResponse(""), nil)
+	client := suite.newClient(FaultRule{Name: "slow", Probability: 1, Fault: LatencyFault, LatencyInMillis: 20})
+
+	startedAt := time.Now()
+	response, err := client.Do(suite.newRequest("GET", "http://user-service/api/users/1"))
+
+	suite.Nil(err)
+	suite.Equal(http.StatusOK, response.StatusCode)
+	suite.True(time.Since(startedAt) >= 20*time.Millisecond)
+}
+
+func (suite *FaultInjectionClientTest) TestShouldStopInjectedLatencyWhenContextIsDone() {
+	client := suite.newClient(FaultRule{Name: "slow", Probability: 1, Fault: LatencyFault, LatencyInMillis: 10000})
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	_, err := client.Do(suite.newRequest("GET", "http://user-service/api/users/1").WithContext(ctx))
+
+	suite.Equal(context.DeadlineExceeded, err)
+}
+
+func (suite *FaultInjectionClientTest) TestShouldMatchOnRouteTemplateHostMethodAndHeaders() {
+	client := suite.newClient(FaultRule{
+		Name:          "users",
+		Host:          "user-*",
+		RouteTemplate: "/api/users/{id}",
+		Method:        "get",
+		Headers:       map[string]string{"x-tenant": "*"},
+		Probability:   1,
+		Fault:         StatusFault,
+		StatusCode:    500,
+	})
+	request := suite.newRequest("GET", "http://user-service/api/users/1")
+	request.Header.Set("X-Tenant", "acme")
+	request = request.WithContext(WithRouteTemplate(request.Context(), "http://user-service/api/users/{id}"))
+
+	response, err := client.Do(request)
+
+	suite.Nil(err)
+	suite.Equal(http.StatusInternalServerError, response.StatusCode)
+}
+
+func (suite *FaultInjectionClientTest) TestShouldLogInjectedFaultWithLoggerOfCaller() {
+	hook := logrusTest.NewLocal(logrus.StandardLogger())
+	defer logrus.StandardLogger().ReplaceHooks(make(logrus.LevelHooks))
+	client := suite.newClient(FaultRule{Name: "unavailable", Probability: 1, Fault: StatusFault, StatusCode: 503})
+	request := suite.newRequest("GET", "http://user-service/api/users/1")
+	logger := logging.NewLoggerEntry().WithField(constants.TRACE_KEY, "trace-1")
+	request = request.WithContext(WithLogger(request.Context(), logger))
+
+	_, err := client.Do(request)
+
+	suite.Nil(err)
+	suite.Equal("fault injection - injecting status into GET http://user-service/api/users/1", hook.LastEntry().Message)
+	suite.Equal("trace-1", hook.LastEntry().Data[constants.TRACE_KEY])
+}
+
+func (suite *FaultInjectionClientTest) TestShouldPassThroughWhenRuleDoesNotMatch() {
+	suite.mockHttpClient.EXPECT().Do(gomock.Any()).Return(suite.okResponse(""), nil).Times(3)
+	client := suite.newClient(
+		FaultRule{Name: "other-host", Host: "order-service", Probability: 1, Fault: ErrorFault},
+		FaultRule{Name: "post-only", Method: "POST", Probability: 1, Fault: ErrorFault},
+		FaultRule{Name: "tenant", Headers: map[string]string{"X-Tenant": "acme"}, Probability: 1, Fault: ErrorFault},
+	)
+
+	for i := 0; i < 3; i++ {
+		response, err := client.Do(suite.newRequest("GET", "http://user-service/api/users/1"))
+		suite.Nil(err)
+		suite.Equal(http.StatusOK, response.StatusCode)
+	}
+}
+
+func (suite *FaultInjectionClientTest) TestShouldPassThroughWhenProbabilityIsNotHit() {
+	suite.mockHttpClient.EXPECT().Do(gomock.Any()).Return(suite.okResponse(""), nil)
+	client := suite.newClient(FaultRule{Name: "never", Probability: 0, Fault: ErrorFault})
+
+	_, err := client.Do(suite.newRequest("GET", "http://user-service/api/users/1"))
+
+	suite.Nil(err)
+}
+
+func (suite *FaultInjectionClientTest) TestShouldPassThroughWhenDisabledAtRuntime() {
+	suite.mockHttpClient.EXPECT().Do(gomock.Any()).Return(suite.okResponse(""), nil)
+	client := suite.newClient(FaultRule{Name: "broken", Probability: 1, Fault: ErrorFault})
+	config := client.Config()
+	config.Enabled = false
+
+	suite.Nil(client.UpdateConfig(config))
+	_, err := client.Do(suite.newRequest("GET", "http://user-service/api/users/1"))
+
+	suite.Nil(err)
+}
+
+func (suite *FaultInjectionClientTest) TestShouldRejectInvalidRules() {
+	_, err := NewFaultInjectingClient(suite.mockHttpClient, FaultInjectionConfig{Rules: []FaultRule{{Name: "status", Probability: 1, Fault: StatusFault}}})
+	suite.EqualError(err, `fault rule "status": invalid status code 0`)
+
+	_, err = NewFaultInjectingClient(suite.mockHttpClient, FaultInjectionConfig{Rules: []FaultRule{{Name: "unknown", Probability: 1, Fault: "explode"}}})
+	suite.EqualError(err, `fault rule "unknown": not a valid fault type "explode"`)
+
+	_, err = NewFaultInjectingClient(suite.mockHttpClient, FaultInjectionConfig{Rules: []FaultRule{{Name: "probability", Probability: 2, Fault: ErrorFault}}})
+	suite.EqualError(err, `fault rule "probability": probability must be between 0 and 1`)
+
+	_, err = NewFaultInjectingClient(suite.mockHttpClient, FaultInjectionConfig{Rules: []FaultRule{{Name: "pattern", Host: "[", Probability: 1, Fault: ErrorFault}}})
+	suite.EqualError(err, `fault rule "pattern": invalid pattern "["`)
+}
+
+func (suite *FaultInjectionClientTest) TestShouldKeepPreviousConfigWhenUpdateIsInvalid() {
+	client := suite.newClient(FaultRule{Name: "broken", Probability: 1, Fault: ErrorFault})
+
+	err := client.UpdateConfig(FaultInjectionConfig{Rules: []FaultRule{{Name: "status", Fault: StatusFault}}})
+
+	suite.NotNil(err)
+	suite.True(client.Config().Enabled)
+	suite.Equal("broken", client.Config().Rules[0].Name)
+}
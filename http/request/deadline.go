@@ -0,0 +1,61 @@
+package request
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/inclusi-blog/gola-utils/constants"
+	"github.com/inclusi-blog/gola-utils/http/util"
+)
+
+// withDeadline derives the context the request runs with from parentContext, so that
+// an inherited deadline is enforced and not only propagated, with the timeout of
+// WithTimeout applied on top. The returned cancel func has to be called once the
+// response has been read.
+func (r httpRequest) withDeadline() (httpRequest, context.CancelFunc) {
+	var cancel context.CancelFunc
+	if r.timeout > 0 {
+		r.deadlineCtx, cancel = context.WithTimeout(r.parentContext(), r.timeout)
+	} else {
+		r.deadlineCtx, cancel = context.WithCancel(r.parentContext())
+	}
+	return r, cancel
+}
+
+func (r httpRequest) tracingContext() context.Context {
+	if r.deadlineCtx != nil {
+		return r.deadlineCtx
+	}
+	return r.ctx
+}
+
+// parentContext is the context whose deadline applies to the outbound call. A gin
+// context never reports a deadline itself, the incoming request context does.
+func (r httpRequest) parentContext() context.Context {
+	if r.ctx == nil {
+		return context.Background()
+	}
+	if ginContext, ok := r.ctx.(*gin.Context); ok && ginContext.Request != nil {
+		return ginContext.Request.Context()
+	}
+	return r.ctx
+}
+
+func (r httpRequest) addDeadline(httpRequest *http.Request) error {
+	deadlineCtx := r.deadlineCtx
+	if deadlineCtx == nil {
+		deadlineCtx = r.parentContext()
+	}
+	deadline, hasDeadline := deadlineCtx.Deadline()
+	if !hasDeadline {
+		return nil
+	}
+	remaining := time.Until(deadline)
+	if remaining <= 0 {
+		return context.DeadlineExceeded
+	}
+	httpRequest.Header.Set(constants.REQUEST_TIMEOUT_HTTP_HEADER, util.EncodeTimeout(remaining))
+	return nil
+}
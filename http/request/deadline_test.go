@@ -0,0 +1,151 @@
+package request
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/inclusi-blog/gola-utils/constants"
+	"github.com/inclusi-blog/gola-utils/http/client"
+	"github.com/inclusi-blog/gola-utils/http/client/mocks"
+	"github.com/inclusi-blog/gola-utils/http/util"
+	"github.com/inclusi-blog/gola-utils/logging"
+	"github.com/sirupsen/logrus"
+	logrusTest "github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/suite"
+)
+
+type DeadlineTestSuite struct {
+	suite.Suite
+	mockCtrl           *gomock.Controller
+	mockHttpClient     *mocks.MockHttpClient
+	httpRequestBuilder HttpRequestBuilder
+	url                string
+}
+
+func TestDeadlineTestSuite(t *testing.T) {
+	suite.Run(t, new(DeadlineTestSuite))
+}
+
+func (suite *DeadlineTestSuite) SetupTest() {
+	suite.mockCtrl = gomock.NewController(suite.T())
+	suite.mockHttpClient = mocks.NewMockHttpClient(suite.mockCtrl)
+	suite.httpRequestBuilder = NewHttpRequestBuilder(suite.mockHttpClient)
+	suite.url = "http://dummyurl.com/resources"
+}
+
+func (suite *DeadlineTestSuite) TearDownTest() {
+	suite.mockCtrl.Finish()
+}
+
+func (suite *DeadlineTestSuite) expectRemainingBudget(max time.Duration) {
+	suite.mockHttpClient.EXPECT().Do(gomock.Any()).DoAndReturn(func(actualRequest *http.Request) (*http.Response, error) {
+		_, hasDeadline := actualRequest.Context().Deadline()
+		suite.True(hasDeadline)
+		budget, err := util.DecodeTimeout(actualRequest.Header.Get(constants.REQUEST_TIMEOUT_HTTP_HEADER))
+		suite.Nil(err)
+		suite.True(budget > 0 && budget <= max)
+		return &http.Response{StatusCode: http.StatusOK, Body: ioutil.NopCloser(bytes.NewBufferString(""))}, nil
+	})
+}
+
+func (suite *DeadlineTestSuite) TestShouldSendRemainingBudgetForRequestTimeout() {
+	suite.expectRemainingBudget(2 * time.Second)
+
+	err := suite.httpRequestBuilder.
+		NewRequest().
+		WithTimeout(2 * time.Second).
+		Get(suite.url)
+
+	suite.Nil(err)
+}
+
+func (suite *DeadlineTestSuite) TestShouldUseShorterDeadlineInheritedFromGinRequest() {
+	suite.expectRemainingBudget(time.Second)
+	ginContext, _ := gin.CreateTestContext(httptest.NewRecorder())
+	requestCtx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	ginContext.Request, _ = http.NewRequest("GET", "/incoming", nil)
+	ginContext.Request = ginContext.Request.WithContext(requestCtx)
+
+	err := suite.httpRequestBuilder.
+		NewRequestWithContext(ginContext).
+		WithTimeout(time.Minute).
+		Get(suite.url)
+
+	suite.Nil(err)
+}
+
+func (suite *DeadlineTestSuite) TestShouldCancelRequestOnDeadlineInheritedFromGinRequest() {
+	suite.mockHttpClient.EXPECT().Do(gomock.Any()).DoAndReturn(func(actualRequest *http.Request) (*http.Response, error) {
+		select {
+		case <-actualRequest.Context().Done():
+			return nil, actualRequest.Context().Err()
+		case <-time.After(time.Second):
+			return &http.Response{StatusCode: http.StatusOK, Body: ioutil.NopCloser(bytes.NewBufferString(""))}, nil
+		}
+	})
+	ginContext, _ := gin.CreateTestContext(httptest.NewRecorder())
+	requestCtx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	ginContext.Request, _ = http.NewRequest("GET", "/incoming", nil)
+	ginContext.Request = ginContext.Request.WithContext(requestCtx)
+
+	err := suite.httpRequestBuilder.
+		NewRequestWithContext(ginContext).
+		Get(suite.url)
+
+	suite.Equal(context.DeadlineExceeded, err)
+}
+
+func (suite *DeadlineTestSuite) TestShouldFailWithoutCallingServiceWhenDeadlineHasPassed() {
+	ctx, cancel := context.WithDeadline(context.Background(), time.Now().Add(-time.Second))
+	defer cancel()
+
+	err := suite.httpRequestBuilder.
+		NewRequestWithContext(ctx).
+		Get(suite.url)
+
+	suite.Equal(context.DeadlineExceeded, err)
+}
+
+func (suite *DeadlineTestSuite) TestShouldNotSendBudgetWithoutDeadline() {
+	suite.mockHttpClient.EXPECT().Do(gomock.Any()).DoAndReturn(func(actualRequest *http.Request) (*http.Response, error) {
+		suite.Equal("", actualRequest.Header.Get(constants.REQUEST_TIMEOUT_HTTP_HEADER))
+		return &http.Response{StatusCode: http.StatusOK, Body: ioutil.NopCloser(bytes.NewBufferString(""))}, nil
+	})
+
+	err := suite.httpRequestBuilder.
+		NewRequestWithContext(context.Background()).
+		Get(suite.url)
+
+	suite.Nil(err)
+}
+
+func (suite *DeadlineTestSuite) TestShouldPassLoggerOfGinContextToClientWithDeadline() {
+	hook := logrusTest.NewLocal(logrus.StandardLogger())
+	defer logrus.StandardLogger().ReplaceHooks(make(logrus.LevelHooks))
+	faultInjectingClient, err := client.NewFaultInjectingClient(suite.mockHttpClient, client.FaultInjectionConfig{
+		Enabled: true,
+		Rules:   []client.FaultRule{{Name: "ok", Probability: 1, Fault: client.StatusFault, StatusCode: http.StatusOK}},
+	})
+	suite.Nil(err)
+	ginContext, _ := gin.CreateTestContext(httptest.NewRecorder())
+	ginContext.Request, _ = http.NewRequest("GET", "/incoming", nil)
+	ginContext.Set(constants.LOGGER_KEY, logging.NewLoggerEntry().WithField(constants.TRACE_KEY, "trace-1"))
+
+	err = NewHttpRequestBuilder(faultInjectingClient).
+		NewRequestWithContext(ginContext).
+		WithTimeout(time.Minute).
+		Get(suite.url)
+
+	suite.Nil(err)
+	suite.Equal("fault injection - injecting status into GET http://dummyurl.com/resources", hook.LastEntry().Message)
+	suite.Equal("trace-1", hook.LastEntry().Data[constants.TRACE_KEY])
+}
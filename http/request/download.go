@@ -0,0 +1,318 @@
+package request
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	utilError "github.com/inclusi-blog/gola-utils/golaerror"
+	"github.com/inclusi-blog/gola-utils/logging"
+	"github.com/inclusi-blog/gola-utils/trace"
+)
+
+const (
+	DefaultDownloadRetries       = 3
+	DefaultDownloadRetryInterval = time.Second
+
+	downloadedBytesAttribute = "downloaded_bytes"
+	totalBytesAttribute      = "total_bytes"
+	attemptAttribute         = "attempt"
+)
+
+type DownloadProgressFunc func(downloadedBytes, totalBytes int64)
+
+// DownloadOptions configures Download and DownloadToFile. MaxRetries and RetryInterval
+// default to DefaultDownloadRetries and DefaultDownloadRetryInterval when left empty,
+// a negative MaxRetries disables resuming. When ChecksumHeader is set and the server
+// sends that header, the downloaded content is verified against it using ChecksumHash
+// (sha256 by default). The header value may be hex or base64 encoded and may carry an
+// algorithm prefix such as "sha-256=".
+type DownloadOptions struct {
+	MaxRetries     int
+	RetryInterval  time.Duration
+	ChecksumHeader string
+	ChecksumHash   func() hash.Hash
+	Progress       DownloadProgressFunc
+}
+
+type truncater interface {
+	Truncate(size int64) error
+}
+
+type downloadState struct {
+	offset    int64
+	total     int64
+	validator string
+	checksum  string
+	hash      hash.Hash
+}
+
+func (state *downloadState) restart(newHash func() hash.Hash) {
+	state.offset = 0
+	state.total = -1
+	state.validator = ""
+	state.checksum = ""
+	state.hash = newHash()
+}
+
+type offsetWriter struct {
+	destination io.WriterAt
+	state       *downloadState
+	progress    DownloadProgressFunc
+}
+
+func (w offsetWriter) Write(data []byte) (int, error) {
+	written, err := w.destination.WriteAt(data, w.state.offset)
+	w.state.hash.Write(data[:written])
+	w.state.offset += int64(written)
+	if w.progress != nil {
+		w.progress(w.state.offset, w.state.total)
+	}
+	return written, err
+}
+
+func (r httpRequest) DownloadToFile(url string, filePath string, options DownloadOptions) error {
+	file, openError := os.OpenFile(filePath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if openError != nil {
+		return openError
+	}
+	downloadError := r.Download(url, file, options)
+	if closeError := file.Close(); closeError != nil && downloadError == nil {
+		return closeError
+	}
+	return downloadError
+}
+
+// Download streams a GET response into destination. When the transfer fails midway it
+// is resumed with a Range request, guarded by If-Range so that a changed resource is
+// downloaded again from the start instead of being stitched together. Every attempt is
+// traced in a span of the download, a child of the span of the caller.
+func (r httpRequest) Download(url string, destination io.WriterAt, options DownloadOptions) error {
+	r.pathTemplate = url
+	r, cancel := r.withDeadline()
+	defer cancel()
+	options = withDownloadDefaults(options)
+	ctx, span := r.downloadSpan()
+	defer span.End()
+	logger := logging.NewLoggerEntry()
+	if r.ctx != nil {
+		logger = logging.GetLogger(r.ctx)
+	}
+
+	state := &downloadState{}
+	state.restart(options.ChecksumHash)
+	span.Annotate([]trace.Attribute{trace.StringAttribute("url", url)}, "download started")
+
+	var lastError error
+	for attempt := 0; attempt <= options.MaxRetries; attempt++ {
+		if attempt > 0 {
+			logger.Warnf("Download of %s interrupted at %d bytes, retrying: %v", url, state.offset, lastError)
+			if waitError := r.waitBeforeRetry(options.RetryInterval); waitError != nil {
+				return waitError
+			}
+			span.Annotate([]trace.Attribute{
+				trace.Int64Attribute(attemptAttribute, int64(attempt)),
+				trace.Int64Attribute(downloadedBytesAttribute, state.offset),
+			}, "download resumed")
+		}
+
+		completed, retryable, downloadError := r.downloadAttempt(ctx, destination, state, options)
+		if completed {
+			return r.completeDownload(destination, state, span)
+		}
+		lastError = downloadError
+		if !retryable {
+			break
+		}
+	}
+
+	span.Annotate([]trace.Attribute{
+		trace.Int64Attribute(downloadedBytesAttribute, state.offset),
+		trace.BoolAttribute(ErrorTraceAttribute, true),
+	}, "download failed: "+lastError.Error())
+	return lastError
+}
+
+func withDownloadDefaults(options DownloadOptions) DownloadOptions {
+	if options.MaxRetries == 0 {
+		options.MaxRetries = DefaultDownloadRetries
+	}
+	if options.MaxRetries < 0 {
+		options.MaxRetries = 0
+	}
+	if options.RetryInterval == 0 {
+		options.RetryInterval = DefaultDownloadRetryInterval
+	}
+	if options.ChecksumHash == nil {
+		options.ChecksumHash = sha256.New
+	}
+	return options
+}
+
+// downloadSpan starts the span of a download, its context keeps the deadline of the
+// request. Requests without a context are not traced.
+func (r httpRequest) downloadSpan() (context.Context, trace.Span) {
+	if r.ctx == nil {
+		return context.Background(), trace.FromContext(context.Background())
+	}
+	ctx := r.parentContext()
+	if r.deadlineCtx != nil {
+		ctx = r.deadlineCtx
+	}
+	return r.trace.StartTracing(ctx, r.extractPath()+" | download")
+}
+
+func (r httpRequest) waitBeforeRetry(interval time.Duration) error {
+	if r.ctx == nil {
+		time.Sleep(interval)
+		return nil
+	}
+	select {
+	case <-r.ctx.Done():
+		return r.ctx.Err()
+	case <-time.After(interval):
+		return nil
+	}
+}
+
+// downloadAttempt performs a single request, resuming from state.offset when possible.
+// It reports whether the download completed and whether a failure may be retried.
+func (r httpRequest) downloadAttempt(ctx context.Context, destination io.WriterAt, state *downloadState, options DownloadOptions) (bool, bool, error) {
+	httpRequest, buildError := r.buildHttpRequest(http.MethodGet)
+	if buildError != nil {
+		return false, false, buildError
+	}
+	if r.ctx != nil {
+		_, httpRequest = r.trace.Continue(ctx, httpRequest)
+	}
+	if state.offset > 0 {
+		httpRequest.Header.Set("Range", fmt.Sprintf("bytes=%d-", state.offset))
+		if state.validator != "" {
+			httpRequest.Header.Set("If-Range", state.validator)
+		}
+	}
+
+	response, httpError := r.doRequest(httpRequest)
+	if httpError != nil {
+		return false, true, httpError
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		return false, response.StatusCode >= 500, utilError.HttpError{StatusCode: response.StatusCode}
+	}
+	if r.responseStatusCode != nil {
+		*r.responseStatusCode = response.StatusCode
+	}
+	if r.responseHeaders != nil {
+		*r.responseHeaders = response.Header
+	}
+
+	if response.StatusCode == http.StatusPartialContent && state.offset > 0 {
+		start, total, rangeError := parseContentRange(response.Header.Get("Content-Range"))
+		if rangeError != nil {
+			return false, false, rangeError
+		}
+		if start != state.offset {
+			resumeError := fmt.Errorf("server resumed download at byte %d instead of %d", start, state.offset)
+			state.restart(options.ChecksumHash)
+			return false, true, resumeError
+		}
+		state.total = total
+	} else {
+		state.restart(options.ChecksumHash)
+		state.total = response.ContentLength
+	}
+
+	if validator := rangeValidator(response.Header); validator != "" {
+		state.validator = validator
+	}
+	if options.ChecksumHeader != "" {
+		if checksum := response.Header.Get(options.ChecksumHeader); checksum != "" {
+			state.checksum = checksum
+		}
+	}
+
+	writer := offsetWriter{destination: destination, state: state, progress: options.Progress}
+	if _, copyError := io.Copy(writer, response.Body); copyError != nil {
+		return false, true, copyError
+	}
+
+	if state.total >= 0 && state.offset < state.total {
+		return false, true, fmt.Errorf("connection closed after %d of %d bytes", state.offset, state.total)
+	}
+	if state.total >= 0 && state.offset > state.total {
+		return false, false, fmt.Errorf("received %d bytes but Content-Length was %d", state.offset, state.total)
+	}
+	return true, false, nil
+}
+
+func (r httpRequest) completeDownload(destination io.WriterAt, state *downloadState, span trace.Span) error {
+	if file, ok := destination.(truncater); ok {
+		if truncateError := file.Truncate(state.offset); truncateError != nil {
+			return truncateError
+		}
+	}
+	if state.checksum != "" && !checksumMatches(state.checksum, state.hash.Sum(nil)) {
+		span.Annotate([]trace.Attribute{trace.BoolAttribute(ErrorTraceAttribute, true)}, "download checksum mismatch")
+		return errors.New("downloaded content does not match checksum " + state.checksum)
+	}
+	span.Annotate([]trace.Attribute{
+		trace.Int64Attribute(downloadedBytesAttribute, state.offset),
+		trace.Int64Attribute(totalBytesAttribute, state.total),
+	}, "download completed")
+	return nil
+}
+
+// rangeValidator returns the value to send in If-Range. Weak ETags can not be used
+// for range requests, Last-Modified is used as a fallback.
+func rangeValidator(header http.Header) string {
+	if etag := header.Get("ETag"); etag != "" && !strings.HasPrefix(etag, "W/") {
+		return etag
+	}
+	return header.Get("Last-Modified")
+}
+
+func parseContentRange(contentRange string) (int64, int64, error) {
+	invalidError := fmt.Errorf("invalid Content-Range %q", contentRange)
+	if !strings.HasPrefix(contentRange, "bytes ") {
+		return 0, 0, invalidError
+	}
+	rangeAndTotal := strings.SplitN(strings.TrimPrefix(contentRange, "bytes "), "/", 2)
+	if len(rangeAndTotal) != 2 {
+		return 0, 0, invalidError
+	}
+	bounds := strings.SplitN(rangeAndTotal[0], "-", 2)
+	start, startError := strconv.ParseInt(bounds[0], 10, 64)
+	if startError != nil {
+		return 0, 0, invalidError
+	}
+	total := int64(-1)
+	if rangeAndTotal[1] != "*" {
+		parsedTotal, totalError := strconv.ParseInt(rangeAndTotal[1], 10, 64)
+		if totalError != nil {
+			return 0, 0, invalidError
+		}
+		total = parsedTotal
+	}
+	return start, total, nil
+}
+
+func checksumMatches(expected string, digest []byte) bool {
+	expected = strings.TrimSpace(expected)
+	if index := strings.Index(expected, "="); index > 0 && index < len(expected)-2 {
+		expected = expected[index+1:]
+	}
+	return strings.EqualFold(expected, hex.EncodeToString(digest)) ||
+		expected == base64.StdEncoding.EncodeToString(digest)
+}
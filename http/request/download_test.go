@@ -0,0 +1,265 @@
+package request
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	utilError "github.com/inclusi-blog/gola-utils/golaerror"
+	"github.com/inclusi-blog/gola-utils/http/client/mocks"
+	"github.com/inclusi-blog/gola-utils/tracing/tracetest"
+	"github.com/stretchr/testify/suite"
+	"go.opencensus.io/trace"
+)
+
+type memoryWriterAt struct {
+	data []byte
+}
+
+func (w *memoryWriterAt) WriteAt(p []byte, offset int64) (int, error) {
+	if end := int(offset) + len(p); end > len(w.data) {
+		w.data = append(w.data, make([]byte, end-len(w.data))...)
+	}
+	copy(w.data[offset:], p)
+	return len(p), nil
+}
+
+type interruptedReader struct {
+	data []byte
+}
+
+func (r *interruptedReader) Read(p []byte) (int, error) {
+	if len(r.data) == 0 {
+		return 0, errors.New("connection reset by peer")
+	}
+	n := copy(p, r.data)
+	r.data = r.data[n:]
+	return n, nil
+}
+
+func (r *interruptedReader) Close() error {
+	return nil
+}
+
+type DownloadTestSuite struct {
+	suite.Suite
+	mockCtrl           *gomock.Controller
+	mockHttpClient     *mocks.MockHttpClient
+	httpRequestBuilder HttpRequestBuilder
+	content            []byte
+	url                string
+	options            DownloadOptions
+}
+
+func TestDownloadTestSuite(t *testing.T) {
+	suite.Run(t, new(DownloadTestSuite))
+}
+
+func (suite *DownloadTestSuite) SetupTest() {
+	suite.mockCtrl = gomock.NewController(suite.T())
+	suite.mockHttpClient = mocks.NewMockHttpClient(suite.mockCtrl)
+	suite.httpRequestBuilder = NewHttpRequestBuilder(suite.mockHttpClient)
+	suite.content, _ = ioutil.ReadFile("testdata/AC_ENTRY_POSTING_FEED_20201010150405")
+	suite.url = "http://dummyurl.com/feeds/AC_ENTRY_POSTING_FEED_20201010150405"
+	suite.options = DownloadOptions{RetryInterval: time.Millisecond}
+}
+
+func (suite *DownloadTestSuite) TearDownTest() {
+	suite.mockCtrl.Finish()
+}
+
+func (suite *DownloadTestSuite) fullResponse(body io.ReadCloser) *http.Response {
+	header := http.Header{}
+	header.Set("ETag", `"feed-v1"`)
+	header.Set("X-Checksum-Sha256", suite.checksum())
+	return &http.Response{
+		StatusCode:    http.StatusOK,
+		Header:        header,
+		ContentLength: int64(len(suite.content)),
+		Body:          body,
+	}
+}
+
+func (suite *DownloadTestSuite) partialResponse(offset int) *http.Response {
+	header := http.Header{}
+	header.Set("ETag", `"feed-v1"`)
+	header.Set("Content-Range", "bytes "+strconv.Itoa(offset)+"-"+strconv.Itoa(len(suite.content)-1)+"/"+strconv.Itoa(len(suite.content)))
+	return &http.Response{
+		StatusCode:    http.StatusPartialContent,
+		Header:        header,
+		ContentLength: int64(len(suite.content) - offset),
+		Body:          ioutil.NopCloser(bytes.NewReader(suite.content[offset:])),
+	}
+}
+
+func (suite *DownloadTestSuite) checksum() string {
+	sum := sha256.Sum256(suite.content)
+	return hex.EncodeToString(sum[:])
+}
+
+func (suite *DownloadTestSuite) TestShouldDownloadInSingleRequest() {
+	suite.mockHttpClient.EXPECT().Do(gomock.Any()).DoAndReturn(func(actualRequest *http.Request) (*http.Response, error) {
+		suite.Equal("GET", actualRequest.Method)
+		suite.Equal("", actualRequest.Header.Get("Range"))
+		return suite.fullResponse(ioutil.NopCloser(bytes.NewReader(suite.content))), nil
+	})
+
+	destination := &memoryWriterAt{}
+	var statusCode int
+	err := suite.httpRequestBuilder.
+		NewRequest().
+		WithContext(context.Background()).
+		ResponseStatusCodeAs(&statusCode).
+		Download(suite.url, destination, suite.options)
+
+	suite.Nil(err)
+	suite.Equal(http.StatusOK, statusCode)
+	suite.Equal(suite.content, destination.data)
+}
+
+func (suite *DownloadTestSuite) TestShouldTraceDownloadInEndedChildSpanOfCaller() {
+	exporter := tracetest.Start()
+	defer exporter.Stop()
+	ctx, parent := trace.StartSpan(context.Background(), "handler", trace.WithSampler(trace.AlwaysSample()))
+	suite.mockHttpClient.EXPECT().Do(gomock.Any()).DoAndReturn(func(actualRequest *http.Request) (*http.Response, error) {
+		return suite.fullResponse(ioutil.NopCloser(bytes.NewReader(suite.content))), nil
+	})
+
+	err := suite.httpRequestBuilder.
+		NewRequest().
+		WithContext(ctx).
+		Download(suite.url, &memoryWriterAt{}, suite.options)
+
+	suite.Nil(err)
+	span, ended := exporter.Span("/feeds/AC_ENTRY_POSTING_FEED_20201010150405 | download")
+	suite.True(ended)
+	suite.Equal(parent.SpanContext().SpanID, span.ParentSpanID)
+	suite.Equal([]string{"download started", "download completed"}, exporter.AnnotationMessages(span.Name))
+	suite.Equal([]string{span.Name}, exporter.Names())
+	parent.End()
+}
+
+func (suite *DownloadTestSuite) TestShouldResumeWithRangeAndIfRangeAfterConnectionDrop() {
+	interruptedAt := 100
+	gomock.InOrder(
+		suite.mockHttpClient.EXPECT().Do(gomock.Any()).Return(
+			suite.fullResponse(&interruptedReader{data: suite.content[:interruptedAt]}), nil),
+		suite.mockHttpClient.EXPECT().Do(gomock.Any()).DoAndReturn(func(actualRequest *http.Request) (*http.Response, error) {
+			suite.Equal("bytes=100-", actualRequest.Header.Get("Range"))
+			suite.Equal(`"feed-v1"`, actualRequest.Header.Get("If-Range"))
+			return suite.partialResponse(interruptedAt), nil
+		}),
+	)
+
+	destination := &memoryWriterAt{}
+	var progress []int64
+	options := suite.options
+	options.ChecksumHeader = "X-Checksum-Sha256"
+	options.Progress = func(downloadedBytes, totalBytes int64) {
+		suite.Equal(int64(len(suite.content)), totalBytes)
+		progress = append(progress, downloadedBytes)
+	}
+
+	err := suite.httpRequestBuilder.
+		NewRequest().
+		WithContext(context.Background()).
+		Download(suite.url, destination, options)
+
+	suite.Nil(err)
+	suite.Equal(suite.content, destination.data)
+	suite.Equal(int64(interruptedAt), progress[0])
+	suite.Equal(int64(len(suite.content)), progress[len(progress)-1])
+}
+
+func (suite *DownloadTestSuite) TestShouldRestartFromBeginningWhenServerIgnoresRange() {
+	gomock.InOrder(
+		suite.mockHttpClient.EXPECT().Do(gomock.Any()).Return(
+			suite.fullResponse(&interruptedReader{data: []byte("stale partial content")}), nil),
+		suite.mockHttpClient.EXPECT().Do(gomock.Any()).DoAndReturn(func(actualRequest *http.Request) (*http.Response, error) {
+			suite.NotEqual("", actualRequest.Header.Get("Range"))
+			return suite.fullResponse(ioutil.NopCloser(bytes.NewReader(suite.content))), nil
+		}),
+	)
+
+	destination := &memoryWriterAt{}
+	options := suite.options
+	options.ChecksumHeader = "X-Checksum-Sha256"
+
+	err := suite.httpRequestBuilder.
+		NewRequest().
+		Download(suite.url, destination, options)
+
+	suite.Nil(err)
+	suite.Equal(suite.content, destination.data)
+}
+
+func (suite *DownloadTestSuite) TestShouldReturnErrorWhenChecksumDoesNotMatch() {
+	suite.mockHttpClient.EXPECT().Do(gomock.Any()).DoAndReturn(func(actualRequest *http.Request) (*http.Response, error) {
+		response := suite.fullResponse(ioutil.NopCloser(bytes.NewReader(suite.content)))
+		response.Header.Set("X-Checksum-Sha256", "sha-256=47DEQpj8HBSa+/TImW+5JCeuQeRkm5NMpJWZG3hSuFU=")
+		return response, nil
+	})
+
+	options := suite.options
+	options.ChecksumHeader = "X-Checksum-Sha256"
+
+	err := suite.httpRequestBuilder.
+		NewRequest().
+		Download(suite.url, &memoryWriterAt{}, options)
+
+	suite.EqualError(err, "downloaded content does not match checksum sha-256=47DEQpj8HBSa+/TImW+5JCeuQeRkm5NMpJWZG3hSuFU=")
+}
+
+func (suite *DownloadTestSuite) TestShouldNotRetryClientErrors() {
+	suite.mockHttpClient.EXPECT().Do(gomock.Any()).Return(&http.Response{
+		StatusCode: http.StatusNotFound,
+		Body:       ioutil.NopCloser(bytes.NewBufferString("")),
+	}, nil).Times(1)
+
+	err := suite.httpRequestBuilder.
+		NewRequest().
+		Download(suite.url, &memoryWriterAt{}, suite.options)
+
+	suite.Equal(utilError.HttpError{StatusCode: http.StatusNotFound}, err)
+}
+
+func (suite *DownloadTestSuite) TestShouldGiveUpAfterMaxRetries() {
+	suite.mockHttpClient.EXPECT().Do(gomock.Any()).Return(nil, errors.New("connection refused")).Times(3)
+
+	options := suite.options
+	options.MaxRetries = 2
+
+	err := suite.httpRequestBuilder.
+		NewRequest().
+		Download(suite.url, &memoryWriterAt{}, options)
+
+	suite.EqualError(err, "connection refused")
+}
+
+func (suite *DownloadTestSuite) TestShouldDownloadToFileAndTruncateStaleContent() {
+	directory, _ := ioutil.TempDir("", "download")
+	defer os.RemoveAll(directory)
+	filePath := filepath.Join(directory, "feed")
+	_ = ioutil.WriteFile(filePath, bytes.Repeat([]byte("x"), 1024), 0644)
+	suite.mockHttpClient.EXPECT().Do(gomock.Any()).Return(
+		suite.fullResponse(ioutil.NopCloser(bytes.NewReader(suite.content))), nil)
+
+	err := suite.httpRequestBuilder.
+		NewRequest().
+		DownloadToFile(suite.url, filePath, suite.options)
+
+	suite.Nil(err)
+	actual, _ := ioutil.ReadFile(filePath)
+	suite.Equal(suite.content, actual)
+}
@@ -11,14 +11,13 @@ import (
 	"fmt"
 	"github.com/inclusi-blog/gola-utils/http/util"
 	"github.com/jtacoma/uritemplates"
+	"io"
 	"io/ioutil"
 	"log"
 	"mime"
 	"mime/multipart"
 	"net/http"
-	"net/textproto"
 	"net/url"
-	"os"
 	"reflect"
 	"strconv"
 	"strings"
@@ -26,16 +25,15 @@ import (
 
 	"gopkg.in/go-playground/validator.v9"
 
-	"github.com/inclusi-blog/gola-utils/http/model"
 	"github.com/inclusi-blog/gola-utils/logging"
+	"github.com/inclusi-blog/gola-utils/redaction"
+	"github.com/inclusi-blog/gola-utils/tracing/propagation"
 
 	"github.com/gin-gonic/gin"
 	"github.com/inclusi-blog/gola-utils/constants"
 	utilError "github.com/inclusi-blog/gola-utils/golaerror"
 	"github.com/inclusi-blog/gola-utils/http/client"
 	"github.com/inclusi-blog/gola-utils/trace"
-	"go.opencensus.io/plugin/ochttp"
-	openTrace "go.opencensus.io/trace"
 )
 
 const (
@@ -50,17 +48,22 @@ type HttpRequest interface {
 	WithXMLBody(interface{}) HttpRequest
 	WithXMLBodyTextHeader(interface{}) HttpRequest
 	WithFormURLEncoded(map[string]interface{}) HttpRequest
+	WithMultipartBody(*MultipartBody) HttpRequest
 	WithContext(context.Context) HttpRequest
+	WithTimeout(time.Duration) HttpRequest
 	WithOauth() HttpRequest
 	WithRequestBodyBytes([]byte) HttpRequest
 	WithTracer(trace.Trace) HttpRequest
 	WithCustomValidator(*validator.Validate) HttpRequest
 	RequestTraceHook(hookFunc TraceHookFunc) HttpRequest
 	ResponseTraceHook(hookFunc TraceHookFunc) HttpRequest
+	WithRedactor(redactor redaction.Redactor) HttpRequest
 	ResponseAs(interface{}) HttpRequest
 	ResponseStatusCodeAs(*int) HttpRequest
 	ResponseHeadersAs(*map[string][]string) HttpRequest
 	ResponseCookiesAs(*[]*http.Cookie) HttpRequest
+	OnStatus(StatusRange, StatusHandlerFunc) HttpRequest
+	ResponseAsForStatus(int, interface{}) HttpRequest
 	AddHeader(string, string) HttpRequest
 	AddHeaders(map[string]string) HttpRequest
 	AddQueryParameters(map[string]string) HttpRequest
@@ -70,6 +73,8 @@ type HttpRequest interface {
 	Put(string) error
 	Get(string) error
 	Delete(string) error
+	Download(url string, destination io.WriterAt, options DownloadOptions) error
+	DownloadToFile(url string, filePath string, options DownloadOptions) error
 }
 
 type httpRequest struct {
@@ -93,6 +98,9 @@ type httpRequest struct {
 	trace              trace.Trace
 	requestTraceHook   TraceHookFunc
 	responseTraceHook  TraceHookFunc
+	statusHandlers     []statusHandler
+	timeout            time.Duration
+	deadlineCtx        context.Context
 }
 
 var quoteEscaper = strings.NewReplacer("\\", "\\\\", `"`, "\\\"")
@@ -116,6 +124,12 @@ func (r httpRequest) ResponseTraceHook(hookFunc TraceHookFunc) HttpRequest {
 	return r
 }
 
+func (r httpRequest) WithRedactor(redactor redaction.Redactor) HttpRequest {
+	r.requestTraceHook = redactor.TraceHook
+	r.responseTraceHook = redactor.TraceHook
+	return r
+}
+
 func (r httpRequest) ResponseAs(responseModel interface{}) HttpRequest {
 	r.responseModel = responseModel
 	return r
@@ -193,91 +207,27 @@ func (r httpRequest) WithXMLBodyTextHeader(requestModel interface{}) HttpRequest
 	return r
 }
 
+// WithFormURLEncoded writes the form data as multipart/form-data with parts ordered
+// by key. Use WithMultipartBody for repeated names, custom part headers or streams.
 func (r httpRequest) WithFormURLEncoded(formData map[string]interface{}) HttpRequest {
 	r.requestModel = formData
-	bodyBuf := &bytes.Buffer{}
-	bodyWriter := multipart.NewWriter(bodyBuf)
-	for key, value := range formData {
-		switch value.(type) {
-		case multipart.FileHeader:
-			fileHeader := value.(multipart.FileHeader)
-			file, err := fileHeader.Open()
-			if err != nil {
-				r.requestBuildError = err
-			}
+	return r.withMultipartBody(multipartBodyFromFormData(formData))
+}
 
-			h := make(textproto.MIMEHeader)
-			h.Set("Content-Disposition", fmt.Sprintf(`form-data; name="%s"; filename="%s"`, escapeQuotes(key), escapeQuotes(fileHeader.Filename)))
-			h.Set("Content-Type", fileHeader.Header.Get(constants.HeaderContentType))
-			formFileWriter, err := bodyWriter.CreatePart(h)
-			if err != nil {
-				r.requestBuildError = err
-			}
-			if err != nil {
-				r.requestBuildError = err
-			}
-			content, err := ioutil.ReadAll(file)
-			if err != nil {
-				r.requestBuildError = err
-			}
-			if _, fileWriteError := formFileWriter.Write(content); fileWriteError != nil {
-				r.requestBuildError = fileWriteError
-			}
-		case string:
-			if fieldWriteError := bodyWriter.WriteField(key, value.(string)); fieldWriteError != nil {
-				r.requestBuildError = fieldWriteError
-			}
-		case []byte:
-			data := value.([]byte)
-			h := make(textproto.MIMEHeader)
-			h.Set("Content-Disposition",
-				fmt.Sprintf(`form-data; name="%s"`, escapeQuotes(key)))
-			h.Set("Content-Type", "application/json")
-			byteWriter, err := bodyWriter.CreatePart(h)
-			if err != nil {
-				r.requestBuildError = err
-			}
-			if _, err = byteWriter.Write(data); err != nil {
-				r.requestBuildError = err
-			}
-		case model.FileUploadContent:
-			fileContent := value.(model.FileUploadContent)
-			file, err := os.Open(fileContent.FilePath)
-			if err != nil {
-				logging.GetLogger(r.ctx).Error(err)
-			}
-			formFileWriter, err := bodyWriter.CreateFormFile(key, fileContent.FileName)
-			if err != nil {
-				r.requestBuildError = err
-			}
-			content, err := ioutil.ReadAll(file)
-			if err != nil {
-				r.requestBuildError = err
-			}
-			if _, fileWriteError := formFileWriter.Write(content); fileWriteError != nil {
-				r.requestBuildError = fileWriteError
-			}
-			err = file.Close()
-			if err != nil {
-				r.requestBuildError = err
-			}
-		default:
-			r.requestBuildError = utilError.Error{
-				ErrorCode:      "ERR_INVALID_REQUEST_TYPE",
-				ErrorMessage:   "only multipart files and strings are supported",
-				AdditionalData: nil,
-			}
-		}
-	}
+func (r httpRequest) WithMultipartBody(body *MultipartBody) HttpRequest {
+	r.requestModel = body
+	return r.withMultipartBody(body)
+}
 
-	if multipartWriterCloseError := bodyWriter.Close(); multipartWriterCloseError != nil {
-		r.requestBuildError = multipartWriterCloseError
+func (r httpRequest) withMultipartBody(body *MultipartBody) httpRequest {
+	requestBytes, contentType, err := body.Build()
+	if err != nil {
+		r.requestBuildError = err
+		return r
 	}
 
-	r.headers["Content-Type"] = bodyWriter.FormDataContentType()
-
-	r.requestBytes = bodyBuf.Bytes()
-
+	r.headers["Content-Type"] = contentType
+	r.requestBytes = requestBytes
 	return r
 }
 
@@ -286,6 +236,14 @@ func (r httpRequest) WithContext(ctx context.Context) HttpRequest {
 	return r
 }
 
+// WithTimeout bounds the request, including reading the response body. The remaining
+// budget, or the one inherited from the context, is sent downstream in the
+// X-Request-Timeout header.
+func (r httpRequest) WithTimeout(timeout time.Duration) HttpRequest {
+	r.timeout = timeout
+	return r
+}
+
 func (r httpRequest) WithOauth() HttpRequest {
 	r.forwardAuthHeaders = true
 	return r
@@ -339,65 +297,27 @@ func (r httpRequest) Delete(url string) error {
 }
 
 func (r httpRequest) makeRequest(method string) error {
-	if r.requestBuildError != nil {
-		return r.requestBuildError
-	}
+	r, cancel := r.withDeadline()
+	defer cancel()
 
-	cleanUrl, urlFormatErr := r.removeDoubleSlashesInUrl(r.pathTemplate)
-	if urlFormatErr != nil {
-		return urlFormatErr
+	httpRequest, buildError := r.buildHttpRequest(method)
+	if buildError != nil {
+		return buildError
 	}
-	r.pathTemplate = cleanUrl
 
-	urlWithPathParams, urlConstructionErr := r.getUrlWithPathParams()
-	if urlConstructionErr != nil {
-		return urlConstructionErr
-	}
-
-	httpRequest, requestError := http.NewRequest(method, urlWithPathParams, bytes.NewBuffer(r.requestBytes))
-
-	if requestError != nil {
-		return requestError
-	}
-
-	if r.ctx != nil {
-		httpRequest = httpRequest.WithContext(r.ctx)
-		addSessionTracingId(r.ctx, httpRequest)
-	}
-	if r.forwardAuthHeaders {
-		if r.ctx == nil {
-			return errors.New(fmt.Sprintf("Context not set for forwarding oauth headers"))
-		}
-		addAuthHeaders(r.ctx, httpRequest)
-	}
-
-	for k, v := range r.headers {
-		httpRequest.Header.Add(k, v)
-	}
-
-	query := httpRequest.URL.Query()
-	for paramKey, paramValue := range r.queryParameters {
-		query.Add(paramKey, paramValue)
-	}
-	httpRequest.URL.RawQuery = query.Encode()
-
-	for _, cookie := range r.cookies {
-		httpRequest.AddCookie(cookie)
-	}
-
-	var dataSpan *openTrace.Span = nil
+	var dataSpan trace.Span = nil
 	currentContext := r.ctx
 	if currentContext != nil {
-		span, h := r.trace.Continue(currentContext, httpRequest)
+		span, h := r.trace.Continue(r.tracingContext(), httpRequest)
 		httpRequest = h
-		span.Annotate([]openTrace.Attribute{openTrace.StringAttribute("isEndingSpan", "false")}, "log")
+		span.Annotate([]trace.Attribute{trace.StringAttribute("isEndingSpan", "false")}, "log")
 		dataSpan = r.logHttpRequest(span, httpRequest)
 		// defer span.End() Fixes span adjustment
 	}
 	start := time.Now()
 
 	log.Printf("Making the request %s", httpRequest.URL.String())
-	response, httpError := r.httpClient.Do(httpRequest)
+	response, httpError := r.doRequest(httpRequest)
 	log.Printf("After the request %s", httpRequest.URL.String())
 
 	_ = time.Since(start)
@@ -417,6 +337,10 @@ func (r httpRequest) makeRequest(method string) error {
 		return httpError
 	}
 
+	if handler, isHandled := r.statusHandlerFor(response.StatusCode); isHandled {
+		return r.handleStatus(handler, response, httpRequest, dataSpan)
+	}
+
 	addResponseTags(response, dataSpan)
 	if response.StatusCode < 200 || response.StatusCode >= 400 {
 		errorResponseBytes, readError := ioutil.ReadAll(response.Body)
@@ -457,6 +381,69 @@ func (r httpRequest) makeRequest(method string) error {
 	return nil
 }
 
+func (r httpRequest) buildHttpRequest(method string) (*http.Request, error) {
+	if r.requestBuildError != nil {
+		return nil, r.requestBuildError
+	}
+
+	cleanUrl, urlFormatErr := r.removeDoubleSlashesInUrl(r.pathTemplate)
+	if urlFormatErr != nil {
+		return nil, urlFormatErr
+	}
+	r.pathTemplate = cleanUrl
+
+	urlWithPathParams, urlConstructionErr := r.getUrlWithPathParams()
+	if urlConstructionErr != nil {
+		return nil, urlConstructionErr
+	}
+
+	httpRequest, requestError := http.NewRequest(method, urlWithPathParams, bytes.NewBuffer(r.requestBytes))
+
+	if requestError != nil {
+		return nil, requestError
+	}
+
+	if r.ctx != nil {
+		httpRequest = httpRequest.WithContext(r.ctx)
+		addBaggage(r.ctx, httpRequest)
+	}
+	if r.deadlineCtx != nil {
+		httpRequest = httpRequest.WithContext(r.deadlineCtx)
+	}
+	if deadlineError := r.addDeadline(httpRequest); deadlineError != nil {
+		return nil, deadlineError
+	}
+	if r.forwardAuthHeaders {
+		if r.ctx == nil {
+			return nil, errors.New(fmt.Sprintf("Context not set for forwarding oauth headers"))
+		}
+		addAuthHeaders(r.ctx, httpRequest)
+	}
+
+	for k, v := range r.headers {
+		httpRequest.Header.Add(k, v)
+	}
+
+	query := httpRequest.URL.Query()
+	for paramKey, paramValue := range r.queryParameters {
+		query.Add(paramKey, paramValue)
+	}
+	httpRequest.URL.RawQuery = query.Encode()
+
+	for _, cookie := range r.cookies {
+		httpRequest.AddCookie(cookie)
+	}
+	return httpRequest, nil
+}
+
+func (r httpRequest) doRequest(httpRequest *http.Request) (*http.Response, error) {
+	ctx := client.WithRouteTemplate(httpRequest.Context(), r.pathTemplate)
+	if r.ctx != nil {
+		ctx = client.WithLogger(ctx, logging.GetLogger(r.ctx))
+	}
+	return r.httpClient.Do(httpRequest.WithContext(ctx))
+}
+
 func (r httpRequest) getUrlWithPathParams() (string, error) {
 	urlTemplate, parseErr := uritemplates.Parse(r.pathTemplate)
 	if parseErr != nil {
@@ -499,19 +486,16 @@ func getStatusCode(response *http.Response) string {
 	return statusCode
 }
 
-func addResponseTags(res *http.Response, span *openTrace.Span) {
-	span.AddAttributes(openTrace.Int64Attribute(ochttp.StatusCodeAttribute, int64(res.StatusCode)))
-	if res.StatusCode >= 400 {
-		span.AddAttributes(openTrace.BoolAttribute(ErrorTraceAttribute, true))
-	}
+func addResponseTags(res *http.Response, span trace.Span) {
+	addStatusTags(res.StatusCode, res.StatusCode >= 400, span)
 }
 
-func (r httpRequest) logHttpRequest(span *openTrace.Span, httpRequest *http.Request) *openTrace.Span {
+func (r httpRequest) logHttpRequest(span trace.Span, httpRequest *http.Request) trace.Span {
 	logKeyName := "request"
 	logDescription := httpRequest.Host + httpRequest.URL.RequestURI()
 	var logRequest string
 
-	_, dataSpan := openTrace.StartSpanWithRemoteParent(r.ctx, r.extractPath()+" | request/response", span.SpanContext())
+	_, dataSpan := trace.StartSpanWithRemoteParent(r.ctx, r.extractPath()+" | request/response", span.SpanContext())
 
 	requestBodyBytes, readErr := ioutil.ReadAll(httpRequest.Body)
 	defer func() {
@@ -525,15 +509,15 @@ func (r httpRequest) logHttpRequest(span *openTrace.Span, httpRequest *http.Requ
 			if requestBody, err := r.requestTraceHook(requestBodyBytes); err == nil {
 				logRequest = requestBody
 			} else {
-				logRequest = string(requestBodyBytes)
+				logRequest = redaction.NotLogged(err)
 			}
 		} else {
 			logRequest = string(requestBodyBytes)
 		}
 	}
 
-	dataSpan.Annotate([]openTrace.Attribute{
-		openTrace.StringAttribute(logKeyName, logRequest),
+	dataSpan.Annotate([]trace.Attribute{
+		trace.StringAttribute(logKeyName, logRequest),
 	}, logDescription)
 
 	return dataSpan
@@ -552,7 +536,7 @@ func isLoggingDisabled(requestedPath string) bool {
 	return strings.Contains(requestedPath, "crypto")
 }
 
-func (r httpRequest) logHttpResponse(respBody string, httpRequest *http.Request, dataSpan *openTrace.Span) {
+func (r httpRequest) logHttpResponse(respBody string, httpRequest *http.Request, dataSpan trace.Span) {
 	//TODO: Remove this if condition (don't remove the lines inside if) once deprecated methods from cryptoUtil are removed
 	if dataSpan != nil {
 		logKeyName := "response"
@@ -562,17 +546,19 @@ func (r httpRequest) logHttpResponse(respBody string, httpRequest *http.Request,
 		if r.responseTraceHook != nil {
 			if responseBody, err := r.responseTraceHook(responseBodyBytes); err == nil {
 				responseBodyBytes = []byte(responseBody)
+			} else {
+				responseBodyBytes = []byte(redaction.NotLogged(err))
 			}
 		}
 
-		dataSpan.Annotate([]openTrace.Attribute{
-			openTrace.StringAttribute(logKeyName, trace.EscapeSpecialChar(responseBodyBytes)),
+		dataSpan.Annotate([]trace.Attribute{
+			trace.StringAttribute(logKeyName, trace.EscapeSpecialChar(responseBodyBytes)),
 		}, logDescription)
 		dataSpan.End()
 	}
 }
 
-func (r httpRequest) processResponseModel(response *http.Response, httpRequest *http.Request, dataSpan *openTrace.Span) error {
+func (r httpRequest) processResponseModel(response *http.Response, httpRequest *http.Request, dataSpan trace.Span) error {
 	if twoDimByteArrPtr, is2dByteArray := r.responseModel.(*[][]byte); is2dByteArray {
 		data, err := r.processMultiFormResponse(response, httpRequest, dataSpan)
 		if err != nil {
@@ -593,32 +579,37 @@ func (r httpRequest) processResponseModel(response *http.Response, httpRequest *
 			r.logHttpResponse(string(responseBytes), httpRequest, dataSpan)
 		}
 
-		if strPointer, isString := r.responseModel.(*string); isString {
-			*strPointer = string(responseBytes)
-		} else if byteArrayPointer, isByteArray := r.responseModel.(*[]byte); isByteArray {
-			*byteArrayPointer = responseBytes
+		return r.decodeResponseBytes(response, responseBytes, r.responseModel)
+	}
+	return nil
+}
+
+func (r httpRequest) decodeResponseBytes(response *http.Response, responseBytes []byte, responseModel interface{}) error {
+	if strPointer, isString := responseModel.(*string); isString {
+		*strPointer = string(responseBytes)
+	} else if byteArrayPointer, isByteArray := responseModel.(*[]byte); isByteArray {
+		*byteArrayPointer = responseBytes
+	} else {
+		var unmarshalError error
+		contentType := response.Header.Get(constants.HeaderContentType)
+		if strings.Contains(contentType, "application/xml") || strings.Contains(contentType, "text/xml") {
+			unmarshalError = xml.Unmarshal(responseBytes, responseModel)
 		} else {
-			var unmarshalError error
-			contentType := response.Header.Get(constants.HeaderContentType)
-			if strings.Contains(contentType, "application/xml") || strings.Contains(contentType, "text/xml") {
-				unmarshalError = xml.Unmarshal(responseBytes, r.responseModel)
-			} else {
-				unmarshalError = json.Unmarshal(responseBytes, r.responseModel)
-			}
-			if unmarshalError != nil {
-				return unmarshalError
-			}
+			unmarshalError = json.Unmarshal(responseBytes, responseModel)
+		}
+		if unmarshalError != nil {
+			return unmarshalError
+		}
 
-			validationError := r.validateResponse(r.responseModel)
-			if validationError != nil {
-				return validationError
-			}
+		validationError := r.validateResponse(responseModel)
+		if validationError != nil {
+			return validationError
 		}
 	}
 	return nil
 }
 
-func (r httpRequest) processMultiFormResponse(response *http.Response, httpRequest *http.Request, dataSpan *openTrace.Span) ([][]byte, error) {
+func (r httpRequest) processMultiFormResponse(response *http.Response, httpRequest *http.Request, dataSpan trace.Span) ([][]byte, error) {
 	_, params, err := mime.ParseMediaType(response.Header.Get("Content-Type"))
 	if err != nil {
 		r.logHttpResponse("Error occurred in parsing response media type: "+err.Error(), httpRequest, dataSpan)
@@ -665,18 +656,9 @@ func addAuthHeaders(ctx context.Context, httpRequest *http.Request) {
 	}
 }
 
-func addSessionTracingId(ctx context.Context, httpRequest *http.Request) {
-	if ginContext, ok := ctx.(*gin.Context); ok {
-		sessionTracingId := ginContext.Request.Header.Get(constants.TRACING_SESSION_HEADER_KEY)
-		if sessionTracingId != "" {
-			httpRequest.Header.Add(constants.TRACING_SESSION_HEADER_KEY, sessionTracingId)
-		}
-		return
-	}
-	sessionTracingId, isSessionTracingId := ctx.Value(constants.TRACING_SESSION_HEADER_KEY).(string)
-	if isSessionTracingId {
-		httpRequest.Header.Add(constants.TRACING_SESSION_HEADER_KEY, sessionTracingId)
-	}
+// addBaggage propagates the baggage of ctx, including the session tracing fields.
+func addBaggage(ctx context.Context, httpRequest *http.Request) {
+	propagation.InjectBaggage(propagation.BaggageFromContext(ctx), httpRequest)
 }
 
 func (r httpRequest) validateResponse(obj interface{}) error {
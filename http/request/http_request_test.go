@@ -17,12 +17,16 @@ import (
 
 	"github.com/inclusi-blog/gola-utils/constants"
 	utilError "github.com/inclusi-blog/gola-utils/golaerror"
+	"github.com/inclusi-blog/gola-utils/http/client"
 	"github.com/inclusi-blog/gola-utils/http/model"
+	"github.com/inclusi-blog/gola-utils/redaction"
+	"github.com/inclusi-blog/gola-utils/tracing/tracetest"
 	"go.opencensus.io/plugin/ochttp"
 	"go.opencensus.io/trace"
 
 	"github.com/gin-gonic/gin"
 	"github.com/inclusi-blog/gola-utils/http/client/mocks"
+	golaTrace "github.com/inclusi-blog/gola-utils/trace"
 	traceMocks "github.com/inclusi-blog/gola-utils/trace/mocks"
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/suite"
@@ -57,17 +61,6 @@ type dummyResponseWithCustomValidation struct {
 	ResponseFieldA string `json:"responseFieldA" validate:"isBool"`
 }
 
-type SpanExporter struct {
-	spans []*trace.SpanData
-}
-
-func (s *SpanExporter) ExportSpan(d *trace.SpanData) {
-	s.spans = append(s.spans, d)
-}
-func (s *SpanExporter) Reset() {
-	s.spans = []*trace.SpanData{}
-}
-
 func dummyValidationIsBool(fl validator.FieldLevel) bool {
 	return strings.ToLower(fl.Field().String()) == "true" ||
 		strings.ToLower(fl.Field().String()) == "false"
@@ -943,6 +936,30 @@ func (suite HttpRequestTestSuite) TestShouldMakePostRequestAndGetResponseHeaders
 	suite.Nil(err)
 }
 
+func (suite HttpRequestTestSuite) TestShouldPropagateBaggageFromGinContext() {
+	ginContext, _ := gin.CreateTestContext(httptest.NewRecorder())
+	ginContext.Request = httptest.NewRequest("", "/", strings.NewReader(""))
+	ginContext.Request.Header.Set("baggage", "tenant=gola")
+	ginContext.Request.Header.Set(constants.TRACING_APP_VERSION_HEADER_KEY, "version1")
+	ginContext.Request.Header.Set(constants.TRACING_DEVICE_INFO_HEADER_KEY, "android 11")
+	var actualRequest *http.Request
+	suite.mockHttpClient.EXPECT().Do(gomock.Any()).DoAndReturn(func(request *http.Request) (*http.Response, error) {
+		actualRequest = request
+		return &http.Response{StatusCode: http.StatusOK, Body: ioutil.NopCloser(bytes.NewBuffer([]byte("")))}, nil
+	})
+
+	err := suite.httpRequestBuilder.
+		NewRequest().
+		WithContext(ginContext).
+		Get(suite.url)
+
+	suite.Nil(err)
+	suite.Equal("app_version=version1,device_info=android%2011,tenant=gola", actualRequest.Header.Get("baggage"))
+	suite.Equal("version1", actualRequest.Header.Get(constants.TRACING_APP_VERSION_HEADER_KEY))
+	suite.Equal("android 11", actualRequest.Header.Get(constants.TRACING_DEVICE_INFO_HEADER_KEY))
+	suite.Empty(actualRequest.Header.Get(constants.TRACING_SESSION_HEADER_KEY))
+}
+
 func (suite HttpRequestTestSuite) TestShouldMakePostRequestAndUnmarshalToString() {
 	responseModel := dummyResponse{ResponseFieldA: "sample response value"}
 	expectedJSONResponse, _ := json.Marshal(responseModel)
@@ -1363,8 +1380,12 @@ func (suite HttpRequestTestSuite) TestShouldStartTracingWhenContextSet() {
 		suite.Run(test.method, func() {
 			type contextKey struct{}
 			ctx := context.WithValue(context.Background(), contextKey{}, &trace.Span{})
-			suite.trace.EXPECT().Continue(ctx, gomock.Any()).MaxTimes(2).DoAndReturn(func(ctx context.Context, h *http.Request) (*trace.Span, *http.Request) {
-				return &trace.Span{}, h
+			span := traceMocks.NewMockSpan(suite.mockCtrl)
+			span.EXPECT().Annotate(gomock.Any(), "log").AnyTimes()
+			span.EXPECT().SpanContext().Return(golaTrace.SpanContext{}).AnyTimes()
+			suite.trace.EXPECT().Continue(gomock.Any(), gomock.Any()).MaxTimes(2).DoAndReturn(func(tracingCtx context.Context, h *http.Request) (golaTrace.Span, *http.Request) {
+				suite.Equal(ctx.Value(contextKey{}), tracingCtx.Value(contextKey{}))
+				return span, h
 			})
 			responseModel := dummyResponse{ResponseFieldA: "sample response value"}
 			expectedJSONResponse, _ := json.Marshal(responseModel)
@@ -1393,8 +1414,12 @@ func (suite HttpRequestTestSuite) TestShouldNotUpdateRequestWithMessageIdWhenTra
 	ctx := context.WithValue(context.Background(), contextKey{}, &trace.Span{})
 	ctx = context.WithValue(ctx, constants.TRACE_KEY, constants.NO_TRACE_ID)
 
-	suite.trace.EXPECT().Continue(ctx, gomock.Any()).MaxTimes(2).DoAndReturn(func(ctx context.Context, h *http.Request) (*trace.Span, *http.Request) {
-		return &trace.Span{}, h
+	span := traceMocks.NewMockSpan(suite.mockCtrl)
+	span.EXPECT().Annotate(gomock.Any(), "log").AnyTimes()
+	span.EXPECT().SpanContext().Return(golaTrace.SpanContext{}).AnyTimes()
+	suite.trace.EXPECT().Continue(gomock.Any(), gomock.Any()).MaxTimes(2).DoAndReturn(func(tracingCtx context.Context, h *http.Request) (golaTrace.Span, *http.Request) {
+		suite.Equal(constants.NO_TRACE_ID, tracingCtx.Value(constants.TRACE_KEY))
+		return span, h
 	})
 	responseModel := dummyResponse{ResponseFieldA: "sample response value"}
 	expectedJSONResponse, _ := json.Marshal(responseModel)
@@ -1444,8 +1469,8 @@ func (suite HttpRequestTestSuite) TestShouldNotUpdateRequestWithMessageIdWhenTra
 }
 
 func (suite HttpRequestTestSuite) TestShouldAddHTTPStatusCodeToDataSpan() {
-	e := SpanExporter{}
-	trace.RegisterExporter(&e)
+	e := tracetest.Start()
+	defer e.Stop()
 	responseModel := dummyResponse{ResponseFieldA: "sample response value"}
 	expectedJSONResponse, _ := json.Marshal(responseModel)
 
@@ -1465,13 +1490,13 @@ func (suite HttpRequestTestSuite) TestShouldAddHTTPStatusCodeToDataSpan() {
 		Post(suite.url)
 
 	s.End()
-	suite.Equal(e.spans[0].Attributes[ochttp.StatusCodeAttribute], int64(200))
-	suite.Equal(e.spans[0].Attributes[ErrorTraceAttribute], nil)
+	suite.Equal(e.Spans()[0].Attributes[ochttp.StatusCodeAttribute], int64(200))
+	suite.Equal(e.Spans()[0].Attributes[ErrorTraceAttribute], nil)
 }
 
 func (suite HttpRequestTestSuite) TestShouldAddHTTPStatusCodeAndErrorToDataSpan() {
-	e := SpanExporter{}
-	trace.RegisterExporter(&e)
+	e := tracetest.Start()
+	defer e.Stop()
 	responseModel := dummyResponse{ResponseFieldA: "sample response value"}
 	expectedJSONResponse, _ := json.Marshal(responseModel)
 
@@ -1491,8 +1516,8 @@ func (suite HttpRequestTestSuite) TestShouldAddHTTPStatusCodeAndErrorToDataSpan(
 		Post(suite.url)
 
 	s.End()
-	suite.Equal(e.spans[0].Attributes[ochttp.StatusCodeAttribute], int64(404))
-	suite.Equal(e.spans[0].Attributes[ErrorTraceAttribute], true)
+	suite.Equal(e.Spans()[0].Attributes[ochttp.StatusCodeAttribute], int64(404))
+	suite.Equal(e.Spans()[0].Attributes[ErrorTraceAttribute], true)
 }
 
 func (suite HttpRequestTestSuite) TestShouldGiveSuccessfulResponseFor3XXStatusCode() {
@@ -1518,8 +1543,8 @@ func (suite HttpRequestTestSuite) TestShouldGiveSuccessfulResponseFor3XXStatusCo
 }
 
 func (suite HttpRequestTestSuite) TestShouldCloseSpanWhenResponseModelIsNotPassed() {
-	e := SpanExporter{}
-	trace.RegisterExporter(&e)
+	e := tracetest.Start()
+	defer e.Stop()
 	responseModel := dummyResponse{ResponseFieldA: "sample response value"}
 	expectedJSONResponse, _ := json.Marshal(responseModel)
 
@@ -1538,8 +1563,119 @@ func (suite HttpRequestTestSuite) TestShouldCloseSpanWhenResponseModelIsNotPasse
 
 	s.End()
 	suite.Nil(err)
-	suite.Equal(e.spans[0].Attributes[ochttp.StatusCodeAttribute], int64(200))
-	suite.Equal(len(e.spans[0].Annotations), 2)
+	suite.Equal(e.Spans()[0].Attributes[ochttp.StatusCodeAttribute], int64(200))
+	suite.Equal(len(e.Spans()[0].Annotations), 2)
+}
+
+func (suite HttpRequestTestSuite) TestShouldRedactRequestAndResponseAnnotationsWhenRedactorIsSet() {
+	e := tracetest.Start()
+	defer e.Stop()
+	redactor, _ := redaction.NewRedactor(redaction.Config{Rules: []redaction.Rule{
+		{Path: "$.fieldA", Strategy: redaction.FullMask},
+		{Path: "responseFieldA", Strategy: redaction.Drop},
+	}})
+
+	ctx, s := trace.StartSpan(context.Background(), "test-span", trace.WithSampler(trace.AlwaysSample()), trace.WithSpanKind(trace.SpanKindServer))
+	response := http.Response{StatusCode: http.StatusOK, Body: ioutil.NopCloser(bytes.NewBufferString(`{"responseFieldA":"secret","id":1}`))}
+	suite.mockHttpClient.EXPECT().Do(gomock.Any()).DoAndReturn(func(actualRequest *http.Request) (*http.Response, error) {
+		requestBytes, _ := ioutil.ReadAll(actualRequest.Body)
+		suite.Equal(string(suite.requestBodyAsBytes), string(requestBytes))
+		return &response, nil
+	})
+
+	actualResponse := ""
+	err := suite.
+		httpRequestBuilder.
+		NewRequest().
+		WithContext(ctx).
+		WithJSONBody(suite.requestBody).
+		WithRedactor(redactor).
+		ResponseAs(&actualResponse).
+		Post(suite.url)
+
+	s.End()
+	suite.Nil(err)
+	suite.Equal(`{"responseFieldA":"secret","id":1}`, actualResponse)
+	suite.Equal(`{"fieldA":"****","fieldB":0}`, e.Spans()[0].Annotations[0].Attributes["request"])
+	suite.Equal(`{"id":1}`, e.Spans()[0].Annotations[1].Attributes["response"])
+}
+
+func (suite HttpRequestTestSuite) TestShouldNotAnnotateFormEncodedBodyWhenRedactorIsSet() {
+	e := tracetest.Start()
+	defer e.Stop()
+	redactor, _ := redaction.NewRedactor(redaction.Config{Rules: []redaction.Rule{{Path: "password", Strategy: redaction.FullMask}}})
+
+	ctx, s := trace.StartSpan(context.Background(), "test-span", trace.WithSampler(trace.AlwaysSample()), trace.WithSpanKind(trace.SpanKindServer))
+	response := http.Response{StatusCode: http.StatusOK, Body: ioutil.NopCloser(bytes.NewBufferString(`token=abc&password=secret`))}
+	suite.mockHttpClient.EXPECT().Do(gomock.Any()).DoAndReturn(func(actualRequest *http.Request) (*http.Response, error) {
+		requestBytes, _ := ioutil.ReadAll(actualRequest.Body)
+		suite.Equal("username=ada&password=secret", string(requestBytes))
+		return &response, nil
+	})
+
+	actualResponse := ""
+	err := suite.
+		httpRequestBuilder.
+		NewRequest().
+		WithContext(ctx).
+		AddHeader("Content-Type", "application/x-www-form-urlencoded").
+		WithRequestBodyBytes([]byte("username=ada&password=secret")).
+		WithRedactor(redactor).
+		ResponseAs(&actualResponse).
+		Post(suite.url)
+
+	s.End()
+	suite.Nil(err)
+	suite.Equal("Payload not logged as it is not a JSON document", e.Spans()[0].Annotations[0].Attributes["request"])
+	suite.Equal("Payload not logged as it is not a JSON document", e.Spans()[0].Annotations[1].Attributes["response"])
+}
+
+func (suite HttpRequestTestSuite) TestShouldAnnotatePlaceholderWhenTraceHookFails() {
+	e := tracetest.Start()
+	defer e.Stop()
+	failingHook := func([]byte) (string, error) { return "", redaction.ErrNotJSON }
+
+	ctx, s := trace.StartSpan(context.Background(), "test-span", trace.WithSampler(trace.AlwaysSample()), trace.WithSpanKind(trace.SpanKindServer))
+	response := http.Response{StatusCode: http.StatusOK, Body: ioutil.NopCloser(bytes.NewBufferString(`password=secret`))}
+	suite.mockHttpClient.EXPECT().Do(gomock.Any()).Return(&response, nil)
+
+	actualResponse := ""
+	err := suite.
+		httpRequestBuilder.
+		NewRequest().
+		WithContext(ctx).
+		WithRequestBodyBytes([]byte("username=ada&password=secret")).
+		RequestTraceHook(failingHook).
+		ResponseTraceHook(failingHook).
+		ResponseAs(&actualResponse).
+		Post(suite.url)
+
+	s.End()
+	suite.Nil(err)
+	suite.Equal("body not logged: payload is not a valid JSON document", e.Spans()[0].Annotations[0].Attributes["request"])
+	suite.Equal("body not logged: payload is not a valid JSON document", e.Spans()[0].Annotations[1].Attributes["response"])
+}
+
+func (suite HttpRequestTestSuite) TestShouldExposeRouteTemplateToFaultInjectionRules() {
+	defer suite.mockCtrl.Finish()
+	faultInjectingClient, _ := client.NewFaultInjectingClient(suite.mockHttpClient, client.FaultInjectionConfig{
+		Enabled: true,
+		Rules: []client.FaultRule{{
+			Name:          "user-lookup",
+			RouteTemplate: "/users/{id}",
+			Probability:   1,
+			Fault:         client.StatusFault,
+			StatusCode:    http.StatusServiceUnavailable,
+		}},
+	})
+
+	err := NewHttpRequestBuilder(faultInjectingClient).
+		NewRequest().
+		WithContext(context.Background()).
+		AddPathParameters(map[string]interface{}{"id": 42}).
+		Get(suite.url + "/users/{id}")
+
+	suite.Equal(utilError.HttpError{StatusCode: http.StatusServiceUnavailable, ResponseBody: []byte{}}, err)
 }
 
 type cotsRequest struct {
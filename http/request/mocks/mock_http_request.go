@@ -6,12 +6,15 @@ package mocks
 
 import (
 	context "context"
+	gomock "github.com/golang/mock/gomock"
 	request "github.com/inclusi-blog/gola-utils/http/request"
+	redaction "github.com/inclusi-blog/gola-utils/redaction"
 	trace "github.com/inclusi-blog/gola-utils/trace"
-	gomock "github.com/golang/mock/gomock"
 	validator "gopkg.in/go-playground/validator.v9"
+	io "io"
 	http "net/http"
 	reflect "reflect"
+	time "time"
 )
 
 // MockHttpRequest is a mock of HttpRequest interface
@@ -107,6 +110,20 @@ func (mr *MockHttpRequestMockRecorder) WithFormURLEncoded(arg0 interface{}) *gom
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WithFormURLEncoded", reflect.TypeOf((*MockHttpRequest)(nil).WithFormURLEncoded), arg0)
 }
 
+// WithMultipartBody mocks base method
+func (m *MockHttpRequest) WithMultipartBody(arg0 *request.MultipartBody) request.HttpRequest {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WithMultipartBody", arg0)
+	ret0, _ := ret[0].(request.HttpRequest)
+	return ret0
+}
+
+// WithMultipartBody indicates an expected call of WithMultipartBody
+func (mr *MockHttpRequestMockRecorder) WithMultipartBody(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WithMultipartBody", reflect.TypeOf((*MockHttpRequest)(nil).WithMultipartBody), arg0)
+}
+
 // WithContext mocks base method
 func (m *MockHttpRequest) WithContext(arg0 context.Context) request.HttpRequest {
 	m.ctrl.T.Helper()
@@ -121,6 +138,20 @@ func (mr *MockHttpRequestMockRecorder) WithContext(arg0 interface{}) *gomock.Cal
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WithContext", reflect.TypeOf((*MockHttpRequest)(nil).WithContext), arg0)
 }
 
+// WithTimeout mocks base method
+func (m *MockHttpRequest) WithTimeout(arg0 time.Duration) request.HttpRequest {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WithTimeout", arg0)
+	ret0, _ := ret[0].(request.HttpRequest)
+	return ret0
+}
+
+// WithTimeout indicates an expected call of WithTimeout
+func (mr *MockHttpRequestMockRecorder) WithTimeout(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WithTimeout", reflect.TypeOf((*MockHttpRequest)(nil).WithTimeout), arg0)
+}
+
 // WithOauth mocks base method
 func (m *MockHttpRequest) WithOauth() request.HttpRequest {
 	m.ctrl.T.Helper()
@@ -205,6 +236,20 @@ func (mr *MockHttpRequestMockRecorder) ResponseTraceHook(hookFunc interface{}) *
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResponseTraceHook", reflect.TypeOf((*MockHttpRequest)(nil).ResponseTraceHook), hookFunc)
 }
 
+// WithRedactor mocks base method
+func (m *MockHttpRequest) WithRedactor(redactor redaction.Redactor) request.HttpRequest {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WithRedactor", redactor)
+	ret0, _ := ret[0].(request.HttpRequest)
+	return ret0
+}
+
+// WithRedactor indicates an expected call of WithRedactor
+func (mr *MockHttpRequestMockRecorder) WithRedactor(redactor interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WithRedactor", reflect.TypeOf((*MockHttpRequest)(nil).WithRedactor), redactor)
+}
+
 // ResponseAs mocks base method
 func (m *MockHttpRequest) ResponseAs(arg0 interface{}) request.HttpRequest {
 	m.ctrl.T.Helper()
@@ -261,6 +306,34 @@ func (mr *MockHttpRequestMockRecorder) ResponseCookiesAs(arg0 interface{}) *gomo
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResponseCookiesAs", reflect.TypeOf((*MockHttpRequest)(nil).ResponseCookiesAs), arg0)
 }
 
+// OnStatus mocks base method
+func (m *MockHttpRequest) OnStatus(arg0 request.StatusRange, arg1 request.StatusHandlerFunc) request.HttpRequest {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "OnStatus", arg0, arg1)
+	ret0, _ := ret[0].(request.HttpRequest)
+	return ret0
+}
+
+// OnStatus indicates an expected call of OnStatus
+func (mr *MockHttpRequestMockRecorder) OnStatus(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "OnStatus", reflect.TypeOf((*MockHttpRequest)(nil).OnStatus), arg0, arg1)
+}
+
+// ResponseAsForStatus mocks base method
+func (m *MockHttpRequest) ResponseAsForStatus(arg0 int, arg1 interface{}) request.HttpRequest {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResponseAsForStatus", arg0, arg1)
+	ret0, _ := ret[0].(request.HttpRequest)
+	return ret0
+}
+
+// ResponseAsForStatus indicates an expected call of ResponseAsForStatus
+func (mr *MockHttpRequestMockRecorder) ResponseAsForStatus(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResponseAsForStatus", reflect.TypeOf((*MockHttpRequest)(nil).ResponseAsForStatus), arg0, arg1)
+}
+
 // AddHeader mocks base method
 func (m *MockHttpRequest) AddHeader(arg0, arg1 string) request.HttpRequest {
 	m.ctrl.T.Helper()
@@ -387,6 +460,34 @@ func (mr *MockHttpRequestMockRecorder) Delete(arg0 interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockHttpRequest)(nil).Delete), arg0)
 }
 
+// Download mocks base method
+func (m *MockHttpRequest) Download(url string, destination io.WriterAt, options request.DownloadOptions) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Download", url, destination, options)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Download indicates an expected call of Download
+func (mr *MockHttpRequestMockRecorder) Download(url, destination, options interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Download", reflect.TypeOf((*MockHttpRequest)(nil).Download), url, destination, options)
+}
+
+// DownloadToFile mocks base method
+func (m *MockHttpRequest) DownloadToFile(url, filePath string, options request.DownloadOptions) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DownloadToFile", url, filePath, options)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DownloadToFile indicates an expected call of DownloadToFile
+func (mr *MockHttpRequestMockRecorder) DownloadToFile(url, filePath, options interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DownloadToFile", reflect.TypeOf((*MockHttpRequest)(nil).DownloadToFile), url, filePath, options)
+}
+
 // MockHttpRequestBuilder is a mock of HttpRequestBuilder interface
 type MockHttpRequestBuilder struct {
 	ctrl     *gomock.Controller
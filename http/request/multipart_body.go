@@ -0,0 +1,234 @@
+package request
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/inclusi-blog/gola-utils/constants"
+	utilError "github.com/inclusi-blog/gola-utils/golaerror"
+	"github.com/inclusi-blog/gola-utils/http/model"
+)
+
+const defaultFileContentType = "application/octet-stream"
+
+// MultipartFile is satisfied by *os.File as well as fs.File implementations.
+type MultipartFile interface {
+	io.Reader
+	Stat() (os.FileInfo, error)
+}
+
+// MultipartPart is a single part of a multipart/form-data body. Header entries are
+// written in addition to the Content-Disposition and Content-Type derived from the
+// other fields.
+type MultipartPart struct {
+	Name        string
+	FileName    string
+	ContentType string
+	Header      textproto.MIMEHeader
+	Content     io.Reader
+}
+
+// MultipartBody builds a multipart/form-data body whose parts are written in the
+// order they were added. Field names may repeat. The first error encountered while
+// adding parts is kept and surfaced when the request is made.
+type MultipartBody struct {
+	parts    []MultipartPart
+	closers  []io.Closer
+	boundary string
+	err      error
+}
+
+func NewMultipartBody() *MultipartBody {
+	return &MultipartBody{}
+}
+
+func (b *MultipartBody) WithBoundary(boundary string) *MultipartBody {
+	b.boundary = boundary
+	return b
+}
+
+func (b *MultipartBody) AddPart(part MultipartPart) *MultipartBody {
+	if part.Content == nil {
+		part.Content = bytes.NewReader(nil)
+	}
+	b.parts = append(b.parts, part)
+	return b
+}
+
+func (b *MultipartBody) AddField(name, value string) *MultipartBody {
+	return b.AddPart(MultipartPart{Name: name, Content: bytes.NewBufferString(value)})
+}
+
+func (b *MultipartBody) AddBytes(name string, content []byte, contentType string) *MultipartBody {
+	return b.AddPart(MultipartPart{Name: name, ContentType: contentType, Content: bytes.NewReader(content)})
+}
+
+func (b *MultipartBody) AddReader(name, fileName string, content io.Reader, contentType string) *MultipartBody {
+	return b.AddPart(MultipartPart{Name: name, FileName: fileName, ContentType: contentType, Content: content})
+}
+
+// AddFile adds an already opened file. The file name and content type are derived
+// from the file itself, closing the file remains the responsibility of the caller.
+func (b *MultipartBody) AddFile(name string, file MultipartFile) *MultipartBody {
+	info, err := file.Stat()
+	if err != nil {
+		b.setError(err)
+		return b
+	}
+	return b.AddReader(name, info.Name(), file, contentTypeByFileName(info.Name()))
+}
+
+// AddFilePath opens the file at filePath and closes it once the body is built.
+func (b *MultipartBody) AddFilePath(name, filePath, fileName string) *MultipartBody {
+	file, err := os.Open(filePath)
+	if err != nil {
+		b.setError(err)
+		return b
+	}
+	b.closers = append(b.closers, file)
+	if fileName == "" {
+		fileName = filepath.Base(filePath)
+	}
+	return b.AddReader(name, fileName, file, contentTypeByFileName(fileName))
+}
+
+func (b *MultipartBody) AddFileHeader(name string, fileHeader *multipart.FileHeader) *MultipartBody {
+	file, err := fileHeader.Open()
+	if err != nil {
+		b.setError(err)
+		return b
+	}
+	b.closers = append(b.closers, file)
+	contentType := fileHeader.Header.Get(constants.HeaderContentType)
+	if contentType == "" {
+		contentType = contentTypeByFileName(fileHeader.Filename)
+	}
+	return b.AddReader(name, fileHeader.Filename, file, contentType)
+}
+
+func (b *MultipartBody) Err() error {
+	return b.err
+}
+
+// Build writes all parts and returns the encoded body along with its Content-Type.
+// Files opened by the body are closed afterwards, failing to close them fails Build.
+func (b *MultipartBody) Build() ([]byte, string, error) {
+	body, contentType, err := b.build()
+	if closeErr := b.closeAll(); err == nil && closeErr != nil {
+		return nil, "", closeErr
+	}
+	return body, contentType, err
+}
+
+func (b *MultipartBody) build() ([]byte, string, error) {
+	if b.err != nil {
+		return nil, "", b.err
+	}
+
+	bodyBuf := &bytes.Buffer{}
+	bodyWriter := multipart.NewWriter(bodyBuf)
+	if b.boundary != "" {
+		if err := bodyWriter.SetBoundary(b.boundary); err != nil {
+			return nil, "", err
+		}
+	}
+	for _, part := range b.parts {
+		partWriter, err := bodyWriter.CreatePart(part.mimeHeader())
+		if err != nil {
+			return nil, "", err
+		}
+		if _, err = io.Copy(partWriter, part.Content); err != nil {
+			return nil, "", err
+		}
+	}
+	if err := bodyWriter.Close(); err != nil {
+		return nil, "", err
+	}
+	return bodyBuf.Bytes(), bodyWriter.FormDataContentType(), nil
+}
+
+func (b *MultipartBody) setError(err error) {
+	if b.err == nil {
+		b.err = err
+	}
+}
+
+// closeAll closes the files opened by the body and returns the first error.
+func (b *MultipartBody) closeAll() error {
+	var closeErr error
+	for _, closer := range b.closers {
+		if err := closer.Close(); err != nil && closeErr == nil {
+			closeErr = err
+		}
+	}
+	b.closers = nil
+	if closeErr != nil {
+		b.setError(closeErr)
+	}
+	return closeErr
+}
+
+func (part MultipartPart) mimeHeader() textproto.MIMEHeader {
+	h := make(textproto.MIMEHeader)
+	for key, values := range part.Header {
+		for _, value := range values {
+			h.Add(key, value)
+		}
+	}
+	if part.FileName != "" {
+		h.Set("Content-Disposition", fmt.Sprintf(`form-data; name="%s"; filename="%s"`, escapeQuotes(part.Name), escapeQuotes(part.FileName)))
+	} else {
+		h.Set("Content-Disposition", fmt.Sprintf(`form-data; name="%s"`, escapeQuotes(part.Name)))
+	}
+	if part.ContentType != "" {
+		h.Set(constants.HeaderContentType, part.ContentType)
+	} else if part.FileName != "" && h.Get(constants.HeaderContentType) == "" {
+		h.Set(constants.HeaderContentType, defaultFileContentType)
+	}
+	return h
+}
+
+func contentTypeByFileName(fileName string) string {
+	if contentType := mime.TypeByExtension(filepath.Ext(fileName)); contentType != "" {
+		return contentType
+	}
+	return defaultFileContentType
+}
+
+// multipartBodyFromFormData keeps the behaviour of WithFormURLEncoded on top of
+// MultipartBody. Keys are sorted so that the part order is stable between calls.
+func multipartBodyFromFormData(formData map[string]interface{}) *MultipartBody {
+	keys := make([]string, 0, len(formData))
+	for key := range formData {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	body := NewMultipartBody()
+	for _, key := range keys {
+		switch value := formData[key].(type) {
+		case multipart.FileHeader:
+			body.AddFileHeader(key, &value)
+		case string:
+			body.AddField(key, value)
+		case []byte:
+			body.AddBytes(key, value, "application/json")
+		case model.FileUploadContent:
+			body.AddFilePath(key, value.FilePath, value.FileName)
+		default:
+			body.setError(utilError.Error{
+				ErrorCode:      "ERR_INVALID_REQUEST_TYPE",
+				ErrorMessage:   "only multipart files and strings are supported",
+				AdditionalData: nil,
+			})
+		}
+	}
+	return body
+}
@@ -0,0 +1,197 @@
+package request
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"os"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/inclusi-blog/gola-utils/http/client/mocks"
+	"github.com/inclusi-blog/gola-utils/http/model"
+	"github.com/stretchr/testify/suite"
+)
+
+type receivedPart struct {
+	name        string
+	fileName    string
+	contentType string
+	header      textproto.MIMEHeader
+	content     string
+}
+
+type failingCloser struct {
+	err error
+}
+
+func (c failingCloser) Close() error {
+	return c.err
+}
+
+type MultipartBodyTestSuite struct {
+	suite.Suite
+	mockCtrl           *gomock.Controller
+	mockHttpClient     *mocks.MockHttpClient
+	httpRequestBuilder HttpRequestBuilder
+	url                string
+}
+
+func TestMultipartBodyTestSuite(t *testing.T) {
+	suite.Run(t, new(MultipartBodyTestSuite))
+}
+
+func (suite *MultipartBodyTestSuite) SetupTest() {
+	suite.mockCtrl = gomock.NewController(suite.T())
+	suite.mockHttpClient = mocks.NewMockHttpClient(suite.mockCtrl)
+	suite.httpRequestBuilder = NewHttpRequestBuilder(suite.mockHttpClient)
+	suite.url = "http://dummyurl.com/upload"
+}
+
+func (suite *MultipartBodyTestSuite) TearDownTest() {
+	suite.mockCtrl.Finish()
+}
+
+func (suite *MultipartBodyTestSuite) readParts(request *http.Request) []receivedPart {
+	_, params, err := mime.ParseMediaType(request.Header.Get("Content-Type"))
+	suite.Nil(err)
+	reader := multipart.NewReader(request.Body, params["boundary"])
+	var parts []receivedPart
+	for part, err := reader.NextPart(); err == nil; part, err = reader.NextPart() {
+		content, _ := ioutil.ReadAll(part)
+		parts = append(parts, receivedPart{
+			name:        part.FormName(),
+			fileName:    part.FileName(),
+			contentType: part.Header.Get("Content-Type"),
+			header:      part.Header,
+			content:     string(content),
+		})
+	}
+	return parts
+}
+
+func (suite *MultipartBodyTestSuite) expectParts(assertParts func([]receivedPart)) {
+	suite.mockHttpClient.EXPECT().Do(gomock.Any()).DoAndReturn(func(actualRequest *http.Request) (*http.Response, error) {
+		assertParts(suite.readParts(actualRequest))
+		return &http.Response{StatusCode: http.StatusOK, Body: ioutil.NopCloser(bytes.NewBufferString(""))}, nil
+	})
+}
+
+func (suite *MultipartBodyTestSuite) TestShouldWritePartsInOrderWithRepeatedNamesAndHeaders() {
+	suite.expectParts(func(parts []receivedPart) {
+		suite.Len(parts, 4)
+		suite.Equal("tag", parts[0].name)
+		suite.Equal("first", parts[0].content)
+		suite.Equal("tag", parts[1].name)
+		suite.Equal("second", parts[1].content)
+		suite.Equal("metadata", parts[2].name)
+		suite.Equal("application/json", parts[2].contentType)
+		suite.Equal(`{"id":1}`, parts[2].content)
+		suite.Equal("attachment", parts[3].name)
+		suite.Equal("notes.txt", parts[3].fileName)
+		suite.Equal("text/plain", parts[3].contentType)
+		suite.Equal("v2", parts[3].header.Get("X-Part-Version"))
+		suite.Equal("some notes", parts[3].content)
+	})
+
+	body := NewMultipartBody().
+		AddField("tag", "first").
+		AddField("tag", "second").
+		AddBytes("metadata", []byte(`{"id":1}`), "application/json").
+		AddPart(MultipartPart{
+			Name:        "attachment",
+			FileName:    "notes.txt",
+			ContentType: "text/plain",
+			Header:      textproto.MIMEHeader{"X-Part-Version": {"v2"}},
+			Content:     bytes.NewBufferString("some notes"),
+		})
+
+	err := suite.httpRequestBuilder.
+		NewRequest().
+		WithMultipartBody(body).
+		Post(suite.url)
+
+	suite.Nil(err)
+}
+
+func (suite *MultipartBodyTestSuite) TestShouldAddOpenedFileWithNameAndDefaultContentType() {
+	content, _ := ioutil.ReadFile("testdata/AC_ENTRY_POSTING_FEED_20201010150405")
+	suite.expectParts(func(parts []receivedPart) {
+		suite.Len(parts, 1)
+		suite.Equal("feed", parts[0].name)
+		suite.Equal("AC_ENTRY_POSTING_FEED_20201010150405", parts[0].fileName)
+		suite.Equal(defaultFileContentType, parts[0].contentType)
+		suite.Equal(string(content), parts[0].content)
+	})
+	file, _ := os.Open("testdata/AC_ENTRY_POSTING_FEED_20201010150405")
+	defer file.Close()
+
+	err := suite.httpRequestBuilder.
+		NewRequest().
+		WithMultipartBody(NewMultipartBody().AddFile("feed", file)).
+		Post(suite.url)
+
+	suite.Nil(err)
+}
+
+func (suite *MultipartBodyTestSuite) TestShouldReturnBuildErrorWhenFileCanNotBeOpened() {
+	err := suite.httpRequestBuilder.
+		NewRequest().
+		WithMultipartBody(NewMultipartBody().
+			AddField("name", "value").
+			AddFilePath("feed", "testdata/missing", "")).
+		Post(suite.url)
+
+	suite.True(os.IsNotExist(err))
+}
+
+func (suite *MultipartBodyTestSuite) TestShouldReturnBuildErrorWhenFileCanNotBeClosed() {
+	body := NewMultipartBody().AddFilePath("feed", "testdata/AC_ENTRY_POSTING_FEED_20201010150405", "")
+	body.closers = append(body.closers, failingCloser{err: errors.New("file already closed")})
+
+	content, contentType, err := body.Build()
+
+	suite.EqualError(err, "file already closed")
+	suite.Nil(content)
+	suite.Empty(contentType)
+	suite.EqualError(body.Err(), "file already closed")
+}
+
+func (suite *MultipartBodyTestSuite) TestShouldReturnBuildErrorForMissingFileUploadContent() {
+	formData := map[string]interface{}{
+		"fileContent": model.FileUploadContent{FilePath: "testdata/missing", FileName: "missing"},
+	}
+
+	err := suite.httpRequestBuilder.
+		NewRequest().
+		WithFormURLEncoded(formData).
+		Post(suite.url)
+
+	suite.True(os.IsNotExist(err))
+}
+
+func (suite *MultipartBodyTestSuite) TestShouldWriteFormDataPartsSortedByKey() {
+	suite.expectParts(func(parts []receivedPart) {
+		suite.Len(parts, 3)
+		suite.Equal("a", parts[0].name)
+		suite.Equal("b", parts[1].name)
+		suite.Equal("application/json", parts[1].contentType)
+		suite.Equal("c", parts[2].name)
+	})
+	formData := map[string]interface{}{
+		"c": "3",
+		"a": "1",
+		"b": []byte(`{"b":2}`),
+	}
+
+	err := suite.httpRequestBuilder.
+		NewRequest().
+		WithFormURLEncoded(formData).
+		Post(suite.url)
+
+	suite.Nil(err)
+}
@@ -0,0 +1,111 @@
+package request
+
+import (
+	"io/ioutil"
+	"net/http"
+
+	"github.com/inclusi-blog/gola-utils/trace"
+	"go.opencensus.io/plugin/ochttp"
+)
+
+// StatusHandlerFunc handles a response whose status matched an OnStatus registration.
+// Returning nil makes the request succeed, any other error is returned to the caller
+// and marks the span as errored.
+type StatusHandlerFunc func(statusCode int, responseBody []byte) error
+
+type StatusRange struct {
+	From int
+	To   int
+}
+
+func Status(statusCode int) StatusRange {
+	return StatusRange{From: statusCode, To: statusCode}
+}
+
+func StatusesBetween(from, to int) StatusRange {
+	return StatusRange{From: from, To: to}
+}
+
+func (s StatusRange) contains(statusCode int) bool {
+	return statusCode >= s.From && statusCode <= s.To
+}
+
+type statusHandler struct {
+	statuses StatusRange
+	handle   func(r httpRequest, response *http.Response, responseBody []byte) error
+}
+
+// OnStatus registers a handler for a status code or range. Registrations are checked
+// in the order they were added and take precedence over the default handling, so an
+// expected status such as 404 can be turned into a successful call.
+func (r httpRequest) OnStatus(statuses StatusRange, handler StatusHandlerFunc) HttpRequest {
+	return r.withStatusHandler(statusHandler{
+		statuses: statuses,
+		handle: func(_ httpRequest, response *http.Response, responseBody []byte) error {
+			return handler(response.StatusCode, responseBody)
+		},
+	})
+}
+
+// ResponseAsForStatus decodes the body of a response with the given status into
+// responseModel, the same way ResponseAs does for successful responses.
+func (r httpRequest) ResponseAsForStatus(statusCode int, responseModel interface{}) HttpRequest {
+	return r.withStatusHandler(statusHandler{
+		statuses: Status(statusCode),
+		handle: func(r httpRequest, response *http.Response, responseBody []byte) error {
+			return r.decodeResponseBytes(response, responseBody, responseModel)
+		},
+	})
+}
+
+func (r httpRequest) withStatusHandler(handler statusHandler) httpRequest {
+	handlers := make([]statusHandler, len(r.statusHandlers), len(r.statusHandlers)+1)
+	copy(handlers, r.statusHandlers)
+	r.statusHandlers = append(handlers, handler)
+	return r
+}
+
+func (r httpRequest) statusHandlerFor(statusCode int) (statusHandler, bool) {
+	for _, handler := range r.statusHandlers {
+		if handler.statuses.contains(statusCode) {
+			return handler, true
+		}
+	}
+	return statusHandler{}, false
+}
+
+func (r httpRequest) handleStatus(handler statusHandler, response *http.Response, httpRequest *http.Request, dataSpan trace.Span) error {
+	responseBytes, readError := ioutil.ReadAll(response.Body)
+	if readError != nil {
+		addStatusTags(response.StatusCode, true, dataSpan)
+		r.logHttpResponse("Response body read Error: "+readError.Error(), httpRequest, dataSpan)
+		return readError
+	}
+
+	handlerError := handler.handle(r, response, responseBytes)
+	addStatusTags(response.StatusCode, handlerError != nil, dataSpan)
+	if isLoggingDisabled(httpRequest.URL.Path) {
+		r.logHttpResponse("Response body not logged for security reasons", httpRequest, dataSpan)
+	} else {
+		r.logHttpResponse(string(responseBytes), httpRequest, dataSpan)
+	}
+
+	if r.responseCookies != nil {
+		*r.responseCookies = response.Cookies()
+	}
+	closeError := response.Body.Close()
+	if handlerError != nil {
+		return handlerError
+	}
+	return closeError
+}
+
+func addStatusTags(statusCode int, isError bool, span trace.Span) {
+	if span == nil {
+		return
+	}
+	span.AddAttributes(trace.Int64Attribute(ochttp.StatusCodeAttribute, int64(statusCode)))
+	if isError {
+		span.AddAttributes(trace.BoolAttribute(ErrorTraceAttribute, true))
+	}
+}
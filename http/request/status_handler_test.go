@@ -0,0 +1,151 @@
+package request
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/inclusi-blog/gola-utils/http/client/mocks"
+	"github.com/inclusi-blog/gola-utils/tracing/tracetest"
+	"github.com/stretchr/testify/suite"
+	"go.opencensus.io/plugin/ochttp"
+	"go.opencensus.io/trace"
+)
+
+type conflictResponse struct {
+	ExistingId string `json:"existingId" validate:"required"`
+}
+
+var errResourceMissing = errors.New("resource missing")
+
+type StatusHandlerTestSuite struct {
+	suite.Suite
+	mockCtrl           *gomock.Controller
+	mockHttpClient     *mocks.MockHttpClient
+	httpRequestBuilder HttpRequestBuilder
+	url                string
+}
+
+func TestStatusHandlerTestSuite(t *testing.T) {
+	suite.Run(t, new(StatusHandlerTestSuite))
+}
+
+func (suite *StatusHandlerTestSuite) SetupTest() {
+	suite.mockCtrl = gomock.NewController(suite.T())
+	suite.mockHttpClient = mocks.NewMockHttpClient(suite.mockCtrl)
+	suite.httpRequestBuilder = NewHttpRequestBuilder(suite.mockHttpClient)
+	suite.url = "http://dummyurl.com/resources"
+}
+
+func (suite *StatusHandlerTestSuite) TearDownTest() {
+	suite.mockCtrl.Finish()
+}
+
+func (suite *StatusHandlerTestSuite) respondWith(statusCode int, body string) {
+	response := http.Response{StatusCode: statusCode, Body: ioutil.NopCloser(bytes.NewBufferString(body))}
+	suite.mockHttpClient.EXPECT().Do(gomock.Any()).Return(&response, nil)
+}
+
+func (suite *StatusHandlerTestSuite) TestShouldTreatHandledNotFoundAsSuccessWithoutErrorSpan() {
+	e := tracetest.Start()
+	defer e.Stop()
+	ctx, s := trace.StartSpan(context.Background(), "test-span", trace.WithSampler(trace.AlwaysSample()))
+	suite.respondWith(http.StatusNotFound, `{"message":"not found"}`)
+
+	var statusCode int
+	var actualResponse dummyResponse
+	err := suite.httpRequestBuilder.
+		NewRequest().
+		WithContext(ctx).
+		ResponseStatusCodeAs(&statusCode).
+		ResponseAs(&actualResponse).
+		OnStatus(Status(http.StatusNotFound), func(int, []byte) error { return nil }).
+		Get(suite.url)
+
+	s.End()
+	suite.Nil(err)
+	suite.Equal(http.StatusNotFound, statusCode)
+	suite.Equal(dummyResponse{}, actualResponse)
+	suite.Equal(int64(404), e.Spans()[0].Attributes[ochttp.StatusCodeAttribute])
+	suite.Nil(e.Spans()[0].Attributes[ErrorTraceAttribute])
+}
+
+func (suite *StatusHandlerTestSuite) TestShouldDecodeStatusSpecificModel() {
+	suite.respondWith(http.StatusConflict, `{"existingId":"abc"}`)
+
+	var actualResponse dummyResponse
+	var conflict conflictResponse
+	err := suite.httpRequestBuilder.
+		NewRequest().
+		ResponseAs(&actualResponse).
+		ResponseAsForStatus(http.StatusConflict, &conflict).
+		Post(suite.url)
+
+	suite.Nil(err)
+	suite.Equal(conflictResponse{ExistingId: "abc"}, conflict)
+	suite.Equal(dummyResponse{}, actualResponse)
+}
+
+func (suite *StatusHandlerTestSuite) TestShouldValidateStatusSpecificModel() {
+	suite.respondWith(http.StatusConflict, `{}`)
+
+	var conflict conflictResponse
+	err := suite.httpRequestBuilder.
+		NewRequest().
+		ResponseAsForStatus(http.StatusConflict, &conflict).
+		Post(suite.url)
+
+	suite.NotNil(err)
+}
+
+func (suite *StatusHandlerTestSuite) TestShouldReturnCustomErrorAndMarkSpanAsError() {
+	e := tracetest.Start()
+	defer e.Stop()
+	ctx, s := trace.StartSpan(context.Background(), "test-span", trace.WithSampler(trace.AlwaysSample()))
+	suite.respondWith(http.StatusUnprocessableEntity, `{"field":"name"}`)
+
+	var receivedStatus int
+	var receivedBody string
+	err := suite.httpRequestBuilder.
+		NewRequest().
+		WithContext(ctx).
+		OnStatus(StatusesBetween(400, 499), func(statusCode int, responseBody []byte) error {
+			receivedStatus = statusCode
+			receivedBody = string(responseBody)
+			return errResourceMissing
+		}).
+		Post(suite.url)
+
+	s.End()
+	suite.Equal(errResourceMissing, err)
+	suite.Equal(http.StatusUnprocessableEntity, receivedStatus)
+	suite.Equal(`{"field":"name"}`, receivedBody)
+	suite.Equal(true, e.Spans()[0].Attributes[ErrorTraceAttribute])
+}
+
+func (suite *StatusHandlerTestSuite) TestShouldUseFirstMatchingRegistration() {
+	suite.respondWith(http.StatusNotFound, ``)
+
+	err := suite.httpRequestBuilder.
+		NewRequest().
+		OnStatus(Status(http.StatusNotFound), func(int, []byte) error { return nil }).
+		OnStatus(StatusesBetween(400, 499), func(int, []byte) error { return errResourceMissing }).
+		Get(suite.url)
+
+	suite.Nil(err)
+}
+
+func (suite *StatusHandlerTestSuite) TestShouldFallBackToDefaultHandlingForUnregisteredStatus() {
+	suite.respondWith(http.StatusInternalServerError, `failure`)
+
+	err := suite.httpRequestBuilder.
+		NewRequest().
+		OnStatus(Status(http.StatusNotFound), func(int, []byte) error { return nil }).
+		Get(suite.url)
+
+	suite.EqualError(err, "StatusCode : 500, ResponseBody : failure")
+}
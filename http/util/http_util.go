@@ -5,6 +5,7 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/inclusi-blog/gola-utils/constants"
 	"github.com/inclusi-blog/gola-utils/logging"
+	"github.com/inclusi-blog/gola-utils/tracing/propagation"
 	"go.opencensus.io/plugin/ochttp"
 	"net"
 	"net/http"
@@ -18,7 +19,7 @@ func GetHttpClientWithTracing() *http.Client {
 			Timeout: 50 * time.Second,
 		}).DialContext,
 	}
-	return &http.Client{Transport: &ochttp.Transport{Base: transport}}
+	return &http.Client{Transport: &ochttp.Transport{Base: transport, Propagation: propagation.Default()}}
 }
 
 func getBearerTokenFromHeader(context *gin.Context) (string, error) {
@@ -0,0 +1,53 @@
+package util
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+)
+
+const maxTimeoutValue = 99999999
+
+var timeoutUnits = []struct {
+	unit     byte
+	duration time.Duration
+}{
+	{'n', time.Nanosecond},
+	{'u', time.Microsecond},
+	{'m', time.Millisecond},
+	{'S', time.Second},
+	{'M', time.Minute},
+	{'H', time.Hour},
+}
+
+// EncodeTimeout formats a remaining time budget the way the grpc-timeout header does:
+// at most 8 digits followed by a unit (H, M, S, m, u or n). Values are rounded up.
+func EncodeTimeout(timeout time.Duration) string {
+	if timeout <= 0 {
+		return "0n"
+	}
+	for _, timeoutUnit := range timeoutUnits {
+		value := (timeout + timeoutUnit.duration - 1) / timeoutUnit.duration
+		if value <= maxTimeoutValue {
+			return strconv.FormatInt(int64(value), 10) + string(timeoutUnit.unit)
+		}
+	}
+	return strconv.Itoa(maxTimeoutValue) + "H"
+}
+
+func DecodeTimeout(encodedTimeout string) (time.Duration, error) {
+	if len(encodedTimeout) < 2 || len(encodedTimeout) > 9 {
+		return 0, fmt.Errorf("invalid timeout %q", encodedTimeout)
+	}
+	value, err := strconv.ParseInt(encodedTimeout[:len(encodedTimeout)-1], 10, 64)
+	if err != nil || value < 0 {
+		return 0, fmt.Errorf("invalid timeout %q", encodedTimeout)
+	}
+	unit := encodedTimeout[len(encodedTimeout)-1]
+	for _, timeoutUnit := range timeoutUnits {
+		if timeoutUnit.unit == unit {
+			return time.Duration(value) * timeoutUnit.duration, nil
+		}
+	}
+	return 0, fmt.Errorf("invalid timeout unit in %q", encodedTimeout)
+}
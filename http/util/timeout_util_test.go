@@ -0,0 +1,45 @@
+package util
+
+import (
+	"github.com/stretchr/testify/suite"
+	"testing"
+	"time"
+)
+
+type TimeoutUtilTestSuite struct {
+	suite.Suite
+}
+
+func TestTimeoutUtilTestSuite(t *testing.T) {
+	suite.Run(t, new(TimeoutUtilTestSuite))
+}
+
+func (suite TimeoutUtilTestSuite) TestShouldEncodeTimeoutWithSmallestFittingUnit() {
+	suite.Equal("1500000u", EncodeTimeout(1500*time.Millisecond))
+	suite.Equal("250000m", EncodeTimeout(250*time.Second))
+	suite.Equal("2n", EncodeTimeout(2*time.Nanosecond))
+	suite.Equal("0n", EncodeTimeout(-time.Second))
+}
+
+func (suite TimeoutUtilTestSuite) TestShouldDecodeTimeout() {
+	timeout, err := DecodeTimeout("1500m")
+	suite.Nil(err)
+	suite.Equal(1500*time.Millisecond, timeout)
+
+	timeout, err = DecodeTimeout("2H")
+	suite.Nil(err)
+	suite.Equal(2*time.Hour, timeout)
+}
+
+func (suite TimeoutUtilTestSuite) TestShouldRoundTripEncodedTimeout() {
+	timeout, err := DecodeTimeout(EncodeTimeout(90 * time.Minute))
+	suite.Nil(err)
+	suite.Equal(90*time.Minute, timeout)
+}
+
+func (suite TimeoutUtilTestSuite) TestShouldReturnErrorForInvalidTimeout() {
+	for _, encodedTimeout := range []string{"", "5", "m", "-1m", "10x", "123456789m"} {
+		_, err := DecodeTimeout(encodedTimeout)
+		suite.NotNil(err, encodedTimeout)
+	}
+}
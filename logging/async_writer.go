@@ -0,0 +1,274 @@
+package logging
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	OverflowBlock          = "block"
+	OverflowDropOldest     = "drop-oldest"
+	OverflowDropDebugFirst = "drop-debug-first"
+)
+
+// AsyncConfig makes entries be written by a background goroutine through a buffer of
+// BufferSize entries. When the buffer is full, OverflowPolicy decides: "block" waits
+// for space, "drop-oldest" drops the oldest entry and "drop-debug-first" drops the
+// oldest of the most verbose entries, or the new entry when it is the most verbose.
+// Fatal and panic entries, and span annotations, are always written synchronously.
+type AsyncConfig struct {
+	BufferSize     int    `json:"bufferSize"`
+	OverflowPolicy string `json:"overflowPolicy"`
+}
+
+type AsyncStats struct {
+	Buffered       int               `json:"buffered"`
+	Dropped        uint64            `json:"dropped"`
+	DroppedByLevel map[string]uint64 `json:"droppedByLevel"`
+}
+
+type asyncRecord struct {
+	entry    golaLoggerEntry
+	level    logrus.Level
+	message  string
+	sequence uint64
+}
+
+// AsyncWriter writes the entries of one logrus logger or backend in the background.
+// Buffered entries are queued by level and ordered by sequence, so the oldest entry and
+// the oldest of the most verbose entries are both found without scanning the buffer.
+type AsyncWriter struct {
+	mutex    sync.Mutex
+	cond     *sync.Cond
+	policy   string
+	capacity int
+	queues   [logrus.TraceLevel + 1][]asyncRecord
+	sequence uint64
+	size     int
+	writing  bool
+	closed   bool
+	dropped  map[logrus.Level]uint64
+	done     chan struct{}
+}
+
+// asyncWriters holds the async writer of each logrus logger or backend.
+var (
+	asyncWriters    sync.Map
+	exitHandlerOnce sync.Once
+)
+
+func newAsyncWriter(config AsyncConfig) (*AsyncWriter, error) {
+	if config.BufferSize < 0 {
+		return nil, errors.New("async log buffer size must not be negative")
+	}
+	switch config.OverflowPolicy {
+	case "":
+		config.OverflowPolicy = OverflowBlock
+	case OverflowBlock, OverflowDropOldest, OverflowDropDebugFirst:
+	default:
+		return nil, fmt.Errorf("not a valid async log overflow policy: %q", config.OverflowPolicy)
+	}
+	writer := &AsyncWriter{
+		policy:   config.OverflowPolicy,
+		capacity: config.BufferSize,
+		dropped:  make(map[logrus.Level]uint64),
+		done:     make(chan struct{}),
+	}
+	writer.cond = sync.NewCond(&writer.mutex)
+	go writer.run()
+	return writer, nil
+}
+
+// SetAsync writes every entry of the underlying logrus logger or backend through a new
+// AsyncWriter. The previous writer is flushed and closed, a zero BufferSize switches
+// back to synchronous writes and returns a nil writer. Callers must defer Close on the
+// returned writer, which is safe on nil, so buffered entries are written before the
+// process ends; only fatal entries flush the writers on their own.
+func (c loggerConfig) SetAsync(config AsyncConfig) (*AsyncWriter, error) {
+	var writer *AsyncWriter
+	if config.BufferSize != 0 {
+		var err error
+		if writer, err = newAsyncWriter(config); err != nil {
+			return nil, err
+		}
+		exitHandlerOnce.Do(func() { logrus.RegisterExitHandler(closeAsyncWriters) })
+	}
+	gate := c.root.levelGate()
+	previous, hasPrevious := asyncWriters.Load(gate)
+	if writer != nil {
+		asyncWriters.Store(gate, writer)
+	} else {
+		asyncWriters.Delete(gate)
+	}
+	if hasPrevious {
+		previous.(*AsyncWriter).Close()
+	}
+	return writer, nil
+}
+
+// closeAsyncWriters flushes and closes the writer of every logger when logrus exits.
+func closeAsyncWriters() {
+	asyncWriters.Range(func(_, writer interface{}) bool {
+		writer.(*AsyncWriter).Close()
+		return true
+	})
+}
+
+// output writes the entry through the async writer of the logger, if it has one.
+func (l golaLoggerEntry) output(level logrus.Level, printType string, format string, value ...interface{}) {
+	writer, isAsync := asyncWriters.Load(l.levelGate())
+	if isAsync {
+		if level > logrus.FatalLevel && writer.(*AsyncWriter).enqueue(l, level, formatMessage(printType, format, value...)) {
+			return
+		}
+		writer.(*AsyncWriter).Flush()
+	}
+	l.write(level, printType, format, value...)
+}
+
+func (w *AsyncWriter) enqueue(entry golaLoggerEntry, level logrus.Level, message string) bool {
+	if entry.stdEntry != nil {
+		entry.stdEntry = entry.stdEntry.WithTime(time.Now())
+	}
+	record := asyncRecord{entry: entry, level: level, message: message}
+
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	for w.policy == OverflowBlock && w.size == w.capacity && !w.closed {
+		w.cond.Wait()
+	}
+	if w.closed {
+		return false
+	}
+	if w.size == w.capacity {
+		if !w.evictFor(level) {
+			w.dropped[level]++
+			return true
+		}
+	}
+	w.sequence++
+	record.sequence = w.sequence
+	w.queues[level] = append(w.queues[level], record)
+	w.size++
+	w.cond.Broadcast()
+	return true
+}
+
+// evictFor makes room for an entry of level, it reports false when the entry itself
+// should be dropped.
+func (w *AsyncWriter) evictFor(level logrus.Level) bool {
+	evicted := w.oldest()
+	if w.policy == OverflowDropDebugFirst {
+		if evicted = w.mostVerbose(); evicted <= level {
+			return false
+		}
+	}
+	w.dropped[evicted]++
+	w.pop(evicted)
+	return true
+}
+
+// oldest returns the level of the oldest buffered entry, the buffer must not be empty.
+func (w *AsyncWriter) oldest() logrus.Level {
+	oldest := logrus.TraceLevel
+	for level := logrus.PanicLevel; level <= logrus.TraceLevel; level++ {
+		queue := w.queues[level]
+		if len(queue) > 0 && (len(w.queues[oldest]) == 0 || queue[0].sequence < w.queues[oldest][0].sequence) {
+			oldest = level
+		}
+	}
+	return oldest
+}
+
+// mostVerbose returns the most verbose buffered level, the buffer must not be empty.
+func (w *AsyncWriter) mostVerbose() logrus.Level {
+	level := logrus.TraceLevel
+	for len(w.queues[level]) == 0 {
+		level--
+	}
+	return level
+}
+
+// pop removes the oldest buffered entry of level.
+func (w *AsyncWriter) pop(level logrus.Level) asyncRecord {
+	queue := w.queues[level]
+	record := queue[0]
+	queue[0] = asyncRecord{}
+	if len(queue) == 1 {
+		w.queues[level] = queue[:0]
+	} else {
+		w.queues[level] = queue[1:]
+	}
+	w.size--
+	return record
+}
+
+func (w *AsyncWriter) run() {
+	defer close(w.done)
+	for {
+		w.mutex.Lock()
+		for w.size == 0 && !w.closed {
+			w.cond.Wait()
+		}
+		if w.size == 0 {
+			w.mutex.Unlock()
+			return
+		}
+		record := w.pop(w.oldest())
+		w.writing = true
+		w.cond.Broadcast()
+		w.mutex.Unlock()
+
+		record.write()
+
+		w.mutex.Lock()
+		w.writing = false
+		w.cond.Broadcast()
+		w.mutex.Unlock()
+	}
+}
+
+// write writes the entry through its logger, it was enabled when it was logged even if
+// the level of the logger changed since.
+func (r asyncRecord) write() {
+	r.entry.write(r.level, SPRINT, "", r.message)
+}
+
+// Flush waits until every buffered entry is written.
+func (w *AsyncWriter) Flush() {
+	if w == nil {
+		return
+	}
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	for w.size > 0 || w.writing {
+		w.cond.Wait()
+	}
+}
+
+// Close flushes the writer and stops it, later entries are written synchronously.
+func (w *AsyncWriter) Close() {
+	if w == nil {
+		return
+	}
+	w.mutex.Lock()
+	w.closed = true
+	w.cond.Broadcast()
+	w.mutex.Unlock()
+	<-w.done
+}
+
+func (w *AsyncWriter) Stats() AsyncStats {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	stats := AsyncStats{Buffered: w.size, DroppedByLevel: make(map[string]uint64, len(w.dropped))}
+	for level, dropped := range w.dropped {
+		stats.DroppedByLevel[level.String()] = dropped
+		stats.Dropped += dropped
+	}
+	return stats
+}
@@ -0,0 +1,232 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	logrusTest "github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/suite"
+	"go.opencensus.io/trace"
+)
+
+type gatedBackend struct {
+	fakeBackend
+	release     chan struct{}
+	releaseOnce sync.Once
+}
+
+func (b *gatedBackend) open() {
+	b.releaseOnce.Do(func() { close(b.release) })
+}
+
+func (b *gatedBackend) Log(ctx context.Context, level Level, fields GolaFields, message string) {
+	<-b.release
+	b.fakeBackend.Log(ctx, level, fields, message)
+}
+
+type AsyncWriterTestSuite struct {
+	suite.Suite
+	backend *gatedBackend
+	logger  Logger
+}
+
+func TestAsyncWriterTestSuite(t *testing.T) {
+	suite.Run(t, new(AsyncWriterTestSuite))
+}
+
+func (suite *AsyncWriterTestSuite) SetupTest() {
+	suite.backend = &gatedBackend{fakeBackend: fakeBackend{level: DebugLevel}, release: make(chan struct{})}
+	suite.logger = NewLogger(suite.backend)
+}
+
+func (suite *AsyncWriterTestSuite) TearDownTest() {
+	suite.backend.open()
+	if writer, ok := asyncWriters.Load(suite.backend); ok {
+		writer.(*AsyncWriter).Close()
+	}
+	asyncWriters.Delete(suite.backend)
+}
+
+func (suite *AsyncWriterTestSuite) setAsync(config AsyncConfig) *AsyncWriter {
+	writer, err := BackendConfig(suite.backend).SetAsync(config)
+	suite.Nil(err)
+	return writer
+}
+
+// logInFlight logs an entry and waits until the writer blocks on it in the backend.
+func (suite *AsyncWriterTestSuite) logInFlight(writer *AsyncWriter, message string) {
+	suite.logger.Info(message)
+	suite.Eventually(func() bool { return writer.Stats().Buffered == 0 }, time.Second, time.Millisecond)
+}
+
+func (suite *AsyncWriterTestSuite) messages() []string {
+	var messages []string
+	for _, entry := range suite.backend.entries {
+		messages = append(messages, entry.message)
+	}
+	return messages
+}
+
+func (suite *AsyncWriterTestSuite) releaseAndFlush(writer *AsyncWriter) {
+	suite.backend.open()
+	writer.Flush()
+}
+
+func (suite *AsyncWriterTestSuite) TestShouldDropOldestEntryWhenFull() {
+	writer := suite.setAsync(AsyncConfig{BufferSize: 2, OverflowPolicy: OverflowDropOldest})
+	suite.logInFlight(writer, "1")
+
+	suite.logger.Warn("2")
+	suite.logger.Info("3")
+	suite.logger.Info("4")
+	suite.releaseAndFlush(writer)
+
+	suite.Equal([]string{"1", "3", "4"}, suite.messages())
+	suite.Equal(AsyncStats{Dropped: 1, DroppedByLevel: map[string]uint64{"warning": 1}}, writer.Stats())
+}
+
+func (suite *AsyncWriterTestSuite) TestShouldDropMostVerboseEntriesFirstWhenFull() {
+	writer := suite.setAsync(AsyncConfig{BufferSize: 2, OverflowPolicy: OverflowDropDebugFirst})
+	suite.logInFlight(writer, "1")
+
+	suite.logger.Debug("debug")
+	suite.logger.Info("info")
+	suite.logger.Warn("warn")
+	suite.logger.Debug("another debug")
+	suite.releaseAndFlush(writer)
+
+	suite.Equal([]string{"1", "info", "warn"}, suite.messages())
+	suite.Equal(AsyncStats{Dropped: 2, DroppedByLevel: map[string]uint64{"debug": 2}}, writer.Stats())
+}
+
+func (suite *AsyncWriterTestSuite) TestShouldKeepLoggedOrderAcrossLevelsAfterDroppingVerboseEntries() {
+	writer := suite.setAsync(AsyncConfig{BufferSize: 3, OverflowPolicy: OverflowDropDebugFirst})
+	suite.logInFlight(writer, "1")
+
+	suite.logger.Info("info")
+	suite.logger.Debug("debug")
+	suite.logger.Warn("warn")
+	suite.logger.Error("error")
+	suite.logger.Info("another info")
+	suite.releaseAndFlush(writer)
+
+	suite.Equal([]string{"1", "info", "warn", "error"}, suite.messages())
+	suite.Equal(AsyncStats{Dropped: 2, DroppedByLevel: map[string]uint64{"debug": 1, "info": 1}}, writer.Stats())
+}
+
+func (suite *AsyncWriterTestSuite) TestShouldBlockWhenFull() {
+	writer := suite.setAsync(AsyncConfig{BufferSize: 1})
+	suite.logInFlight(writer, "1")
+	suite.logger.Info("2")
+
+	logged := make(chan struct{})
+	go func() {
+		suite.logger.Info("3")
+		close(logged)
+	}()
+	select {
+	case <-logged:
+		suite.Fail("expected the entry to wait for buffer space")
+	case <-time.After(20 * time.Millisecond):
+	}
+	suite.releaseAndFlush(writer)
+	<-logged
+	writer.Flush()
+
+	suite.Equal([]string{"1", "2", "3"}, suite.messages())
+	suite.Equal(uint64(0), writer.Stats().Dropped)
+}
+
+func (suite *AsyncWriterTestSuite) TestShouldAnnotateSpanSynchronously() {
+	t := testExporter{}
+	trace.RegisterExporter(&t)
+	defer trace.UnregisterExporter(&t)
+	ctx, s := trace.StartSpan(context.Background(), "test-span", trace.WithSampler(trace.AlwaysSample()))
+	writer := suite.setAsync(AsyncConfig{BufferSize: 1, OverflowPolicy: OverflowDropOldest})
+	suite.logInFlight(writer, "1")
+
+	logger := suite.logger.WithContext(ctx)
+	logger.Info("2")
+	logger.Info("3")
+	s.End()
+
+	suite.Equal(2, len(t.SpanData.Annotations))
+	suite.Equal(uint64(1), writer.Stats().Dropped)
+}
+
+func (suite *AsyncWriterTestSuite) TestShouldWriteSynchronouslyAfterClose() {
+	writer := suite.setAsync(AsyncConfig{BufferSize: 4})
+	suite.backend.open()
+	suite.logger.Info("1")
+	writer.Close()
+	suite.logger.Info("2")
+
+	suite.Equal([]string{"1", "2"}, suite.messages())
+}
+
+func (suite *AsyncWriterTestSuite) TestShouldSwitchBackToSynchronousWrites() {
+	writer := suite.setAsync(AsyncConfig{BufferSize: 4})
+	suite.backend.open()
+	suite.logger.Info("1")
+
+	disabled, err := BackendConfig(suite.backend).SetAsync(AsyncConfig{})
+	suite.Nil(err)
+	suite.Nil(disabled)
+	suite.NotPanics(disabled.Close)
+	suite.logger.Info("2")
+
+	suite.Equal([]string{"1", "2"}, suite.messages())
+	suite.Equal(0, writer.Stats().Buffered)
+}
+
+func (suite *AsyncWriterTestSuite) TestShouldRejectInvalidAsyncConfig() {
+	_, err := BackendConfig(suite.backend).SetAsync(AsyncConfig{BufferSize: -1})
+	suite.EqualError(err, "async log buffer size must not be negative")
+	_, err = BackendConfig(suite.backend).SetAsync(AsyncConfig{BufferSize: 1, OverflowPolicy: "drop-newest"})
+	suite.EqualError(err, `not a valid async log overflow policy: "drop-newest"`)
+}
+
+func TestShouldWriteAsyncLogrusEntriesLoggedAboveLevel(t *testing.T) {
+	logger, hook := logrusTest.NewNullLogger()
+	defer asyncWriters.Delete(logger)
+	entry := newLoggerEntry(context.Background(), logger.WithContext(context.Background()))
+	writer, err := configOf(entry).SetAsync(AsyncConfig{BufferSize: 8})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer writer.Close()
+
+	entry.WithField("attempt", 1).Info("retrying")
+	logger.SetLevel(logrus.WarnLevel)
+	writer.Flush()
+
+	if len(hook.AllEntries()) != 1 || hook.LastEntry().Message != "retrying" || hook.LastEntry().Data["attempt"] != 1 {
+		t.Fatalf("expected the buffered entry to be written, got %v", hook.AllEntries())
+	}
+}
+
+func TestShouldWriteAsyncLogrusEntriesThroughConfiguredLogger(t *testing.T) {
+	var out bytes.Buffer
+	logger := logrus.New()
+	logger.SetOutput(&out)
+	logger.SetFormatter(&logrus.TextFormatter{DisableTimestamp: true})
+	defer asyncWriters.Delete(logger)
+	entry := newLoggerEntry(context.Background(), logger.WithContext(context.Background()))
+	writer, err := configOf(entry).SetAsync(AsyncConfig{BufferSize: 8})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	entry.WithField("attempt", 1).Info("retrying")
+	logger.SetLevel(logrus.WarnLevel)
+	entry.Info("skipped")
+	writer.Close()
+
+	if out.String() != "level=info msg=retrying attempt=1\n" {
+		t.Fatalf("expected the buffered entry to be written by the logger, got %q", out.String())
+	}
+}
@@ -0,0 +1,100 @@
+package logging
+
+import (
+	"context"
+	"sync/atomic"
+
+	"github.com/sirupsen/logrus"
+)
+
+type Level = logrus.Level
+
+const (
+	PanicLevel = logrus.PanicLevel
+	FatalLevel = logrus.FatalLevel
+	ErrorLevel = logrus.ErrorLevel
+	WarnLevel  = logrus.WarnLevel
+	InfoLevel  = logrus.InfoLevel
+	DebugLevel = logrus.DebugLevel
+	TraceLevel = logrus.TraceLevel
+)
+
+// Backend is the SPI for writing entries to a structured logging library other than
+// logrus. Level checks, named levels, sampling and span annotations stay with the
+// Logger, a backend only writes the entries it is handed and keeps the level. Backends
+// may also implement AddHook(GolaLoggerHook) and SetFormatter(string).
+//
+// Backends are used as map keys, so they must be comparable; pointers are the usual
+// choice.
+type Backend interface {
+	Log(ctx context.Context, level Level, fields GolaFields, message string)
+	GetLevel() Level
+	SetLevel(level Level)
+}
+
+type backendHolder struct {
+	backend Backend
+}
+
+var defaultBackendHolder atomic.Value
+
+// NewLogger returns a Logger writing to backend.
+func NewLogger(backend Backend) Logger {
+	return newBackendEntry(context.TODO(), backend)
+}
+
+// SetDefaultBackend makes NewLoggerEntry, and therefore GetLogger without a request
+// logger, write to backend. Passing nil switches back to logrus.
+func SetDefaultBackend(backend Backend) {
+	defaultBackendHolder.Store(backendHolder{backend: backend})
+}
+
+func defaultBackend() Backend {
+	holder, _ := defaultBackendHolder.Load().(backendHolder)
+	return holder.backend
+}
+
+func newBackendEntry(ctx context.Context, backend Backend) *golaLoggerEntry {
+	return &golaLoggerEntry{context: ctx, backend: backend, Data: make(GolaFields, 6)}
+}
+
+// loggerConfig is the Config of the logrus logger or backend root writes to.
+type loggerConfig struct {
+	root *golaLoggerEntry
+}
+
+// DefaultConfig returns the config of the loggers created by NewLoggerEntry at the time
+// of the call, the logrus standard logger or the default backend.
+func DefaultConfig() Config {
+	return configOf(NewLoggerEntry().(*golaLoggerEntry))
+}
+
+// BackendConfig returns the config of the loggers created by NewLogger for backend.
+func BackendConfig(backend Backend) Config {
+	return configOf(newBackendEntry(context.TODO(), backend))
+}
+
+func configOf(entry *golaLoggerEntry) loggerConfig {
+	return loggerConfig{root: entry.root()}
+}
+
+// levelGate is the level holder of a logger, the logrus logger or the backend.
+type levelGate interface {
+	GetLevel() Level
+	SetLevel(level Level)
+}
+
+func (l golaLoggerEntry) levelGate() levelGate {
+	if l.backend != nil {
+		return l.backend
+	}
+	return l.stdEntry.Logger
+}
+
+// root returns an entry without fields that writes to the same logger.
+func (l golaLoggerEntry) root() *golaLoggerEntry {
+	if l.backend != nil {
+		return newBackendEntry(l.context, l.backend)
+	}
+	return newLoggerEntry(l.context, l.stdEntry.Logger.WithContext(l.context))
+}
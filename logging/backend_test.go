@@ -0,0 +1,153 @@
+package logging
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+	"go.opencensus.io/trace"
+)
+
+type backendEntry struct {
+	level   Level
+	fields  GolaFields
+	message string
+}
+
+type fakeBackend struct {
+	mutex   sync.Mutex
+	level   Level
+	entries []backendEntry
+}
+
+func (b *fakeBackend) Log(ctx context.Context, level Level, fields GolaFields, message string) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	b.entries = append(b.entries, backendEntry{level: level, fields: fields, message: message})
+}
+
+func (b *fakeBackend) GetLevel() Level {
+	return b.level
+}
+
+func (b *fakeBackend) SetLevel(level Level) {
+	b.level = level
+}
+
+type BackendTestSuite struct {
+	suite.Suite
+	backend *fakeBackend
+}
+
+func TestBackendTestSuite(t *testing.T) {
+	suite.Run(t, new(BackendTestSuite))
+}
+
+func (suite *BackendTestSuite) SetupTest() {
+	suite.backend = &fakeBackend{level: InfoLevel}
+}
+
+func (suite *BackendTestSuite) TearDownTest() {
+	registries.Delete(suite.backend)
+	samplers.Delete(suite.backend)
+	SetDefaultBackend(nil)
+}
+
+func (suite *BackendTestSuite) TestShouldWriteEntriesWithFieldsToBackend() {
+	logger := NewLogger(suite.backend).WithField("traceID", "sampleID")
+
+	logger.Infof("loaded %d configs", 2)
+	logger.Warnln("retrying", "request")
+
+	suite.Equal([]backendEntry{
+		{level: InfoLevel, fields: GolaFields{"traceID": "sampleID"}, message: "loaded 2 configs"},
+		{level: WarnLevel, fields: GolaFields{"traceID": "sampleID"}, message: "retrying request"},
+	}, suite.backend.entries)
+}
+
+func (suite *BackendTestSuite) TestShouldSkipEntriesBelowBackendLevel() {
+	logger := NewLogger(suite.backend)
+
+	logger.Debug("query")
+	suite.Nil(logger.SetLevel(DEBUG))
+	logger.Debug("query")
+
+	suite.Equal(1, len(suite.backend.entries))
+	suite.Equal(DebugLevel, suite.backend.GetLevel())
+}
+
+func (suite *BackendTestSuite) TestShouldApplyNamedLevelsAndSamplingToBackend() {
+	logger := NewLogger(suite.backend)
+	suite.Nil(BackendConfig(suite.backend).SetLevelFor("oauth", DEBUG, 0))
+	suite.Nil(BackendConfig(suite.backend).SetSampling(SamplingConfig{Levels: map[string]LevelSampling{INFO: {First: 1}}}))
+
+	logger.Named("oauth").Debug("token validated")
+	logger.Debug("query")
+	for i := 0; i < 2; i++ {
+		logger.Info("cors")
+	}
+
+	suite.Equal(2, len(suite.backend.entries))
+	suite.Equal("oauth", suite.backend.entries[0].fields[LoggerNameField])
+	suite.Equal("cors", suite.backend.entries[1].message)
+}
+
+func (suite *BackendTestSuite) TestShouldAnnotateSpanOfBackendLogger() {
+	t := testExporter{}
+	trace.RegisterExporter(&t)
+	defer trace.UnregisterExporter(&t)
+	ctx, s := trace.StartSpan(context.Background(), "test-span", trace.WithSampler(trace.AlwaysSample()))
+
+	NewLogger(suite.backend).WithContext(ctx).Error("failed")
+	s.End()
+
+	suite.Equal(1, len(suite.backend.entries))
+	suite.Equal("failed", t.SpanData.Annotations[0].Message)
+	suite.Equal("error", t.SpanData.Annotations[0].Attributes["level"])
+}
+
+func (suite *BackendTestSuite) TestShouldUseDefaultBackendForNewLoggerEntry() {
+	SetDefaultBackend(suite.backend)
+	GetLogger(context.Background()).Info("started")
+
+	SetDefaultBackend(nil)
+	_, isLogrus := NewLoggerEntry().(*golaLoggerEntry)
+
+	suite.Equal(1, len(suite.backend.entries))
+	suite.Equal("started", suite.backend.entries[0].message)
+	suite.True(isLogrus)
+	suite.Nil(NewLoggerEntry().(*golaLoggerEntry).backend)
+}
+
+func (suite *BackendTestSuite) TestShouldConfigureDefaultBackend() {
+	SetDefaultBackend(suite.backend)
+
+	suite.Nil(DefaultConfig().SetLevelFor("oauth", DEBUG, 0))
+	GetLogger(context.Background()).Named("oauth").Debug("token validated")
+
+	suite.Equal(1, len(suite.backend.entries))
+	suite.Equal(LogLevels{Root: "info", Loggers: map[string]string{"oauth": "debug"}}, BackendConfig(suite.backend).Levels())
+}
+
+func (suite *BackendTestSuite) TestShouldExitThroughLogrusAfterFatalEntry() {
+	defer func(exit func(int)) { exitProcess = exit }(exitProcess)
+	var exitCodes []int
+	exitProcess = func(code int) { exitCodes = append(exitCodes, code) }
+	logger := NewLogger(suite.backend)
+
+	logger.Fatal("unrecoverable")
+	suite.backend.SetLevel(PanicLevel)
+	logger.Fatal("unrecoverable")
+
+	suite.Equal([]int{1, 1}, exitCodes)
+	suite.Equal(1, len(suite.backend.entries))
+	suite.Equal(FatalLevel, suite.backend.entries[0].level)
+}
+
+func (suite *BackendTestSuite) TestShouldPanicAfterWritingPanicEntry() {
+	logger := NewLogger(suite.backend)
+
+	suite.PanicsWithValue("unrecoverable", func() { logger.Panic("unrecoverable") })
+	suite.Equal(PanicLevel, suite.backend.entries[0].level)
+}
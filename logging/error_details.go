@@ -0,0 +1,210 @@
+package logging
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strings"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	ErrorField       = "error"
+	ErrorCausesField = "error_causes"
+	CallerField      = "caller"
+	FunctionField    = "function"
+	StackTraceField  = "stack_trace"
+
+	DefaultMaxErrorCauses = 10
+	DefaultMaxStackFrames = 32
+	DefaultMaxFieldLength = 2048
+
+	maxCallerDepth = 64
+)
+
+// ErrorReportingConfig controls the details added to entries. The errors.Unwrap chain
+// of a logged error is always added as error_causes. Entries at CallerLevel and above
+// carry the caller location, entries at StackTraceLevel and above a stack trace. An
+// empty level turns the respective detail off. Zero limits fall back to the defaults.
+type ErrorReportingConfig struct {
+	CallerLevel     string `json:"callerLevel"`
+	StackTraceLevel string `json:"stackTraceLevel"`
+	MaxErrorCauses  int    `json:"maxErrorCauses"`
+	MaxStackFrames  int    `json:"maxStackFrames"`
+	MaxFieldLength  int    `json:"maxFieldLength"`
+}
+
+type errorReporter struct {
+	callerLevel     *logrus.Level
+	stackTraceLevel *logrus.Level
+	maxErrorCauses  int
+	maxStackFrames  int
+	maxFieldLength  int
+}
+
+// errorReporters holds the error reporting of each logrus logger or backend, loggers
+// without one use DefaultErrorReportingConfig.
+var errorReporters sync.Map
+
+var defaultErrorReporter, _ = newErrorReporter(DefaultErrorReportingConfig())
+
+var loggingPackagePrefix = reflect.TypeOf(golaLoggerEntry{}).PkgPath() + "."
+
+func DefaultErrorReportingConfig() ErrorReportingConfig {
+	return ErrorReportingConfig{CallerLevel: ERROR}
+}
+
+func newErrorReporter(config ErrorReportingConfig) (*errorReporter, error) {
+	reporter := &errorReporter{
+		maxErrorCauses: config.MaxErrorCauses,
+		maxStackFrames: config.MaxStackFrames,
+		maxFieldLength: config.MaxFieldLength,
+	}
+	for _, option := range []struct {
+		name  string
+		level **logrus.Level
+	}{{config.CallerLevel, &reporter.callerLevel}, {config.StackTraceLevel, &reporter.stackTraceLevel}} {
+		if option.name == "" {
+			continue
+		}
+		level, err := parseLevel(option.name)
+		if err != nil {
+			return nil, err
+		}
+		*option.level = &level
+	}
+	if reporter.maxErrorCauses < 0 || reporter.maxStackFrames < 0 || reporter.maxFieldLength < 0 {
+		return nil, errors.New("error reporting limits must not be negative")
+	}
+	if reporter.maxErrorCauses == 0 {
+		reporter.maxErrorCauses = DefaultMaxErrorCauses
+	}
+	if reporter.maxStackFrames == 0 {
+		reporter.maxStackFrames = DefaultMaxStackFrames
+	}
+	if reporter.maxFieldLength == 0 {
+		reporter.maxFieldLength = DefaultMaxFieldLength
+	}
+	return reporter, nil
+}
+
+// SetErrorReporting applies the config to every entry of the underlying logrus logger
+// or backend.
+func (c loggerConfig) SetErrorReporting(config ErrorReportingConfig) error {
+	reporter, err := newErrorReporter(config)
+	if err != nil {
+		return err
+	}
+	errorReporters.Store(c.root.levelGate(), reporter)
+	return nil
+}
+
+func (l golaLoggerEntry) errorReporter() *errorReporter {
+	if reporter, ok := errorReporters.Load(l.levelGate()); ok {
+		return reporter.(*errorReporter)
+	}
+	return defaultErrorReporter
+}
+
+// withErrorDetails returns the entry with the details of the error it carries. Errors
+// passed as values, as in logger.Error("failed to save", err), are added as the error
+// field unless the entry already has one.
+func (l golaLoggerEntry) withErrorDetails(level logrus.Level, value ...interface{}) *golaLoggerEntry {
+	reporter := l.errorReporter()
+	details := GolaFields{}
+
+	err, hasError := l.Data[ErrorField].(error)
+	if _, hasErrorField := l.Data[ErrorField]; !hasErrorField {
+		for _, v := range value {
+			if err, hasError = v.(error); hasError {
+				details[ErrorField] = err
+				break
+			}
+		}
+	}
+	if hasError && err != nil {
+		if causes := reporter.causes(err); len(causes) > 0 {
+			details[ErrorCausesField] = causes
+		}
+	}
+
+	if reporter.isEnabled(reporter.callerLevel, level) {
+		if frame, ok := callerFrame(); ok {
+			details[CallerField] = fmt.Sprintf("%s:%d", shortFile(frame.File), frame.Line)
+			details[FunctionField] = frame.Function
+		}
+	}
+	if reporter.isEnabled(reporter.stackTraceLevel, level) {
+		details[StackTraceField] = reporter.stackTrace()
+	}
+
+	if len(details) == 0 {
+		return &l
+	}
+	return l.withFields(details)
+}
+
+func (r *errorReporter) isEnabled(threshold *logrus.Level, level logrus.Level) bool {
+	return threshold != nil && level <= *threshold
+}
+
+func (r *errorReporter) causes(err error) []string {
+	var causes []string
+	for cause := errors.Unwrap(err); cause != nil && len(causes) < r.maxErrorCauses; cause = errors.Unwrap(cause) {
+		causes = append(causes, r.truncate(cause.Error()))
+	}
+	return causes
+}
+
+func (r *errorReporter) stackTrace() string {
+	var builder strings.Builder
+	for _, frame := range callerStack(r.maxStackFrames) {
+		builder.WriteString(fmt.Sprintf("%s\n\t%s:%d\n", frame.Function, frame.File, frame.Line))
+	}
+	return r.truncate(strings.TrimSuffix(builder.String(), "\n"))
+}
+
+func (r *errorReporter) truncate(value string) string {
+	if len(value) <= r.maxFieldLength {
+		return value
+	}
+	return value[:r.maxFieldLength] + "..."
+}
+
+func callerFrame() (runtime.Frame, bool) {
+	stack := callerStack(1)
+	if len(stack) == 0 {
+		return runtime.Frame{}, false
+	}
+	return stack[0], true
+}
+
+// callerStack returns up to maxFrames frames, starting at the first frame outside of
+// this package. Tests of this package count as callers.
+func callerStack(maxFrames int) []runtime.Frame {
+	pcs := make([]uintptr, maxCallerDepth+maxFrames)
+	frames := runtime.CallersFrames(pcs[:runtime.Callers(3, pcs)])
+	var stack []runtime.Frame
+	for len(stack) < maxFrames {
+		frame, more := frames.Next()
+		if len(stack) > 0 || !isLoggingFrame(frame) {
+			stack = append(stack, frame)
+		}
+		if !more {
+			break
+		}
+	}
+	return stack
+}
+
+func isLoggingFrame(frame runtime.Frame) bool {
+	return strings.HasPrefix(frame.Function, loggingPackagePrefix) && !strings.HasSuffix(frame.File, "_test.go")
+}
+
+func shortFile(file string) string {
+	return filepath.Join(filepath.Base(filepath.Dir(file)), filepath.Base(file))
+}
@@ -0,0 +1,110 @@
+package logging
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	logrusTest "github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/suite"
+	"go.opencensus.io/trace"
+)
+
+type ErrorDetailsTestSuite struct {
+	suite.Suite
+	logger *logrus.Logger
+	hook   *logrusTest.Hook
+	entry  *golaLoggerEntry
+}
+
+func TestErrorDetailsTestSuite(t *testing.T) {
+	suite.Run(t, new(ErrorDetailsTestSuite))
+}
+
+func (suite *ErrorDetailsTestSuite) SetupTest() {
+	suite.logger, suite.hook = logrusTest.NewNullLogger()
+	suite.logger.SetLevel(logrus.TraceLevel)
+	suite.entry = newLoggerEntry(context.Background(), suite.logger.WithContext(context.Background()))
+}
+
+func (suite *ErrorDetailsTestSuite) TearDownTest() {
+	errorReporters.Delete(suite.logger)
+}
+
+func (suite *ErrorDetailsTestSuite) TestShouldAddCauseChainOfWithError() {
+	rootCause := errors.New("connection refused")
+	err := fmt.Errorf("unable to save draft: %w", fmt.Errorf("db insert failed: %w", rootCause))
+
+	suite.entry.WithError(err).Warn("retrying")
+
+	data := suite.hook.LastEntry().Data
+	suite.Equal(err, data[ErrorField])
+	suite.Equal([]string{"db insert failed: connection refused", "connection refused"}, data[ErrorCausesField])
+}
+
+func (suite *ErrorDetailsTestSuite) TestShouldAddErrorPassedAsValue() {
+	err := fmt.Errorf("unable to save draft: %w", errors.New("connection refused"))
+
+	suite.entry.Error("failed to save draft ", err)
+
+	data := suite.hook.LastEntry().Data
+	suite.Equal("failed to save draft unable to save draft: connection refused", suite.hook.LastEntry().Message)
+	suite.Equal(err, data[ErrorField])
+	suite.Equal([]string{"connection refused"}, data[ErrorCausesField])
+}
+
+func (suite *ErrorDetailsTestSuite) TestShouldAddCallerAtErrorLevelByDefault() {
+	suite.entry.Info("started")
+	suite.NotContains(suite.hook.LastEntry().Data, CallerField)
+
+	suite.entry.Errorf("failed")
+
+	data := suite.hook.LastEntry().Data
+	suite.True(strings.HasPrefix(data[CallerField].(string), "logging/error_details_test.go:"))
+	suite.Equal("github.com/inclusi-blog/gola-utils/logging.(*ErrorDetailsTestSuite).TestShouldAddCallerAtErrorLevelByDefault", data[FunctionField])
+}
+
+func (suite *ErrorDetailsTestSuite) TestShouldAddStackTraceWhenConfigured() {
+	suite.Nil(configOf(suite.entry).SetErrorReporting(ErrorReportingConfig{StackTraceLevel: ERROR, MaxStackFrames: 2}))
+
+	suite.entry.Warn("retrying")
+	suite.NotContains(suite.hook.LastEntry().Data, StackTraceField)
+	suite.NotContains(suite.hook.LastEntry().Data, CallerField)
+
+	suite.entry.Error("failed")
+	stackTrace := suite.hook.LastEntry().Data[StackTraceField].(string)
+	suite.True(strings.HasPrefix(stackTrace, "github.com/inclusi-blog/gola-utils/logging.(*ErrorDetailsTestSuite).TestShouldAddStackTraceWhenConfigured\n\t"))
+	suite.Equal(4, len(strings.Split(stackTrace, "\n")))
+}
+
+func (suite *ErrorDetailsTestSuite) TestShouldCapCausesAndFieldLength() {
+	suite.Nil(configOf(suite.entry).SetErrorReporting(ErrorReportingConfig{MaxErrorCauses: 1, MaxFieldLength: 10}))
+	err := fmt.Errorf("outer: %w", fmt.Errorf("middle cause: %w", errors.New("root")))
+
+	suite.entry.WithError(err).Error("failed")
+
+	suite.Equal([]string{"middle cau..."}, suite.hook.LastEntry().Data[ErrorCausesField])
+}
+
+func (suite *ErrorDetailsTestSuite) TestShouldAddErrorDetailsToSpanAttributes() {
+	t := testExporter{}
+	trace.RegisterExporter(&t)
+	defer trace.UnregisterExporter(&t)
+	ctx, s := trace.StartSpan(context.Background(), "test-span", trace.WithSampler(trace.AlwaysSample()))
+
+	suite.entry.withContext(ctx).Error("failed ", fmt.Errorf("outer: %w", errors.New("root")))
+	s.End()
+
+	attributes := t.SpanData.Annotations[0].Attributes
+	suite.Equal("outer: root", attributes[ErrorField])
+	suite.Equal(`["root"]`, attributes[ErrorCausesField])
+	suite.Contains(attributes, CallerField)
+}
+
+func (suite *ErrorDetailsTestSuite) TestShouldRejectInvalidErrorReportingConfig() {
+	suite.EqualError(configOf(suite.entry).SetErrorReporting(ErrorReportingConfig{CallerLevel: "verbose"}), `not a valid golaLogger Level: "verbose"`)
+	suite.EqualError(configOf(suite.entry).SetErrorReporting(ErrorReportingConfig{MaxStackFrames: -1}), "error reporting limits must not be negative")
+}
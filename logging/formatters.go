@@ -0,0 +1,158 @@
+package logging
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/inclusi-blog/gola-utils/constants"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	defaultConsoleTimestampFormat = "15:04:05.000"
+	defaultConsoleMessageWidth    = 44
+	shortTraceIDLength            = 8
+
+	colorRed    = 31
+	colorYellow = 33
+	colorBlue   = 36
+	colorGray   = 37
+)
+
+// ConfigureFormatter selects the formatter once at startup. An empty format is read
+// from the LOG_FORMAT environment variable, when that is empty as well the formatter
+// is left as it is. Formats are json, logfmt and console.
+func ConfigureFormatter(entry Logger, format string) error {
+	if format == "" {
+		format = os.Getenv(constants.LOG_FORMAT_ENV)
+	}
+	if format == "" {
+		return nil
+	}
+	format = strings.ToLower(strings.TrimSpace(format))
+	if _, err := newFormatter(format); err != nil {
+		return err
+	}
+	entry.SetFormatter(format)
+	return nil
+}
+
+// newFormatter returns the formatter of format wrapped in a LogTagFormatter, so that
+// log tagged fields are masked and redacted whichever format is chosen.
+func newFormatter(format string) (logrus.Formatter, error) {
+	switch format {
+	case constants.JSON:
+		return NewLogTagFormatter(&logrus.JSONFormatter{}), nil
+	case constants.LOGFMT:
+		return NewLogTagFormatter(&logrus.TextFormatter{DisableColors: true, FullTimestamp: true, TimestampFormat: time.RFC3339Nano, QuoteEmptyFields: true}), nil
+	case constants.CONSOLE:
+		return NewLogTagFormatter(&ConsoleFormatter{}), nil
+	}
+	return nil, fmt.Errorf("not a valid golaLogger format: %q", format)
+}
+
+// ConsoleFormatter writes one colorized line per entry for local development: time,
+// level, the message padded to MessageWidth and the sorted fields, starting with the
+// last characters of the trace ID, which 64-bit IDs padded with zeros still differ in.
+// Stack traces follow on their own lines.
+type ConsoleFormatter struct {
+	DisableColors   bool
+	TimestampFormat string
+	MessageWidth    int
+}
+
+func (f *ConsoleFormatter) Format(entry *logrus.Entry) ([]byte, error) {
+	timestampFormat := f.TimestampFormat
+	if timestampFormat == "" {
+		timestampFormat = defaultConsoleTimestampFormat
+	}
+	messageWidth := f.MessageWidth
+	if messageWidth == 0 {
+		messageWidth = defaultConsoleMessageWidth
+	}
+
+	buffer := entry.Buffer
+	if buffer == nil {
+		buffer = &bytes.Buffer{}
+	}
+	buffer.WriteString(entry.Time.Format(timestampFormat))
+	buffer.WriteByte(' ')
+	buffer.WriteString(f.colorize(levelColor(entry.Level), fmt.Sprintf("%-5s", consoleLevel(entry.Level))))
+	buffer.WriteByte(' ')
+	buffer.WriteString(fmt.Sprintf("%-*s", messageWidth, entry.Message))
+
+	if traceID, ok := entry.Data[constants.TRACE_KEY].(string); ok && traceID != constants.NO_TRACE_ID {
+		f.writeField(buffer, "trace", shortTraceID(traceID))
+	}
+	keys := make([]string, 0, len(entry.Data))
+	for key := range entry.Data {
+		if key != constants.TRACE_KEY && key != StackTraceField {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		f.writeField(buffer, key, formatConsoleValue(entry.Data[key]))
+	}
+	buffer.WriteByte('\n')
+
+	if stackTrace, ok := entry.Data[StackTraceField].(string); ok {
+		for _, line := range strings.Split(stackTrace, "\n") {
+			buffer.WriteString("    ")
+			buffer.WriteString(line)
+			buffer.WriteByte('\n')
+		}
+	}
+	return buffer.Bytes(), nil
+}
+
+func (f *ConsoleFormatter) writeField(buffer *bytes.Buffer, key string, value string) {
+	buffer.WriteByte(' ')
+	buffer.WriteString(f.colorize(colorGray, key+"="))
+	buffer.WriteString(value)
+}
+
+func (f *ConsoleFormatter) colorize(color int, text string) string {
+	if f.DisableColors {
+		return text
+	}
+	return fmt.Sprintf("\x1b[%dm%s\x1b[0m", color, text)
+}
+
+func consoleLevel(level logrus.Level) string {
+	if level == logrus.WarnLevel {
+		return "WARN"
+	}
+	return strings.ToUpper(level.String())
+}
+
+func levelColor(level logrus.Level) int {
+	switch {
+	case level <= logrus.ErrorLevel:
+		return colorRed
+	case level == logrus.WarnLevel:
+		return colorYellow
+	case level == logrus.InfoLevel:
+		return colorBlue
+	}
+	return colorGray
+}
+
+func shortTraceID(traceID string) string {
+	if len(traceID) <= shortTraceIDLength {
+		return traceID
+	}
+	return traceID[len(traceID)-shortTraceIDLength:]
+}
+
+func formatConsoleValue(v interface{}) string {
+	value := formatSpanValue(v)
+	if value == "" || strings.ContainsAny(value, " =\"\t\n") {
+		return fmt.Sprintf("%q", value)
+	}
+	return value
+}
@@ -0,0 +1,144 @@
+package logging
+
+import (
+	"context"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/inclusi-blog/gola-utils/constants"
+	"github.com/sirupsen/logrus"
+	logrusTest "github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/suite"
+)
+
+type FormattersTestSuite struct {
+	suite.Suite
+	logger *logrus.Logger
+	entry  *golaLoggerEntry
+}
+
+func TestFormattersTestSuite(t *testing.T) {
+	suite.Run(t, new(FormattersTestSuite))
+}
+
+func (suite *FormattersTestSuite) SetupTest() {
+	suite.logger, _ = logrusTest.NewNullLogger()
+	suite.entry = newLoggerEntry(context.Background(), suite.logger.WithContext(context.Background()))
+}
+
+func (suite *FormattersTestSuite) TearDownTest() {
+	_ = os.Unsetenv(constants.LOG_FORMAT_ENV)
+}
+
+func (suite *FormattersTestSuite) logrusEntry(level logrus.Level, message string, fields logrus.Fields) *logrus.Entry {
+	entry := suite.logger.WithFields(fields)
+	entry.Time = time.Date(2020, 10, 10, 15, 4, 5, 123000000, time.UTC)
+	entry.Level = level
+	entry.Message = message
+	return entry
+}
+
+func (suite *FormattersTestSuite) TestShouldConfigureFormatterFromArgument() {
+	suite.Nil(ConfigureFormatter(suite.entry, "Console"))
+
+	suite.Equal(NewLogTagFormatter(&ConsoleFormatter{}), suite.logger.Formatter)
+}
+
+func (suite *FormattersTestSuite) TestShouldConfigureFormatterFromEnv() {
+	_ = os.Setenv(constants.LOG_FORMAT_ENV, "logfmt")
+
+	suite.Nil(ConfigureFormatter(suite.entry, ""))
+
+	_, isText := suite.logger.Formatter.(*LogTagFormatter).defaultFormatter.(*logrus.TextFormatter)
+	suite.True(isText)
+}
+
+func (suite *FormattersTestSuite) TestShouldKeepFormatterWithoutConfig() {
+	formatter := suite.logger.Formatter
+
+	suite.Nil(ConfigureFormatter(suite.entry, ""))
+
+	suite.Equal(formatter, suite.logger.Formatter)
+}
+
+func (suite *FormattersTestSuite) TestShouldRejectUnknownFormat() {
+	formatter := suite.logger.Formatter
+
+	suite.EqualError(ConfigureFormatter(suite.entry, "xml"), `not a valid golaLogger format: "xml"`)
+	suite.Equal(formatter, suite.logger.Formatter)
+}
+
+func (suite *FormattersTestSuite) TestShouldFormatLogfmt() {
+	formatter, err := newFormatter(constants.LOGFMT)
+	suite.Nil(err)
+
+	output, err := formatter.Format(suite.logrusEntry(logrus.InfoLevel, "loaded configs", logrus.Fields{"traceID": "sampleID", "count": 2}))
+
+	suite.Nil(err)
+	suite.Equal(`time="2020-10-10T15:04:05.123Z" level=info msg="loaded configs" count=2 traceID=sampleID`+"\n", string(output))
+}
+
+func (suite *FormattersTestSuite) TestShouldFormatConsoleLineWithShortTraceID() {
+	formatter := &ConsoleFormatter{DisableColors: true, MessageWidth: 16}
+
+	output, err := formatter.Format(suite.logrusEntry(logrus.WarnLevel, "retrying", logrus.Fields{
+		constants.TRACE_KEY: "463ac35c9f6413ad48485a3953bb6124",
+		"path":              "/api/draft",
+		"error":             errors.New("connection refused"),
+	}))
+
+	suite.Nil(err)
+	suite.Equal(`15:04:05.123 WARN  retrying         trace=53bb6124 error="connection refused" path=/api/draft`+"\n", string(output))
+}
+
+func (suite *FormattersTestSuite) TestShouldShortenZeroPadded64BitTraceIDToItsLastCharacters() {
+	formatter := &ConsoleFormatter{DisableColors: true, MessageWidth: 1}
+
+	output, err := formatter.Format(suite.logrusEntry(logrus.InfoLevel, "loaded", logrus.Fields{
+		constants.TRACE_KEY: "0000000000000000a3ce929d0e0e4736",
+	}))
+
+	suite.Nil(err)
+	suite.Equal("15:04:05.123 INFO  loaded trace=0e0e4736\n", string(output))
+}
+
+func (suite *FormattersTestSuite) TestShouldOmitMissingTraceIDAndIndentStackTrace() {
+	formatter := &ConsoleFormatter{DisableColors: true, MessageWidth: 1}
+
+	output, err := formatter.Format(suite.logrusEntry(logrus.ErrorLevel, "failed", logrus.Fields{
+		constants.TRACE_KEY: constants.NO_TRACE_ID,
+		StackTraceField:     "main.main\n\tmain.go:10",
+	}))
+
+	suite.Nil(err)
+	suite.Equal("15:04:05.123 ERROR failed\n    main.main\n    \tmain.go:10\n", string(output))
+}
+
+func (suite *FormattersTestSuite) TestShouldColorizeConsoleLevel() {
+	formatter := &ConsoleFormatter{}
+
+	output, err := formatter.Format(suite.logrusEntry(logrus.ErrorLevel, "failed", logrus.Fields{}))
+
+	suite.Nil(err)
+	suite.True(strings.Contains(string(output), "\x1b[31mERROR\x1b[0m"))
+}
+
+func (suite *FormattersTestSuite) TestShouldMaskTaggedFieldsInEveryFormat() {
+	type user struct {
+		Email string `log:"email,mask=email"`
+	}
+	fields := logrus.Fields{"user": user{Email: "abc@gmail.com"}}
+
+	for _, format := range []string{constants.JSON, constants.LOGFMT, constants.CONSOLE} {
+		suite.Nil(ConfigureFormatter(suite.entry, format))
+
+		output, err := suite.logger.Formatter.Format(suite.logrusEntry(logrus.InfoLevel, "signed in", fields))
+
+		suite.Nil(err)
+		suite.Contains(string(output), "a*c@g***l.com", format)
+		suite.NotContains(string(output), "abc@gmail.com", format)
+	}
+}
@@ -1,11 +1,15 @@
 package logging
 
+//go:generate mockgen -source=gola_logger.go -destination=./mocks/mock_logger.go -package=mocks
+
 import (
 	"context"
 	"fmt"
-	"github.com/inclusi-blog/gola-utils/constants"
+	"os"
 	"reflect"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/sirupsen/logrus"
@@ -30,16 +34,25 @@ const (
 	TRACE   = "trace"
 )
 
-type GolaLoggerEntry interface {
-	WithError(err error) *golaLoggerEntry
-	WithField(key string, value interface{}) *golaLoggerEntry
-	WithFields(fields GolaFields) *golaLoggerEntry
-	WithContext(ctx context.Context) *golaLoggerEntry
+// Logger is the logger returned by GetLogger. Every entry is written to the backend
+// (logrus by default) and annotated on the span of the logger context.
+type Logger interface {
+	WithError(err error) Logger
+	WithField(key string, value interface{}) Logger
+	WithFields(fields GolaFields) Logger
+	WithContext(ctx context.Context) Logger
+	Named(name string) Logger
+	Fields() GolaFields
+
+	AddHook(hk GolaLoggerHook)
 	SetFormatter(format string)
 	SetLevel(lvl string) error
 
 	Tracef(format string, value ...interface{})
+	Debugf(format string, value ...interface{})
+	Infof(format string, value ...interface{})
 	Printf(format string, value ...interface{})
+	Warnf(format string, value ...interface{})
 	Warningf(format string, value ...interface{})
 	Errorf(format string, value ...interface{})
 	Fatalf(format string, value ...interface{})
@@ -51,6 +64,7 @@ type GolaLoggerEntry interface {
 	Print(value ...interface{})
 	Warn(value ...interface{})
 	Warning(value ...interface{})
+	Error(value ...interface{})
 	Fatal(value ...interface{})
 	Panic(value ...interface{})
 
@@ -65,6 +79,20 @@ type GolaLoggerEntry interface {
 	Panicln(value ...interface{})
 }
 
+// Config holds the settings of a logrus logger or backend, they apply to every Logger
+// writing to it. Get it with DefaultConfig or BackendConfig.
+type Config interface {
+	SetLevelFor(name string, lvl string, ttl time.Duration) error
+	Levels() LogLevels
+	SetSampling(config SamplingConfig) error
+	SetErrorReporting(config ErrorReportingConfig) error
+	SetSpanLogging(config SpanLoggingConfig) error
+	SetAsync(config AsyncConfig) (*AsyncWriter, error)
+}
+
+// Deprecated: use Logger.
+type GolaLoggerEntry = Logger
+
 type GolaFields map[string]interface{}
 
 type GolaLoggerHook logrus.Hook
@@ -74,35 +102,59 @@ type golaLoggerEntry struct {
 	stdEntry *logrus.Entry
 	Data     GolaFields
 	err      string
+	name     string
+	debug    bool
+	backend  Backend
 }
 
 func newLoggerEntry(ctx context.Context, stdEntry *logrus.Entry) *golaLoggerEntry {
 	return &golaLoggerEntry{context: ctx, stdEntry: stdEntry, Data: make(GolaFields, 6), err: ""}
 }
 
-func NewLoggerEntry() *golaLoggerEntry {
+// NewLoggerEntry returns a logger without fields, written to the default backend.
+func NewLoggerEntry() Logger {
 	ctx := context.TODO()
+	if backend := defaultBackend(); backend != nil {
+		return newBackendEntry(ctx, backend)
+	}
 	return newLoggerEntry(ctx, logrus.StandardLogger().WithContext(ctx))
 }
 
-func (l golaLoggerEntry) WithError(err error) *golaLoggerEntry {
-	return l.WithField("error", err)
+func (l golaLoggerEntry) WithError(err error) Logger {
+	return l.WithField(ErrorField, err)
+}
+
+func (l golaLoggerEntry) WithField(key string, value interface{}) Logger {
+	return l.withFields(GolaFields{key: value})
 }
 
-func (l golaLoggerEntry) WithField(key string, value interface{}) *golaLoggerEntry {
-	return l.WithFields(GolaFields{key: value})
+func (l golaLoggerEntry) Fields() GolaFields {
+	return l.Data
 }
 
 func (l golaLoggerEntry) AddHook(hk GolaLoggerHook) {
+	if l.backend != nil {
+		if hookBackend, ok := l.backend.(interface{ AddHook(GolaLoggerHook) }); ok {
+			hookBackend.AddHook(hk)
+		}
+		return
+	}
 	l.stdEntry.Logger.AddHook(hk)
 }
 
-func (l golaLoggerEntry) WithFields(fields GolaFields) *golaLoggerEntry {
-	logrusFields := make(logrus.Fields, len(fields))
-	for k, v := range fields {
-		logrusFields[k] = v
+func (l golaLoggerEntry) WithFields(fields GolaFields) Logger {
+	return l.withFields(fields)
+}
+
+func (l golaLoggerEntry) withFields(fields GolaFields) *golaLoggerEntry {
+	var newEntry *logrus.Entry
+	if l.stdEntry != nil {
+		logrusFields := make(logrus.Fields, len(fields))
+		for k, v := range fields {
+			logrusFields[k] = v
+		}
+		newEntry = l.stdEntry.WithFields(logrusFields)
 	}
-	newEntry := l.stdEntry.WithFields(logrusFields)
 	//span data
 	data := make(GolaFields, len(l.Data)+len(fields))
 	for k, v := range l.Data {
@@ -130,70 +182,91 @@ func (l golaLoggerEntry) WithFields(fields GolaFields) *golaLoggerEntry {
 			data[k] = v
 		}
 	}
-	return &golaLoggerEntry{context: l.context, stdEntry: newEntry, Data: data, err: fieldErr}
+	return &golaLoggerEntry{context: l.context, stdEntry: newEntry, Data: data, err: fieldErr, name: l.name, debug: l.debug, backend: l.backend}
+}
+
+func (l golaLoggerEntry) WithContext(ctx context.Context) Logger {
+	return l.withContext(ctx)
 }
 
-func (l golaLoggerEntry) WithContext(ctx context.Context) *golaLoggerEntry {
+func (l golaLoggerEntry) withContext(ctx context.Context) *golaLoggerEntry {
+	var newEntry *logrus.Entry
+	if l.stdEntry != nil {
+		newEntry = l.stdEntry.WithContext(ctx)
+	}
 	return &golaLoggerEntry{
 		context:  ctx,
-		stdEntry: l.stdEntry.WithContext(ctx),
+		stdEntry: newEntry,
 		Data:     l.Data,
 		err:      l.err,
+		name:     l.name,
+		debug:    l.debug,
+		backend:  l.backend,
 	}
 }
 
 //Happens only for logrus logs and not span logs
 func (l golaLoggerEntry) SetFormatter(format string) {
-	var formatter logrus.Formatter
-	switch format {
-	case constants.JSON:
-		formatter = &logrus.JSONFormatter{}
+	if l.backend != nil {
+		if formatterBackend, ok := l.backend.(interface{ SetFormatter(string) }); ok {
+			formatterBackend.SetFormatter(format)
+		}
+		return
+	}
+	if formatter, err := newFormatter(format); err == nil {
 		l.stdEntry.Logger.SetFormatter(formatter)
 	}
 }
 
+// SetLevel sets the level of this logger, which is the root level unless the logger
+// was created with Named.
 func (l golaLoggerEntry) SetLevel(lvl string) error {
-	setLevel := l.stdEntry.Logger.SetLevel
+	if lvl == "" {
+		return fmt.Errorf("not a valid golaLogger Level: %q", lvl)
+	}
+	return configOf(&l).SetLevelFor(l.name, lvl, 0)
+}
+
+func parseLevel(lvl string) (logrus.Level, error) {
 	lvlString := strings.ToLower(lvl)
 	switch lvlString {
 	case PANIC:
-		setLevel(logrus.PanicLevel)
-		return nil
+		return logrus.PanicLevel, nil
 	case FATAL:
-		setLevel(logrus.FatalLevel)
-		return nil
+		return logrus.FatalLevel, nil
 	case ERROR:
-		setLevel(logrus.ErrorLevel)
-		return nil
+		return logrus.ErrorLevel, nil
 	case WARN, WARNING:
-		setLevel(logrus.WarnLevel)
-		return nil
+		return logrus.WarnLevel, nil
 	case INFO, PRINT:
-		setLevel(logrus.InfoLevel)
-		return nil
+		return logrus.InfoLevel, nil
 	case DEBUG:
-		setLevel(logrus.DebugLevel)
-		return nil
+		return logrus.DebugLevel, nil
 	case TRACE:
-		setLevel(logrus.TraceLevel)
-		return nil
+		return logrus.TraceLevel, nil
 	}
-	return fmt.Errorf("not a valid golaLogger Level: %q", lvl)
+	return logrus.PanicLevel, fmt.Errorf("not a valid golaLogger Level: %q", lvl)
 }
 
 func (l golaLoggerEntry) Tracef(format string, value ...interface{}) {
-	logToSpan(l, logrus.TraceLevel, SPRINT_F, format, value...)
-	l.stdEntry.Tracef(format, value...)
+	if l.isSkipped(logrus.TraceLevel, SPRINT_F, format, value...) {
+		return
+	}
+	l.log(logrus.TraceLevel, SPRINT_F, format, value...)
 }
 
 func (l golaLoggerEntry) Debugf(format string, value ...interface{}) {
-	logToSpan(l, logrus.DebugLevel, SPRINT_F, format, value...)
-	l.stdEntry.Debugf(format, value...)
+	if l.isSkipped(logrus.DebugLevel, SPRINT_F, format, value...) {
+		return
+	}
+	l.log(logrus.DebugLevel, SPRINT_F, format, value...)
 }
 
 func (l golaLoggerEntry) Infof(format string, value ...interface{}) {
-	logToSpan(l, logrus.InfoLevel, SPRINT_F, format, value...)
-	l.stdEntry.Infof(format, value...)
+	if l.isSkipped(logrus.InfoLevel, SPRINT_F, format, value...) {
+		return
+	}
+	l.log(logrus.InfoLevel, SPRINT_F, format, value...)
 }
 
 func (l golaLoggerEntry) Printf(format string, value ...interface{}) {
@@ -201,8 +274,10 @@ func (l golaLoggerEntry) Printf(format string, value ...interface{}) {
 }
 
 func (l golaLoggerEntry) Warnf(format string, value ...interface{}) {
-	logToSpan(l, logrus.WarnLevel, SPRINT_F, format, value...)
-	l.stdEntry.Warnf(format, value...)
+	if l.isSkipped(logrus.WarnLevel, SPRINT_F, format, value...) {
+		return
+	}
+	l.log(logrus.WarnLevel, SPRINT_F, format, value...)
 }
 
 func (l golaLoggerEntry) Warningf(format string, value ...interface{}) {
@@ -210,34 +285,40 @@ func (l golaLoggerEntry) Warningf(format string, value ...interface{}) {
 }
 
 func (l golaLoggerEntry) Errorf(format string, value ...interface{}) {
-	logToSpan(l, logrus.ErrorLevel, SPRINT_F, format, value...)
-	l.stdEntry.Errorf(format, value...)
+	if l.isSkipped(logrus.ErrorLevel, SPRINT_F, format, value...) {
+		return
+	}
+	l.log(logrus.ErrorLevel, SPRINT_F, format, value...)
 }
 
 func (l golaLoggerEntry) Fatalf(format string, value ...interface{}) {
 	//Always logToSpan first and then stdEntry for fatal
-	logToSpan(l, logrus.FatalLevel, SPRINT_F, format, value...)
-	l.stdEntry.Fatalf(format, value...)
+	l.log(logrus.FatalLevel, SPRINT_F, format, value...)
 }
 
 func (l golaLoggerEntry) Panicf(format string, value ...interface{}) {
-	logToSpan(l, logrus.PanicLevel, SPRINT_F, format, value...)
-	l.stdEntry.Panicf(format, value...)
+	l.log(logrus.PanicLevel, SPRINT_F, format, value...)
 }
 
 func (l golaLoggerEntry) Trace(value ...interface{}) {
-	logToSpan(l, logrus.TraceLevel, SPRINT, "", value...)
-	l.stdEntry.Trace(value...)
+	if l.isSkipped(logrus.TraceLevel, SPRINT, "", value...) {
+		return
+	}
+	l.log(logrus.TraceLevel, SPRINT, "", value...)
 }
 
 func (l golaLoggerEntry) Debug(value ...interface{}) {
-	logToSpan(l, logrus.DebugLevel, SPRINT, "", value...)
-	l.stdEntry.Debug(value...)
+	if l.isSkipped(logrus.DebugLevel, SPRINT, "", value...) {
+		return
+	}
+	l.log(logrus.DebugLevel, SPRINT, "", value...)
 }
 
 func (l golaLoggerEntry) Info(value ...interface{}) {
-	logToSpan(l, logrus.InfoLevel, SPRINT, "", value...)
-	l.stdEntry.Info(value...)
+	if l.isSkipped(logrus.InfoLevel, SPRINT, "", value...) {
+		return
+	}
+	l.log(logrus.InfoLevel, SPRINT, "", value...)
 }
 
 func (l golaLoggerEntry) Print(value ...interface{}) {
@@ -245,8 +326,10 @@ func (l golaLoggerEntry) Print(value ...interface{}) {
 }
 
 func (l golaLoggerEntry) Warn(value ...interface{}) {
-	logToSpan(l, logrus.WarnLevel, SPRINT, "", value...)
-	l.stdEntry.Warn(value...)
+	if l.isSkipped(logrus.WarnLevel, SPRINT, "", value...) {
+		return
+	}
+	l.log(logrus.WarnLevel, SPRINT, "", value...)
 }
 
 func (l golaLoggerEntry) Warning(value ...interface{}) {
@@ -254,34 +337,40 @@ func (l golaLoggerEntry) Warning(value ...interface{}) {
 }
 
 func (l golaLoggerEntry) Error(value ...interface{}) {
-	logToSpan(l, logrus.ErrorLevel, SPRINT, "", value...)
-	l.stdEntry.Error(value...)
+	if l.isSkipped(logrus.ErrorLevel, SPRINT, "", value...) {
+		return
+	}
+	l.log(logrus.ErrorLevel, SPRINT, "", value...)
 }
 
 func (l golaLoggerEntry) Fatal(value ...interface{}) {
 	//Always logToSpan first and then stdEntry for fatal
-	logToSpan(l, logrus.FatalLevel, SPRINT, "", value...)
-	l.stdEntry.Fatal(value...)
+	l.log(logrus.FatalLevel, SPRINT, "", value...)
 }
 
 func (l golaLoggerEntry) Panic(value ...interface{}) {
-	logToSpan(l, logrus.PanicLevel, SPRINT, "", value...)
-	l.stdEntry.Panic(value...)
+	l.log(logrus.PanicLevel, SPRINT, "", value...)
 }
 
 func (l golaLoggerEntry) Traceln(value ...interface{}) {
-	logToSpan(l, logrus.TraceLevel, SPRINT_LN, "", value...)
-	l.stdEntry.Traceln(value...)
+	if l.isSkipped(logrus.TraceLevel, SPRINT_LN, "", value...) {
+		return
+	}
+	l.log(logrus.TraceLevel, SPRINT_LN, "", value...)
 }
 
 func (l golaLoggerEntry) Debugln(value ...interface{}) {
-	logToSpan(l, logrus.DebugLevel, SPRINT_LN, "", value...)
-	l.stdEntry.Debugln(value...)
+	if l.isSkipped(logrus.DebugLevel, SPRINT_LN, "", value...) {
+		return
+	}
+	l.log(logrus.DebugLevel, SPRINT_LN, "", value...)
 }
 
 func (l golaLoggerEntry) Infoln(value ...interface{}) {
-	logToSpan(l, logrus.InfoLevel, SPRINT_LN, "", value...)
-	l.stdEntry.Infoln(value...)
+	if l.isSkipped(logrus.InfoLevel, SPRINT_LN, "", value...) {
+		return
+	}
+	l.log(logrus.InfoLevel, SPRINT_LN, "", value...)
 }
 
 func (l golaLoggerEntry) Println(value ...interface{}) {
@@ -289,8 +378,10 @@ func (l golaLoggerEntry) Println(value ...interface{}) {
 }
 
 func (l golaLoggerEntry) Warnln(value ...interface{}) {
-	logToSpan(l, logrus.WarnLevel, SPRINT_LN, "", value...)
-	l.stdEntry.Warnln(value...)
+	if l.isSkipped(logrus.WarnLevel, SPRINT_LN, "", value...) {
+		return
+	}
+	l.log(logrus.WarnLevel, SPRINT_LN, "", value...)
 }
 
 func (l golaLoggerEntry) Warningln(value ...interface{}) {
@@ -298,38 +389,145 @@ func (l golaLoggerEntry) Warningln(value ...interface{}) {
 }
 
 func (l golaLoggerEntry) Errorln(value ...interface{}) {
-	logToSpan(l, logrus.ErrorLevel, SPRINT_LN, "", value...)
-	l.stdEntry.Errorln(value...)
+	if l.isSkipped(logrus.ErrorLevel, SPRINT_LN, "", value...) {
+		return
+	}
+	l.log(logrus.ErrorLevel, SPRINT_LN, "", value...)
 }
 
 func (l golaLoggerEntry) Fatalln(value ...interface{}) {
 	//Always logToSpan first and then stdEntry for fatal
-	logToSpan(l, logrus.FatalLevel, SPRINT_LN, "", value...)
-	l.stdEntry.Fatalln(value...)
+	l.log(logrus.FatalLevel, SPRINT_LN, "", value...)
 }
 
 func (l golaLoggerEntry) Panicln(value ...interface{}) {
-	logToSpan(l, logrus.PanicLevel, SPRINT_LN, "", value...)
-	l.stdEntry.Panicln(value...)
+	l.log(logrus.PanicLevel, SPRINT_LN, "", value...)
+}
+
+// log annotates the span and writes the entry, with the details of a logged error. The
+// span and the logger each check their own level, fatal entries exit the process even
+// when the logger does not write them.
+func (l golaLoggerEntry) log(level logrus.Level, printType string, format string, value ...interface{}) {
+	entry := l.withErrorDetails(level, value...)
+	logToSpan(*entry, level, printType, format, value...)
+	if entry.isLevelEnabled(level) {
+		entry.output(level, printType, format, value...)
+	} else if level == logrus.FatalLevel {
+		entry.exit()
+	}
 }
 
-func logToSpan(l golaLoggerEntry, level logrus.Level, printType string, format string, value ...interface{}) {
-	var formattedValue string
-	if l.stdEntry.Logger.IsLevelEnabled(level) {
-		span := getSpan(l)
-
-		switch printType {
-		case SPRINT:
-			formattedValue = fmt.Sprint(value...)
-		case SPRINT_F:
-			formattedValue = fmt.Sprintf(format, value...)
-		case SPRINT_LN:
-			//The following is same as logrus
-			formattedValue = fmt.Sprintln(value...)
-			formattedValue = formattedValue[:len(formattedValue)-1]
+// exitProcess ends the process after a fatal entry of a backend logger, logrus runs its
+// exit handlers first.
+var exitProcess = logrus.Exit
+
+// exit flushes the async writer of the logger and exits through logrus, so that the exit
+// handlers run and the ExitFunc of the logrus logger is honoured.
+func (l golaLoggerEntry) exit() {
+	if writer, isAsync := asyncWriters.Load(l.levelGate()); isAsync {
+		writer.(*AsyncWriter).Flush()
+	}
+	if l.backend == nil {
+		l.stdEntry.Logger.Exit(1)
+		return
+	}
+	exitProcess(1)
+}
+
+// isSkipped reports whether an entry is below the level of this logger and of its span
+// logging, or sampled out.
+func (l golaLoggerEntry) isSkipped(level logrus.Level, printType string, format string, value ...interface{}) bool {
+	if !l.isLevelEnabled(level) && !l.isSpanLevelEnabled(level) {
+		return true
+	}
+	return l.isSampledOut(level, printType, format, value...)
+}
+
+// write hands the entry to logrus, or to the backend of loggers created by NewLogger.
+// Like logrus, fatal entries exit the process and panic entries panic.
+func (l golaLoggerEntry) write(level logrus.Level, printType string, format string, value ...interface{}) {
+	if l.backend == nil {
+		switch {
+		case !l.stdEntry.Logger.IsLevelEnabled(level):
+			logBelowLoggerLevel(l.stdEntry, level, formatMessage(printType, format, value...))
+		case printType == SPRINT_F:
+			l.stdEntry.Logf(level, format, value...)
+		case printType == SPRINT_LN:
+			l.stdEntry.Logln(level, value...)
+		default:
+			l.stdEntry.Log(level, value...)
 		}
+		if level == logrus.FatalLevel {
+			l.exit()
+		}
+		return
+	}
+
+	message := formatMessage(printType, format, value...)
+	l.backend.Log(l.context, level, l.Data, message)
+	switch level {
+	case logrus.PanicLevel:
+		panic(message)
+	case logrus.FatalLevel:
+		l.exit()
+	}
+}
+
+// belowLevelWrites serializes the writes of logBelowLoggerLevel, the mutex of logrus is
+// not exported.
+var belowLevelWrites sync.Mutex
+
+// logBelowLoggerLevel writes an entry enabled by a named level or a debug request while
+// the logrus logger stays at its own level. Entry.Log would drop it, so the entry is
+// fired to the hooks, formatted and written the way logrus does it.
+func logBelowLoggerLevel(stdEntry *logrus.Entry, level logrus.Level, message string) {
+	logger := stdEntry.Logger
+	data := make(logrus.Fields, len(stdEntry.Data))
+	for key, value := range stdEntry.Data {
+		data[key] = value
+	}
+	entry := &logrus.Entry{Logger: logger, Data: data, Time: stdEntry.Time, Level: level, Message: message, Context: stdEntry.Context}
+	if entry.Time.IsZero() {
+		entry.Time = time.Now()
+	}
+
+	belowLevelWrites.Lock()
+	defer belowLevelWrites.Unlock()
+	if err := logger.Hooks.Fire(level, entry); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to fire hook: %v\n", err)
+	}
+	serialized, err := logger.Formatter.Format(entry)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to obtain reader, %v\n", err)
+		return
+	}
+	if _, err = logger.Out.Write(serialized); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to write to log, %v\n", err)
+	}
+}
+
+func formatMessage(printType string, format string, value ...interface{}) string {
+	switch printType {
+	case SPRINT_F:
+		return fmt.Sprintf(format, value...)
+	case SPRINT_LN:
+		//The following is same as logrus
+		formattedValue := fmt.Sprintln(value...)
+		return formattedValue[:len(formattedValue)-1]
+	}
+	return fmt.Sprint(value...)
+}
 
-		span.Annotate(getSpanAttributes(l, level), formattedValue)
+func logToSpan(l golaLoggerEntry, level logrus.Level, printType string, format string, value ...interface{}) {
+	if !l.isSpanLevelEnabled(level) {
+		return
+	}
+	span := getSpan(l)
+	if span == nil || !span.IsRecordingEvents() {
+		return
+	}
+	if l.spanLogging().allowAnnotation(span, level) {
+		span.Annotate(getSpanAttributes(l, level), formatMessage(printType, format, value...))
 	}
 }
 
@@ -337,7 +535,7 @@ func getSpanAttributes(l golaLoggerEntry, level logrus.Level) []trace.Attribute
 	var logAttributes []trace.Attribute
 	logAttributes = append(logAttributes, trace.StringAttribute("level", fmt.Sprint(level)))
 	for key, data := range l.Data {
-		logAttributes = append(logAttributes, trace.StringAttribute(key, fmt.Sprint(data)))
+		logAttributes = append(logAttributes, trace.StringAttribute(key, formatSpanValue(data)))
 	}
 	return logAttributes
 }
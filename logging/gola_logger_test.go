@@ -8,6 +8,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/sirupsen/logrus"
@@ -46,7 +47,7 @@ func (suite *GolaLoggerTestSuite) TestShouldCreatePrivateNewLoggerEntrySuccessfu
 }
 
 func (suite *GolaLoggerTestSuite) TestShouldCreatePublicNewLoggerEntrySuccessfully() {
-	golaLoggerEntry := NewLoggerEntry()
+	golaLoggerEntry := NewLoggerEntry().(*golaLoggerEntry)
 	suite.Equal(context.TODO(), golaLoggerEntry.context)
 	suite.Equal(logrus.StandardLogger().WithContext(context.TODO()), golaLoggerEntry.stdEntry)
 	suite.Equal(0, len(golaLoggerEntry.Data))
@@ -55,7 +56,7 @@ func (suite *GolaLoggerTestSuite) TestShouldCreatePublicNewLoggerEntrySuccessful
 
 func (suite *GolaLoggerTestSuite) TestShouldSetWithErrorSuccessfully() {
 	err := errors.New("dummyError")
-	golaLoggerEntry := NewLoggerEntry().WithError(err)
+	golaLoggerEntry := NewLoggerEntry().WithError(err).(*golaLoggerEntry)
 	suite.Equal(context.TODO(), golaLoggerEntry.context)
 	suite.Equal(logrus.StandardLogger().WithContext(context.TODO()).WithError(err), golaLoggerEntry.stdEntry)
 	suite.Equal(1, len(golaLoggerEntry.Data))
@@ -64,7 +65,7 @@ func (suite *GolaLoggerTestSuite) TestShouldSetWithErrorSuccessfully() {
 }
 
 func (suite *GolaLoggerTestSuite) TestShouldSetWithFieldSuccessfully() {
-	golaLoggerEntry := NewLoggerEntry().WithField("key1", "value1")
+	golaLoggerEntry := NewLoggerEntry().WithField("key1", "value1").(*golaLoggerEntry)
 	suite.Equal(context.TODO(), golaLoggerEntry.context)
 	suite.Equal(logrus.StandardLogger().WithContext(context.TODO()).WithField("key1", "value1"), golaLoggerEntry.stdEntry)
 	suite.Equal(1, len(golaLoggerEntry.Data))
@@ -74,7 +75,7 @@ func (suite *GolaLoggerTestSuite) TestShouldSetWithFieldSuccessfully() {
 
 func (suite *GolaLoggerTestSuite) TestShouldSetWithFieldsSuccessfully() {
 	expectedLogrusEntry := logrus.StandardLogger().WithContext(context.TODO()).WithFields(logrus.Fields{"key1": "value1", "key2": "value2"})
-	golaLoggerEntry := NewLoggerEntry().WithFields(GolaFields{"key1": "value1", "key2": "value2"})
+	golaLoggerEntry := NewLoggerEntry().WithFields(GolaFields{"key1": "value1", "key2": "value2"}).(*golaLoggerEntry)
 	suite.Equal(context.TODO(), golaLoggerEntry.context)
 	suite.Equal(expectedLogrusEntry, golaLoggerEntry.stdEntry)
 	suite.Equal(2, len(golaLoggerEntry.Data))
@@ -89,7 +90,7 @@ func (suite *GolaLoggerTestSuite) TestShouldSetWithContextSuccessfully() {
 	expectedLogrusEntry := logrus.StandardLogger().WithContext(context.Background())
 	golaLoggerEntry := newLoggerEntry(context.Background(), expectedLogrusEntry)
 	suite.Equal(context.Background(), golaLoggerEntry.context)
-	golaLoggerEntry = golaLoggerEntry.WithContext(context.TODO())
+	golaLoggerEntry = golaLoggerEntry.withContext(context.TODO())
 	suite.Equal(context.TODO(), golaLoggerEntry.context)
 	expectedLogrusEntry.Context = context.TODO()
 	suite.Equal(expectedLogrusEntry, golaLoggerEntry.stdEntry)
@@ -100,7 +101,7 @@ func (suite *GolaLoggerTestSuite) TestShouldSetWithContextSuccessfully() {
 func (suite *GolaLoggerTestSuite) TestShouldSetFormatterSuccessfully() {
 	golaLoggerEntry := newLoggerEntry(context.TODO(), logrus.StandardLogger().WithContext(context.TODO()))
 	golaLoggerEntry.SetFormatter("json")
-	suite.Equal(&logrus.JSONFormatter{}, golaLoggerEntry.stdEntry.Logger.Formatter)
+	suite.Equal(NewLogTagFormatter(&logrus.JSONFormatter{}), golaLoggerEntry.stdEntry.Logger.Formatter)
 
 	suite.Equal(context.TODO(), golaLoggerEntry.context)
 	suite.Equal(0, len(golaLoggerEntry.Data))
@@ -386,6 +387,18 @@ func (suite *GolaLoggerTestSuite) TestShouldPrintErrorfLogsInLogrusAndSpanSucces
 //	suite.Equal("hi", t.SpanData.Annotations[0].Message)
 //}
 
+func (suite *GolaLoggerTestSuite) TestShouldExitOnFatalLogsBelowLoggerLevel() {
+	logger, hook := logrusTest.NewNullLogger()
+	logger.SetLevel(logrus.PanicLevel)
+	exitCode := 0
+	logger.ExitFunc = func(code int) { exitCode = code }
+
+	newLoggerEntry(context.TODO(), logger.WithContext(context.TODO())).Fatal("hi")
+
+	suite.Equal(1, exitCode)
+	suite.Equal(0, len(hook.AllEntries()))
+}
+
 func (suite *GolaLoggerTestSuite) TestShouldPrintPanicfLogsInLogrusAndSpanSuccessfully() {
 	logger, hook := logrusTest.NewNullLogger()
 	t := testExporter{}
@@ -734,6 +747,66 @@ func (suite *GolaLoggerTestSuite) TestShouldPrintSpanLogsWithDataSuccessfully()
 	hook.Reset()
 }
 
+func (suite *GolaLoggerTestSuite) TestShouldRedactTaggedFieldsInSpanLogs() {
+	type idToken struct {
+		Email string `log:"email,mask=email"`
+		Token string `log:"token,redact"`
+	}
+	logger, _ := logrusTest.NewNullLogger()
+	t := testExporter{}
+	trace.RegisterExporter(&t)
+	ctx, s := trace.StartSpan(suite.context, "test-span", trace.WithSampler(trace.AlwaysSample()), trace.WithSpanKind(trace.SpanKindServer))
+
+	var nilToken *idToken
+	golaLoggerEntry := newLoggerEntry(ctx, logger.WithContext(context.Background())).
+		WithField("idToken", idToken{Email: "abc@gmail.com", Token: "eyJhbGciOiJIUzI1NiJ9"}).
+		WithField("nilToken", nilToken)
+	golaLoggerEntry.Info("hi")
+	s.End()
+
+	suite.Equal(`{"email":"a*c@g***l.com","token":"[REDACTED]"}`, t.SpanData.Annotations[0].Attributes["idToken"])
+	suite.Equal("<nil>", t.SpanData.Annotations[0].Attributes["nilToken"])
+}
+
+type draftStatus int
+
+func (draftStatus) String() string {
+	return "published"
+}
+
+func (suite *GolaLoggerTestSuite) TestShouldKeepTimesStringersErrorsAndUntaggedStructsInSpanLogs() {
+	type page struct {
+		Number int
+		Size   int
+	}
+	type audit struct {
+		User      string    `log:"user,mask=email"`
+		CreatedAt time.Time `log:"createdAt"`
+	}
+	createdAt := time.Date(2020, 9, 1, 10, 0, 0, 0, time.UTC)
+	logger, _ := logrusTest.NewNullLogger()
+	t := testExporter{}
+	trace.RegisterExporter(&t)
+	defer trace.UnregisterExporter(&t)
+	ctx, s := trace.StartSpan(suite.context, "test-span", trace.WithSampler(trace.AlwaysSample()), trace.WithSpanKind(trace.SpanKindServer))
+
+	newLoggerEntry(ctx, logger.WithContext(context.Background())).
+		WithField("createdAt", createdAt).
+		WithField("status", draftStatus(1)).
+		WithField("cause", errors.New("draft not found")).
+		WithField("page", page{Number: 2, Size: 20}).
+		WithField("audit", audit{User: "abc@gmail.com", CreatedAt: createdAt}).
+		Info("hi")
+	s.End()
+
+	attributes := t.SpanData.Annotations[0].Attributes
+	suite.Equal("2020-09-01 10:00:00 +0000 UTC", attributes["createdAt"])
+	suite.Equal("published", attributes["status"])
+	suite.Equal("draft not found", attributes["cause"])
+	suite.Equal("{2 20}", attributes["page"])
+	suite.Equal(`{"createdAt":"2020-09-01T10:00:00Z","user":"a*c@g***l.com"}`, attributes["audit"])
+}
+
 func (suite *GolaLoggerTestSuite) TestShouldPrintLogsFromGinContextSuccessfully() {
 	logger, _ := logrusTest.NewNullLogger()
 	t := testExporter{}
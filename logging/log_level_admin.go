@@ -0,0 +1,49 @@
+package logging
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/inclusi-blog/gola-utils/golaerror"
+)
+
+const invalidLogLevelErrorCode = "ERR_INVALID_LOG_LEVEL"
+
+// LogLevelRequest changes the level of Logger, the root logger when it is empty. An
+// empty Level makes a named logger inherit its parent level again.
+type LogLevelRequest struct {
+	Logger       string `json:"logger"`
+	Level        string `json:"level"`
+	TTLInSeconds int    `json:"ttlInSeconds" binding:"min=0"`
+}
+
+// LogLevelAdminHandler lists the levels of config on GET and changes a level on PUT or
+// POST.
+// With a TTL the previous level is restored automatically.
+func LogLevelAdminHandler(config Config) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		logger := GetLogger(c)
+		switch c.Request.Method {
+		case http.MethodGet:
+			c.JSON(http.StatusOK, config.Levels())
+		case http.MethodPut, http.MethodPost:
+			var request LogLevelRequest
+			if err := c.ShouldBindJSON(&request); err != nil {
+				logger.Error("log level admin - invalid request body ", err)
+				c.AbortWithStatusJSON(http.StatusBadRequest, golaerror.New(invalidLogLevelErrorCode, err.Error(), nil))
+				return
+			}
+			ttl := time.Duration(request.TTLInSeconds) * time.Second
+			if err := config.SetLevelFor(request.Logger, request.Level, ttl); err != nil {
+				logger.Error("log level admin - invalid level ", err)
+				c.AbortWithStatusJSON(http.StatusBadRequest, golaerror.New(invalidLogLevelErrorCode, err.Error(), nil))
+				return
+			}
+			logger.Warnf("log level admin - level of %q set to %q for %s", request.Logger, request.Level, ttl)
+			c.JSON(http.StatusOK, config.Levels())
+		default:
+			c.AbortWithStatus(http.StatusMethodNotAllowed)
+		}
+	}
+}
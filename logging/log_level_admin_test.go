@@ -0,0 +1,86 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/inclusi-blog/gola-utils/golaerror"
+	"github.com/sirupsen/logrus"
+	logrusTest "github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/suite"
+)
+
+type LogLevelAdminTestSuite struct {
+	suite.Suite
+	logger   *logrus.Logger
+	entry    *golaLoggerEntry
+	engine   *gin.Engine
+	recorder *httptest.ResponseRecorder
+}
+
+func TestLogLevelAdminTestSuite(t *testing.T) {
+	suite.Run(t, new(LogLevelAdminTestSuite))
+}
+
+func (suite *LogLevelAdminTestSuite) SetupTest() {
+	suite.logger, _ = logrusTest.NewNullLogger()
+	suite.entry = newLoggerEntry(context.Background(), suite.logger.WithContext(context.Background()))
+	suite.engine = gin.New()
+	suite.engine.Any("/admin/log-levels", LogLevelAdminHandler(configOf(suite.entry)))
+	suite.recorder = httptest.NewRecorder()
+}
+
+func (suite *LogLevelAdminTestSuite) TearDownTest() {
+	registries.Delete(suite.logger)
+}
+
+func (suite *LogLevelAdminTestSuite) serve(method string, body string) {
+	request, _ := http.NewRequest(method, "/admin/log-levels", bytes.NewBufferString(body))
+	request.Header.Set("Content-Type", "application/json")
+	suite.engine.ServeHTTP(suite.recorder, request)
+}
+
+func (suite *LogLevelAdminTestSuite) TestShouldListLevels() {
+	_ = configOf(suite.entry).SetLevelFor("oauth", "debug", 0)
+
+	suite.serve(http.MethodGet, "")
+
+	var levels LogLevels
+	_ = json.Unmarshal(suite.recorder.Body.Bytes(), &levels)
+	suite.Equal(http.StatusOK, suite.recorder.Code)
+	suite.Equal(LogLevels{Root: "info", Loggers: map[string]string{"oauth": "debug"}}, levels)
+}
+
+func (suite *LogLevelAdminTestSuite) TestShouldChangeLevel() {
+	suite.serve(http.MethodPut, `{"logger":"oauth.token","level":"trace","ttlInSeconds":300}`)
+
+	suite.Equal(http.StatusOK, suite.recorder.Code)
+	suite.Equal(LogLevels{Root: "info", Loggers: map[string]string{"oauth.token": "trace"}}, configOf(suite.entry).Levels())
+}
+
+func (suite *LogLevelAdminTestSuite) TestShouldRejectInvalidLevel() {
+	suite.serve(http.MethodPost, `{"level":"verbose"}`)
+
+	var actualError golaerror.Error
+	_ = json.Unmarshal(suite.recorder.Body.Bytes(), &actualError)
+	suite.Equal(http.StatusBadRequest, suite.recorder.Code)
+	suite.Equal(invalidLogLevelErrorCode, actualError.ErrorCode)
+}
+
+func (suite *LogLevelAdminTestSuite) TestShouldRejectNegativeTTL() {
+	suite.serve(http.MethodPut, `{"level":"debug","ttlInSeconds":-1}`)
+
+	suite.Equal(http.StatusBadRequest, suite.recorder.Code)
+	suite.Equal("info", configOf(suite.entry).Levels().Root)
+}
+
+func (suite *LogLevelAdminTestSuite) TestShouldNotAllowOtherMethods() {
+	suite.serve(http.MethodDelete, "")
+
+	suite.Equal(http.StatusMethodNotAllowed, suite.recorder.Code)
+}
@@ -0,0 +1,187 @@
+package logging
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+const LoggerNameField = "logger"
+
+// LogLevels lists the root level and the levels set for named loggers. Named loggers
+// that are not listed inherit the level of their closest parent, "oauth.token"
+// inherits from "oauth", which inherits from the root.
+type LogLevels struct {
+	Root    string            `json:"root"`
+	Loggers map[string]string `json:"loggers"`
+}
+
+// registries holds the levels of named loggers per logrus logger or backend.
+var registries sync.Map
+
+// levelRegistry only takes over level checks while a named level is set or a request
+// runs in debug mode. The root level stays the level of the logrus logger or backend,
+// named and debug levels are only applied by golaLoggerEntry, so code logging through
+// logrus directly keeps the configured level.
+type levelRegistry struct {
+	mutex         sync.RWMutex
+	gate          levelGate
+	named         map[string]logrus.Level
+	generations   map[string]int
+	debugRequests int
+}
+
+func registryFor(gate levelGate) *levelRegistry {
+	if registry, ok := registries.Load(gate); ok {
+		return registry.(*levelRegistry)
+	}
+	registry, _ := registries.LoadOrStore(gate, &levelRegistry{
+		gate:        gate,
+		named:       make(map[string]logrus.Level),
+		generations: make(map[string]int),
+	})
+	return registry.(*levelRegistry)
+}
+
+// Named returns a sub-logger. Names of nested sub-loggers are joined with a dot.
+func (l golaLoggerEntry) Named(name string) Logger {
+	if l.name != "" {
+		name = l.name + "." + name
+	}
+	named := l.withFields(GolaFields{LoggerNameField: name})
+	named.name = name
+	return named
+}
+
+func GetNamedLogger(c context.Context, name string) Logger {
+	return GetLogger(c).Named(name)
+}
+
+// Levels returns the current levels of the logrus logger or backend.
+func (c loggerConfig) Levels() LogLevels {
+	return registryFor(c.root.levelGate()).levels()
+}
+
+// SetLevelFor sets the level of a named logger, the root level when name is empty.
+// An empty level makes a named logger inherit again. When ttl is positive, the
+// previous level is restored once it expires, unless the level was changed since.
+func (c loggerConfig) SetLevelFor(name string, lvl string, ttl time.Duration) error {
+	name = strings.TrimSpace(name)
+	registry := registryFor(c.root.levelGate())
+	if lvl == "" && name != "" {
+		registry.setLevel(name, nil, ttl)
+		return nil
+	}
+	level, err := parseLevel(lvl)
+	if err != nil {
+		return err
+	}
+	registry.setLevel(name, &level, ttl)
+	return nil
+}
+
+func (l golaLoggerEntry) isLevelEnabled(level logrus.Level) bool {
+	gate := l.levelGate()
+	if registry, ok := registries.Load(gate); ok {
+		return registry.(*levelRegistry).isEnabled(l.name, l.debug, level)
+	}
+	return gate.GetLevel() >= level
+}
+
+func (r *levelRegistry) isActive() bool {
+	return len(r.named) > 0 || r.debugRequests > 0
+}
+
+func (r *levelRegistry) isEnabled(name string, debug bool, level logrus.Level) bool {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	if !r.isActive() {
+		return r.gate.GetLevel() >= level
+	}
+	effective := r.effectiveLevel(name)
+	if debug && effective < logrus.DebugLevel {
+		effective = logrus.DebugLevel
+	}
+	return level <= effective
+}
+
+func (r *levelRegistry) effectiveLevel(name string) logrus.Level {
+	for name != "" {
+		if level, ok := r.named[name]; ok {
+			return level
+		}
+		index := strings.LastIndex(name, ".")
+		if index < 0 {
+			break
+		}
+		name = name[:index]
+	}
+	return r.gate.GetLevel()
+}
+
+func (r *levelRegistry) levels() LogLevels {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	levels := LogLevels{Root: r.gate.GetLevel().String(), Loggers: make(map[string]string, len(r.named))}
+	for name, level := range r.named {
+		levels.Loggers[name] = level.String()
+	}
+	return levels
+}
+
+// setLevel sets or, with a nil level, removes the level of name.
+func (r *levelRegistry) setLevel(name string, level *logrus.Level, ttl time.Duration) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	previous := r.levelOf(name)
+	r.generations[name]++
+	generation := r.generations[name]
+	r.update(name, level)
+
+	if ttl > 0 {
+		time.AfterFunc(ttl, func() {
+			r.mutex.Lock()
+			defer r.mutex.Unlock()
+			if r.generations[name] == generation {
+				r.update(name, previous)
+			}
+		})
+	}
+}
+
+func (r *levelRegistry) levelOf(name string) *logrus.Level {
+	if name == "" {
+		level := r.gate.GetLevel()
+		return &level
+	}
+	if level, ok := r.named[name]; ok {
+		return &level
+	}
+	return nil
+}
+
+func (r *levelRegistry) update(name string, level *logrus.Level) {
+	switch {
+	case name == "":
+		r.gate.SetLevel(*level)
+	case level == nil:
+		delete(r.named, name)
+	default:
+		r.named[name] = *level
+	}
+}
+
+func (r *levelRegistry) beginDebugRequest() {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.debugRequests++
+}
+
+func (r *levelRegistry) endDebugRequest() {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.debugRequests--
+}
@@ -0,0 +1,128 @@
+package logging
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	logrusTest "github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/suite"
+)
+
+type LogLevelsTestSuite struct {
+	suite.Suite
+	logger *logrus.Logger
+	hook   *logrusTest.Hook
+	entry  *golaLoggerEntry
+}
+
+func TestLogLevelsTestSuite(t *testing.T) {
+	suite.Run(t, new(LogLevelsTestSuite))
+}
+
+func (suite *LogLevelsTestSuite) SetupTest() {
+	suite.logger, suite.hook = logrusTest.NewNullLogger()
+	suite.entry = newLoggerEntry(context.Background(), suite.logger.WithContext(context.Background()))
+}
+
+func (suite *LogLevelsTestSuite) TearDownTest() {
+	registries.Delete(suite.logger)
+}
+
+func (suite *LogLevelsTestSuite) TestShouldInheritLevelFromClosestParent() {
+	suite.Nil(configOf(suite.entry).SetLevelFor("oauth", "debug", 0))
+
+	suite.entry.Named("oauth").Named("token").Debug("validated")
+	suite.entry.Named("cors").Debug("allowed")
+	suite.entry.Debug("root")
+
+	suite.Equal(1, len(suite.hook.AllEntries()))
+	suite.Equal("validated", suite.hook.LastEntry().Message)
+	suite.Equal("oauth.token", suite.hook.LastEntry().Data[LoggerNameField])
+	suite.Equal(logrus.InfoLevel, suite.logger.GetLevel())
+}
+
+func (suite *LogLevelsTestSuite) TestShouldAllowNamedLoggerToBeLessVerboseThanRoot() {
+	suite.Nil(configOf(suite.entry).SetLevelFor("cors", "error", 0))
+
+	suite.entry.Named("cors").Info("allowed")
+	suite.entry.Info("root")
+
+	suite.Equal(1, len(suite.hook.AllEntries()))
+	suite.Equal("root", suite.hook.LastEntry().Message)
+}
+
+func (suite *LogLevelsTestSuite) TestShouldSetLevelOfNamedLogger() {
+	cors := suite.entry.Named("cors")
+
+	suite.Nil(cors.SetLevel("warn"))
+
+	suite.Equal(LogLevels{Root: "info", Loggers: map[string]string{"cors": "warning"}}, configOf(suite.entry).Levels())
+}
+
+func (suite *LogLevelsTestSuite) TestShouldRemoveNamedLevel() {
+	suite.Nil(configOf(suite.entry).SetLevelFor("oauth", "trace", 0))
+
+	suite.Nil(configOf(suite.entry).SetLevelFor("oauth", "", 0))
+	suite.entry.Named("oauth").Debug("validated")
+
+	suite.Equal(0, len(suite.hook.AllEntries()))
+	suite.Equal(LogLevels{Root: "info", Loggers: map[string]string{}}, configOf(suite.entry).Levels())
+}
+
+func (suite *LogLevelsTestSuite) TestShouldKeepLogrusLevelForDirectLogrusUsers() {
+	suite.Nil(configOf(suite.entry).SetLevelFor("oauth", "trace", 0))
+	registryFor(suite.logger).beginDebugRequest()
+	defer registryFor(suite.logger).endDebugRequest()
+
+	suite.logger.Debug("direct")
+	suite.logger.WithField("traceID", "1").Trace("direct entry")
+	suite.entry.Named("oauth").Trace("validated")
+
+	suite.Equal(1, len(suite.hook.AllEntries()))
+	suite.Equal("validated", suite.hook.LastEntry().Message)
+	suite.Equal(logrus.TraceLevel, suite.hook.LastEntry().Level)
+	suite.Equal(logrus.InfoLevel, suite.logger.GetLevel())
+}
+
+func (suite *LogLevelsTestSuite) TestShouldRevertLevelAfterTTL() {
+	suite.Nil(configOf(suite.entry).SetLevelFor("", "debug", 20*time.Millisecond))
+	suite.Nil(configOf(suite.entry).SetLevelFor("oauth", "trace", 20*time.Millisecond))
+	suite.Equal(LogLevels{Root: "debug", Loggers: map[string]string{"oauth": "trace"}}, configOf(suite.entry).Levels())
+
+	time.Sleep(100 * time.Millisecond)
+
+	suite.Equal(LogLevels{Root: "info", Loggers: map[string]string{}}, configOf(suite.entry).Levels())
+	suite.Equal(logrus.InfoLevel, suite.logger.GetLevel())
+}
+
+func (suite *LogLevelsTestSuite) TestShouldNotRevertLevelChangedAfterTTLWasSet() {
+	suite.Nil(configOf(suite.entry).SetLevelFor("oauth", "debug", 20*time.Millisecond))
+	suite.Nil(configOf(suite.entry).SetLevelFor("oauth", "warn", 0))
+
+	time.Sleep(100 * time.Millisecond)
+
+	suite.Equal(LogLevels{Root: "info", Loggers: map[string]string{"oauth": "warning"}}, configOf(suite.entry).Levels())
+}
+
+func (suite *LogLevelsTestSuite) TestShouldRejectInvalidLevel() {
+	suite.EqualError(configOf(suite.entry).SetLevelFor("oauth", "verbose", 0), `not a valid golaLogger Level: "verbose"`)
+	suite.EqualError(suite.entry.SetLevel(""), `not a valid golaLogger Level: ""`)
+}
+
+func (suite *LogLevelsTestSuite) TestShouldLogDebugEntriesOfDebugRequestOnly() {
+	registry := registryFor(suite.logger)
+	registry.beginDebugRequest()
+	debugEntry := suite.entry.withFields(GolaFields{"traceID": "1"})
+	debugEntry.debug = true
+
+	debugEntry.Debug("debug request")
+	suite.entry.Debug("other request")
+	registry.endDebugRequest()
+	debugEntry.Debug("after request")
+
+	suite.Equal(1, len(suite.hook.AllEntries()))
+	suite.Equal("debug request", suite.hook.LastEntry().Message)
+	suite.Equal(logrus.InfoLevel, suite.logger.GetLevel())
+}
@@ -0,0 +1,264 @@
+package logging
+
+import (
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	DefaultSamplingTick            = time.Second
+	DefaultSamplingSummaryInterval = time.Minute
+
+	SampledLevelField   = "sampled_level"
+	DroppedEntriesField = "dropped_entries"
+	SamplingKeyField    = "sampling_key"
+)
+
+// LevelSampling is the sampling policy of a single level. Within every tick the First
+// entries of each message are logged, and always the first one even when First is
+// zero, after that only every Thereafter-th one. Entries
+// passing that check are then rate limited to RatePerSecond with bursts of up to Burst
+// entries. Zero values disable the respective check.
+type LevelSampling struct {
+	First         int     `json:"first"`
+	Thereafter    int     `json:"thereafter"`
+	RatePerSecond float64 `json:"ratePerSecond"`
+	Burst         int     `json:"burst"`
+}
+
+// SamplingConfig maps level names ("info", "warn", ...) to their policy, levels that
+// are not listed are never sampled. Fatal and panic entries can not be sampled. Entries
+// are counted per call site and format string, or first value when they are not
+// formatted, so entries differing only in their arguments count as the same message.
+// Entries with a SamplingKeyField are counted per value of that field instead of the
+// format string.
+type SamplingConfig struct {
+	TickInMillis            int                      `json:"tickInMillis"`
+	SummaryIntervalInMillis int                      `json:"summaryIntervalInMillis"`
+	Levels                  map[string]LevelSampling `json:"levels"`
+}
+
+// samplers holds the sampler of each logrus logger or backend, so that every entry
+// derived from the same logger shares the same counters, just like it shares the level.
+var samplers sync.Map
+
+type samplingKey struct {
+	level   logrus.Level
+	file    string
+	line    int
+	message string
+}
+
+type tokenBucket struct {
+	tokens   float64
+	refillAt time.Time
+}
+
+type logSampler struct {
+	mutex           sync.Mutex
+	tick            time.Duration
+	summaryInterval time.Duration
+	levels          map[logrus.Level]LevelSampling
+	counts          map[samplingKey]int
+	buckets         map[logrus.Level]*tokenBucket
+	dropped         map[logrus.Level]int64
+	tickStartedAt   time.Time
+	now             func() time.Time
+	stop            chan struct{}
+}
+
+func newLogSampler(config SamplingConfig, now func() time.Time) (*logSampler, error) {
+	levels := make(map[logrus.Level]LevelSampling, len(config.Levels))
+	for name, policy := range config.Levels {
+		level, err := parseLevel(name)
+		if err != nil {
+			return nil, err
+		}
+		if level <= logrus.FatalLevel {
+			return nil, fmt.Errorf("golaLogger Level %q can not be sampled", name)
+		}
+		if policy.First < 0 || policy.Thereafter < 0 || policy.RatePerSecond < 0 || policy.Burst < 0 {
+			return nil, fmt.Errorf("sampling of golaLogger Level %q must not be negative", name)
+		}
+		levels[level] = policy
+	}
+	return &logSampler{
+		tick:            durationInMillisOrDefault(config.TickInMillis, DefaultSamplingTick),
+		summaryInterval: durationInMillisOrDefault(config.SummaryIntervalInMillis, DefaultSamplingSummaryInterval),
+		levels:          levels,
+		counts:          make(map[samplingKey]int),
+		buckets:         make(map[logrus.Level]*tokenBucket),
+		dropped:         make(map[logrus.Level]int64),
+		tickStartedAt:   now(),
+		now:             now,
+		stop:            make(chan struct{}),
+	}, nil
+}
+
+func durationInMillisOrDefault(millis int, defaultDuration time.Duration) time.Duration {
+	if millis <= 0 {
+		return defaultDuration
+	}
+	return time.Duration(millis) * time.Millisecond
+}
+
+// SetSampling applies the sampling policy to every entry of the underlying logrus
+// logger or backend. An empty config turns sampling off. Every summary interval, the
+// number of dropped entries of each level is logged at that level.
+func (c loggerConfig) SetSampling(config SamplingConfig) error {
+	var sampler *logSampler
+	if len(config.Levels) > 0 {
+		var err error
+		if sampler, err = newLogSampler(config, time.Now); err != nil {
+			return err
+		}
+	}
+	gate := c.root.levelGate()
+	previous, hasPrevious := samplers.Load(gate)
+	if sampler != nil {
+		samplers.Store(gate, sampler)
+		go sampler.summarizeEvery(c.root)
+	} else {
+		samplers.Delete(gate)
+	}
+	if hasPrevious {
+		close(previous.(*logSampler).stop)
+	}
+	return nil
+}
+
+// isSampledOut decides once per entry whether it is dropped, so that the logrus output
+// and the span annotation always agree. Only entries above the level are counted.
+func (l golaLoggerEntry) isSampledOut(level logrus.Level, printType string, format string, value ...interface{}) bool {
+	sampler, ok := samplers.Load(l.levelGate())
+	if !ok {
+		return false
+	}
+	message := samplingMessage(printType, format, value...)
+	if key, hasKey := l.Data[SamplingKeyField]; hasKey {
+		message = fmt.Sprint(key)
+	}
+	return !sampler.(*logSampler).allow(level, message)
+}
+
+// samplingMessage is the format string of an entry, or its first value when it is not
+// formatted, so that the ids and durations passed along do not make every entry unique.
+func samplingMessage(printType string, format string, value ...interface{}) string {
+	if printType == SPRINT_F {
+		return format
+	}
+	if len(value) == 0 {
+		return ""
+	}
+	if message, ok := value[0].(string); ok {
+		return message
+	}
+	return fmt.Sprint(value[0])
+}
+
+func (s *logSampler) allow(level logrus.Level, message string) bool {
+	key := samplingKey{level: level, message: message}
+	if policy := s.levels[level]; policy.First > 0 || policy.Thereafter > 0 {
+		if caller, found := callerFrame(); found {
+			key.file, key.line = caller.File, caller.Line
+		}
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	isAllowed := s.sample(s.now(), key)
+	if !isAllowed {
+		s.dropped[level]++
+	}
+	return isAllowed
+}
+
+func (s *logSampler) sample(now time.Time, key samplingKey) bool {
+	policy, isSampled := s.levels[key.level]
+	if !isSampled {
+		return true
+	}
+	if now.Sub(s.tickStartedAt) >= s.tick {
+		s.counts = make(map[samplingKey]int)
+		s.tickStartedAt = now
+	}
+
+	if policy.First > 0 || policy.Thereafter > 0 {
+		first := policy.First
+		if first == 0 {
+			first = 1
+		}
+		s.counts[key]++
+		count := s.counts[key]
+		if count > first {
+			if policy.Thereafter == 0 || (count-first)%policy.Thereafter != 0 {
+				return false
+			}
+		}
+	}
+	return s.takeToken(now, key.level, policy)
+}
+
+func (s *logSampler) takeToken(now time.Time, level logrus.Level, policy LevelSampling) bool {
+	if policy.RatePerSecond == 0 {
+		return true
+	}
+	capacity := float64(policy.Burst)
+	if capacity == 0 {
+		capacity = math.Max(1, math.Ceil(policy.RatePerSecond))
+	}
+	bucket, ok := s.buckets[level]
+	if !ok {
+		bucket = &tokenBucket{tokens: capacity, refillAt: now}
+		s.buckets[level] = bucket
+	}
+	bucket.tokens = math.Min(capacity, bucket.tokens+now.Sub(bucket.refillAt).Seconds()*policy.RatePerSecond)
+	bucket.refillAt = now
+	if bucket.tokens < 1 {
+		return false
+	}
+	bucket.tokens--
+	return true
+}
+
+// summarizeEvery logs the summary of dropped entries to entry every summary interval,
+// until the sampler is replaced.
+func (s *logSampler) summarizeEvery(entry *golaLoggerEntry) {
+	ticker := time.NewTicker(s.summaryInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			s.summarize(entry)
+		case <-s.stop:
+			return
+		}
+	}
+}
+
+func (s *logSampler) summarize(entry *golaLoggerEntry) {
+	for droppedLevel, droppedEntries := range s.takeDropped() {
+		if !entry.isLevelEnabled(droppedLevel) {
+			continue
+		}
+		entry.withFields(GolaFields{
+			SampledLevelField:   droppedLevel.String(),
+			DroppedEntriesField: droppedEntries,
+		}).output(droppedLevel, SPRINT_F, "log sampling dropped %d %s entries in the last %s", droppedEntries, droppedLevel, s.summaryInterval)
+	}
+}
+
+func (s *logSampler) takeDropped() map[logrus.Level]int64 {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if len(s.dropped) == 0 {
+		return nil
+	}
+	dropped := s.dropped
+	s.dropped = make(map[logrus.Level]int64)
+	return dropped
+}
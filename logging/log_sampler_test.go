@@ -0,0 +1,222 @@
+package logging
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	logrusTest "github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/suite"
+	"go.opencensus.io/trace"
+)
+
+type LogSamplerTestSuite struct {
+	suite.Suite
+	logger *logrus.Logger
+	hook   *logrusTest.Hook
+	now    time.Time
+}
+
+func TestLogSamplerTestSuite(t *testing.T) {
+	suite.Run(t, new(LogSamplerTestSuite))
+}
+
+func (suite *LogSamplerTestSuite) SetupTest() {
+	suite.logger, suite.hook = logrusTest.NewNullLogger()
+	suite.now = time.Date(2020, 10, 10, 15, 4, 5, 0, time.UTC)
+}
+
+func (suite *LogSamplerTestSuite) TearDownTest() {
+	samplers.Delete(suite.logger)
+}
+
+func (suite *LogSamplerTestSuite) withSampling(config SamplingConfig) *golaLoggerEntry {
+	sampler, err := newLogSampler(config, func() time.Time { return suite.now })
+	suite.Nil(err)
+	samplers.Store(suite.logger, sampler)
+	return newLoggerEntry(context.Background(), suite.logger.WithContext(context.Background()))
+}
+
+func (suite *LogSamplerTestSuite) messages() []string {
+	var messages []string
+	for _, entry := range suite.hook.AllEntries() {
+		messages = append(messages, entry.Message)
+	}
+	return messages
+}
+
+func (suite *LogSamplerTestSuite) TestShouldLogFirstNThenEveryMthEntryPerMessage() {
+	entry := suite.withSampling(SamplingConfig{Levels: map[string]LevelSampling{INFO: {First: 2, Thereafter: 3}}})
+
+	for i := 1; i <= 8; i++ {
+		entry.Infof("request %d", i)
+	}
+
+	suite.Equal([]string{"request 1", "request 2", "request 5", "request 8"}, suite.messages())
+}
+
+func (suite *LogSamplerTestSuite) TestShouldAlwaysLogFirstEntryWithoutFirst() {
+	entry := suite.withSampling(SamplingConfig{Levels: map[string]LevelSampling{INFO: {Thereafter: 3}}})
+
+	for i := 1; i <= 7; i++ {
+		entry.Infof("request %d", i)
+	}
+
+	suite.Equal([]string{"request 1", "request 4", "request 7"}, suite.messages())
+}
+
+func (suite *LogSamplerTestSuite) TestShouldCountMessagesAndLevelsSeparately() {
+	entry := suite.withSampling(SamplingConfig{Levels: map[string]LevelSampling{INFO: {First: 1}}})
+
+	for _, message := range []string{"cors", "cors", "token validated"} {
+		entry.Info(message)
+	}
+	entry.Warn("cors")
+
+	suite.Equal([]string{"cors", "token validated", "cors"}, suite.messages())
+}
+
+func (suite *LogSamplerTestSuite) TestShouldCountCallSitesSeparately() {
+	entry := suite.withSampling(SamplingConfig{Levels: map[string]LevelSampling{INFO: {First: 1}}})
+
+	for i := 0; i < 2; i++ {
+		entry.Errorf("%v", "draft not found")
+		entry.Infof("%v", "token expired")
+		entry.Infof("%v", "cache miss")
+	}
+
+	suite.Equal([]string{"draft not found", "token expired", "cache miss", "draft not found"}, suite.messages())
+}
+
+func (suite *LogSamplerTestSuite) TestShouldResetCountersEveryTick() {
+	entry := suite.withSampling(SamplingConfig{TickInMillis: 1000, Levels: map[string]LevelSampling{INFO: {First: 1}}})
+	cors := func() { entry.Info("cors") }
+
+	cors()
+	cors()
+	suite.now = suite.now.Add(time.Second)
+	cors()
+
+	suite.Equal(2, len(suite.hook.AllEntries()))
+}
+
+func (suite *LogSamplerTestSuite) TestShouldRateLimitWithBurst() {
+	entry := suite.withSampling(SamplingConfig{Levels: map[string]LevelSampling{DEBUG: {RatePerSecond: 2, Burst: 3}}})
+	suite.logger.SetLevel(logrus.DebugLevel)
+
+	for i := 0; i < 5; i++ {
+		entry.Debugln("query")
+	}
+	suite.Equal(3, len(suite.hook.AllEntries()))
+
+	suite.now = suite.now.Add(time.Second)
+	for i := 0; i < 5; i++ {
+		entry.Debugln("query")
+	}
+	suite.Equal(5, len(suite.hook.AllEntries()))
+}
+
+func (suite *LogSamplerTestSuite) TestShouldNotSampleLevelsWithoutPolicy() {
+	entry := suite.withSampling(SamplingConfig{Levels: map[string]LevelSampling{INFO: {First: 1}}})
+
+	for i := 0; i < 3; i++ {
+		entry.Error("failed")
+	}
+
+	suite.Equal(3, len(suite.hook.AllEntries()))
+}
+
+func (suite *LogSamplerTestSuite) TestShouldCountEntriesByFormatOrFirstValue() {
+	entry := suite.withSampling(SamplingConfig{Levels: map[string]LevelSampling{INFO: {First: 1}}})
+
+	for id := 1; id <= 3; id++ {
+		entry.Infof("user %d logged in", id)
+		entry.Info("user ", id, " logged out")
+		entry.Infoln("token refreshed for", id)
+	}
+
+	suite.Equal([]string{"user 1 logged in", "user 1 logged out", "token refreshed for 1"}, suite.messages())
+}
+
+func (suite *LogSamplerTestSuite) TestShouldCountEntriesBySamplingKeyField() {
+	entry := suite.withSampling(SamplingConfig{Levels: map[string]LevelSampling{INFO: {First: 1}}})
+
+	for _, route := range []string{"/api/drafts", "/api/drafts", "/api/users"} {
+		entry.WithField(SamplingKeyField, "GET "+route).Infof("%s %s", "GET", route)
+	}
+
+	suite.Equal([]string{"GET /api/drafts", "GET /api/users"}, suite.messages())
+}
+
+func (suite *LogSamplerTestSuite) TestShouldEmitSummaryOfDroppedEntries() {
+	entry := suite.withSampling(SamplingConfig{SummaryIntervalInMillis: 60000, Levels: map[string]LevelSampling{INFO: {First: 1}}})
+	sampler, _ := samplers.Load(suite.logger)
+
+	for i := 0; i < 4; i++ {
+		entry.Info("cors")
+	}
+	sampler.(*logSampler).summarize(entry)
+	sampler.(*logSampler).summarize(entry)
+
+	entries := suite.hook.AllEntries()
+	suite.Equal(2, len(entries))
+	summary := entries[1]
+	suite.Equal("log sampling dropped 3 info entries in the last 1m0s", summary.Message)
+	suite.Equal(logrus.InfoLevel, summary.Level)
+	suite.Equal("info", summary.Data[SampledLevelField])
+	suite.Equal(int64(3), summary.Data[DroppedEntriesField])
+}
+
+func (suite *LogSamplerTestSuite) TestShouldEmitSummaryEveryIntervalWithoutFurtherEntries() {
+	entry := newLoggerEntry(context.Background(), suite.logger.WithContext(context.Background()))
+	suite.Nil(configOf(entry).SetSampling(SamplingConfig{SummaryIntervalInMillis: 20, Levels: map[string]LevelSampling{INFO: {First: 1}}}))
+	defer configOf(entry).SetSampling(SamplingConfig{})
+
+	for i := 0; i < 2; i++ {
+		entry.Info("cors")
+	}
+	time.Sleep(100 * time.Millisecond)
+
+	entries := suite.hook.AllEntries()
+	suite.Equal(2, len(entries))
+	suite.Equal("log sampling dropped 1 info entries in the last 20ms", entries[1].Message)
+}
+
+func (suite *LogSamplerTestSuite) TestShouldApplySamplingToSpanAnnotations() {
+	t := testExporter{}
+	trace.RegisterExporter(&t)
+	defer trace.UnregisterExporter(&t)
+	ctx, s := trace.StartSpan(context.Background(), "test-span", trace.WithSampler(trace.AlwaysSample()))
+	entry := suite.withSampling(SamplingConfig{Levels: map[string]LevelSampling{INFO: {First: 1}}}).WithContext(ctx)
+
+	for i := 0; i < 2; i++ {
+		entry.Info("cors")
+	}
+	s.End()
+
+	suite.Equal(1, len(suite.hook.AllEntries()))
+	suite.Equal(1, len(t.SpanData.Annotations))
+}
+
+func (suite *LogSamplerTestSuite) TestShouldSetAndRemoveSamplingOnLogger() {
+	entry := newLoggerEntry(context.Background(), suite.logger.WithContext(context.Background()))
+	retrying := func(logger Logger) { logger.Warn("retrying") }
+
+	suite.Nil(configOf(entry).SetSampling(SamplingConfig{Levels: map[string]LevelSampling{WARNING: {First: 1}}}))
+	retrying(entry)
+	retrying(entry.WithField("attempt", 2))
+	suite.Equal(1, len(suite.hook.AllEntries()))
+
+	suite.Nil(configOf(entry).SetSampling(SamplingConfig{}))
+	retrying(entry)
+	suite.Equal(2, len(suite.hook.AllEntries()))
+}
+
+func (suite *LogSamplerTestSuite) TestShouldRejectInvalidSamplingConfig() {
+	entry := newLoggerEntry(context.Background(), suite.logger.WithContext(context.Background()))
+
+	suite.EqualError(configOf(entry).SetSampling(SamplingConfig{Levels: map[string]LevelSampling{"dummy": {}}}), `not a valid golaLogger Level: "dummy"`)
+	suite.EqualError(configOf(entry).SetSampling(SamplingConfig{Levels: map[string]LevelSampling{FATAL: {First: 1}}}), `golaLogger Level "fatal" can not be sampled`)
+	suite.EqualError(configOf(entry).SetSampling(SamplingConfig{Levels: map[string]LevelSampling{INFO: {First: -1}}}), `sampling of golaLogger Level "info" must not be negative`)
+}
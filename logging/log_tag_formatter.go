@@ -1,37 +1,52 @@
 package logging
 
 import (
-	"github.com/jmoiron/sqlx/reflectx"
-	"github.com/sirupsen/logrus"
+	"encoding/json"
+	"fmt"
 	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/inclusi-blog/gola-utils/mask_util"
+	"github.com/sirupsen/logrus"
 )
 
 const (
 	LogTag = "log"
+
+	logTagRedactOption = "redact"
+	logTagMaskOption   = "mask"
+	maxLogTagDepth     = 16
 )
 
+// LogTagFormatter replaces struct values of an entry with a map of their log tagged
+// fields. Tag options control how a field value is written:
+//
+//	Email  string `log:"email,mask=email"`
+//	Token  string `log:"token,redact"`
+//	Card   string `log:"card,mask=last4"`
+//	Secret string `log:"-"`
+//
+// Slices, arrays, maps and pointers are followed, so tagged structs inside them are
+// redacted as well.
 type LogTagFormatter struct {
 	defaultFormatter logrus.Formatter
 	options          map[string]interface{}
 }
 
+// NewLogTagFormatter returns a LogTagFormatter writing the redacted entries with
+// formatter, or as JSON when formatter is nil.
+func NewLogTagFormatter(formatter logrus.Formatter) *LogTagFormatter {
+	return &LogTagFormatter{defaultFormatter: formatter}
+}
+
 func (f *LogTagFormatter) Format(entry *logrus.Entry) ([]byte, error) {
 	formattedEntry := *entry
+	formattedEntry.Data = make(logrus.Fields, len(entry.Data))
 
 	for k, v := range entry.Data {
-		entryDataType := reflect.TypeOf(v)
-		entryDataValue := reflect.ValueOf(v)
-		if entryDataType.Kind() == reflect.Ptr {
-			entryDataType = entryDataType.Elem()
-		}
-		if entryDataType.Kind() == reflect.Struct {
-			mp := reflectx.NewMapperFunc(LogTag, func(s string) string { return "" })
-
-			root := mp.TypeMap(entryDataType).Tree
-
-			formattedStruct := traverseFieldTree(entryDataValue, root)
-			formattedEntry.Data[k] = formattedStruct
-		}
+		formattedEntry.Data[k] = formatLogValue(v)
 	}
 
 	if f.defaultFormatter == nil {
@@ -40,20 +55,196 @@ func (f *LogTagFormatter) Format(entry *logrus.Entry) ([]byte, error) {
 	return f.defaultFormatter.Format(&formattedEntry)
 }
 
-func traverseFieldTree(val reflect.Value, root *reflectx.FieldInfo) map[string]interface{} {
-	if root == nil {
+type logTagOptions struct {
+	name   string
+	redact bool
+	mask   mask_util.MaskFieldKeyType
+}
+
+func parseLogTag(tag string) logTagOptions {
+	parts := strings.Split(tag, ",")
+	options := logTagOptions{name: strings.TrimSpace(parts[0])}
+	for _, option := range parts[1:] {
+		option = strings.TrimSpace(option)
+		switch {
+		case option == logTagRedactOption:
+			options.redact = true
+		case strings.HasPrefix(option, logTagMaskOption+"="):
+			options.mask = mask_util.MaskFieldKeyType(strings.TrimPrefix(option, logTagMaskOption+"="))
+		}
+	}
+	return options
+}
+
+var (
+	timeType     = reflect.TypeOf(time.Time{})
+	stringerType = reflect.TypeOf((*fmt.Stringer)(nil)).Elem()
+	errorType    = reflect.TypeOf((*error)(nil)).Elem()
+
+	// taggedStructs caches whether a struct type has log tagged fields.
+	taggedStructs sync.Map
+)
+
+// formatLogValue returns v with every struct replaced by a map of its log tagged
+// fields. Errors, fmt.Stringers and times without log tags are kept as they are, so
+// that formatters can still print them.
+func formatLogValue(v interface{}) interface{} {
+	return formatValue(v, false)
+}
+
+// formatValue formats v like formatLogValue. With keepUntagged only structs with log
+// tags are reshaped, others are kept as they are.
+func formatValue(v interface{}, keepUntagged bool) interface{} {
+	if v == nil {
 		return nil
 	}
-	var childMap = map[string]interface{}{}
-	for _, child := range root.Children {
-		if child == nil || child.Name == "" {
+	if _, isError := v.(error); isError {
+		return v
+	}
+	return formatReflectValue(reflect.ValueOf(v), 0, keepUntagged)
+}
+
+func formatReflectValue(val reflect.Value, depth int, keepUntagged bool) interface{} {
+	if !val.IsValid() || depth > maxLogTagDepth {
+		return nil
+	}
+	if isPrintable(val) {
+		return val.Interface()
+	}
+	switch val.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if val.IsNil() {
+			return nil
+		}
+		return formatReflectValue(val.Elem(), depth+1, keepUntagged)
+	case reflect.Struct:
+		if keepUntagged && !hasLogTags(val.Type()) && val.CanInterface() {
+			return val.Interface()
+		}
+		return formatStruct(val, depth)
+	case reflect.Slice:
+		if val.Type().Elem().Kind() == reflect.Uint8 && val.CanInterface() {
+			return val.Interface()
+		}
+		if val.IsNil() {
+			return nil
+		}
+		return formatList(val, depth, keepUntagged)
+	case reflect.Array:
+		return formatList(val, depth, keepUntagged)
+	case reflect.Map:
+		if val.IsNil() {
+			return nil
+		}
+		formattedMap := make(map[string]interface{}, val.Len())
+		iterator := val.MapRange()
+		for iterator.Next() {
+			formattedMap[fmt.Sprint(iterator.Key().Interface())] = formatReflectValue(iterator.Value(), depth+1, keepUntagged)
+		}
+		return formattedMap
+	}
+	if val.CanInterface() {
+		return val.Interface()
+	}
+	return nil
+}
+
+func formatList(val reflect.Value, depth int, keepUntagged bool) []interface{} {
+	formattedList := make([]interface{}, val.Len())
+	for i := 0; i < val.Len(); i++ {
+		formattedList[i] = formatReflectValue(val.Index(i), depth+1, keepUntagged)
+	}
+	return formattedList
+}
+
+// isPrintable reports whether val is a time, fmt.Stringer or error whose struct has no
+// log tags, such values print themselves.
+func isPrintable(val reflect.Value) bool {
+	if !val.CanInterface() || (val.Kind() == reflect.Ptr && val.IsNil()) {
+		return false
+	}
+	valType := val.Type()
+	if valType != timeType && !valType.Implements(stringerType) && !valType.Implements(errorType) {
+		return false
+	}
+	if valType.Kind() == reflect.Ptr {
+		valType = valType.Elem()
+	}
+	return valType.Kind() != reflect.Struct || !hasLogTags(valType)
+}
+
+// hasLogTags reports whether structType has log tagged fields, directly or through
+// untagged embedded structs.
+func hasLogTags(structType reflect.Type) bool {
+	if tagged, ok := taggedStructs.Load(structType); ok {
+		return tagged.(bool)
+	}
+	tagged := false
+	for i := 0; i < structType.NumField() && !tagged; i++ {
+		field := structType.Field(i)
+		if _, isTagged := field.Tag.Lookup(LogTag); isTagged {
+			tagged = true
 			continue
 		}
-		if len(child.Children) == 0 {
-			childMap[child.Name] = reflectx.FieldByIndexes(val, child.Index).Interface()
+		embeddedType := field.Type
+		if embeddedType.Kind() == reflect.Ptr {
+			embeddedType = embeddedType.Elem()
+		}
+		tagged = field.Anonymous && embeddedType.Kind() == reflect.Struct && hasLogTags(embeddedType)
+	}
+	taggedStructs.Store(structType, tagged)
+	return tagged
+}
+
+func formatStruct(val reflect.Value, depth int) map[string]interface{} {
+	formattedStruct := map[string]interface{}{}
+	addStructFields(formattedStruct, val, depth)
+	return formattedStruct
+}
+
+func addStructFields(formattedStruct map[string]interface{}, val reflect.Value, depth int) {
+	structType := val.Type()
+	for i := 0; i < structType.NumField(); i++ {
+		field := structType.Field(i)
+		tag, isTagged := field.Tag.Lookup(LogTag)
+		if !isTagged && field.Anonymous {
+			// untagged embedded structs promote their tagged fields, like encoding/json
+			embedded := reflect.Indirect(val.Field(i))
+			if embedded.IsValid() && embedded.Kind() == reflect.Struct {
+				addStructFields(formattedStruct, embedded, depth+1)
+			}
+			continue
+		}
+		options := parseLogTag(tag)
+		if options.name == "" || options.name == "-" || (field.PkgPath != "" && !field.Anonymous) {
 			continue
 		}
-		childMap[child.Name] = traverseFieldTree(val, child)
+		formattedStruct[options.name] = formatTaggedField(val.Field(i), options, depth)
+	}
+}
+
+func formatTaggedField(val reflect.Value, options logTagOptions, depth int) interface{} {
+	if options.redact {
+		return mask_util.RedactedText
+	}
+	formatted := formatReflectValue(val, depth+1, false)
+	if options.mask == "" || formatted == nil {
+		return formatted
+	}
+	masked, _ := mask_util.Mask(options.mask, fmt.Sprint(formatted))
+	return masked
+}
+
+// formatSpanValue applies the same redaction as LogTagFormatter to span attributes.
+// Structs with log tags and collections are written as JSON, everything else,
+// including untagged structs, as before.
+func formatSpanValue(v interface{}) string {
+	formatted := formatValue(v, true)
+	switch formatted.(type) {
+	case map[string]interface{}, []interface{}:
+		if encoded, err := json.Marshal(formatted); err == nil {
+			return string(encoded)
+		}
 	}
-	return childMap
+	return fmt.Sprint(formatted)
 }
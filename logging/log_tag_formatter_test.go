@@ -3,9 +3,11 @@ package logging
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"github.com/sirupsen/logrus"
 	"reflect"
 	"testing"
+	"time"
 )
 
 func TestBasic(t *testing.T) {
@@ -386,3 +388,195 @@ func TestLogTagFormatter_Format_DefaultFormatter(t *testing.T) {
 		t.Fatal("Error default formatter not set")
 	}
 }
+
+func TestStructRedactionOptions(t *testing.T) {
+	formatter := LogTagFormatter{}
+	type IdToken struct {
+		Email    string `log:"email,mask=email"`
+		Token    string `log:"token,redact"`
+		Card     string `log:"card,mask=last4"`
+		Password string `log:"-"`
+		UserId   int    `log:"userId"`
+	}
+	s := &IdToken{
+		Email:    "gobinath@yahoo.co.in",
+		Token:    "eyJhbGciOiJIUzI1NiJ9",
+		Card:     "4111111111111111",
+		Password: "secret",
+		UserId:   42,
+	}
+
+	b, err := formatter.Format(logrus.WithField("idToken", s))
+	if err != nil {
+		t.Fatal("Unable to format entry: ", err)
+	}
+
+	entry := make(map[string]interface{})
+	err = json.Unmarshal(b, &entry)
+	if err != nil {
+		t.Fatal("Unable to unmarshal formatted entry: ", err)
+	}
+
+	idToken := entry["idToken"].(map[string]interface{})
+	if idToken["email"] != "g******h@y***o.co.in" {
+		t.Fatal("Error email not masked: ", idToken["email"])
+	}
+	if idToken["token"] != "[REDACTED]" {
+		t.Fatal("Error token not redacted: ", idToken["token"])
+	}
+	if idToken["card"] != "************1111" {
+		t.Fatal("Error card not masked: ", idToken["card"])
+	}
+	if _, ok := idToken["Password"]; ok {
+		t.Fatal("Error skipped field set")
+	}
+	if idToken["userId"].(float64) != 42 {
+		t.Fatal("Error plain field not set")
+	}
+	if bytes.Contains(b, []byte("eyJhbGciOiJIUzI1NiJ9")) || bytes.Contains(b, []byte("secret")) {
+		t.Fatal("Error secret leaked: ", string(b))
+	}
+}
+
+func TestStructInCollections(t *testing.T) {
+	formatter := LogTagFormatter{}
+	type User struct {
+		Email string `log:"email,mask=email"`
+		Name  string
+	}
+	type Team struct {
+		Members []User           `log:"members"`
+		Leads   map[string]*User `log:"leads"`
+	}
+
+	b, err := formatter.Format(logrus.
+		WithField("team", Team{
+			Members: []User{{Email: "abc@gmail.com", Name: "abc"}},
+			Leads:   map[string]*User{"backend": {Email: "abc@gmail.com"}},
+		}).
+		WithField("users", []*User{{Email: "abc@gmail.com"}, nil}))
+	if err != nil {
+		t.Fatal("Unable to format entry: ", err)
+	}
+
+	entry := make(map[string]interface{})
+	err = json.Unmarshal(b, &entry)
+	if err != nil {
+		t.Fatal("Unable to unmarshal formatted entry: ", err)
+	}
+
+	team := entry["team"].(map[string]interface{})
+	member := team["members"].([]interface{})[0].(map[string]interface{})
+	if member["email"] != "a*c@g***l.com" {
+		t.Fatal("Error slice element not masked: ", member["email"])
+	}
+	if _, ok := member["Name"]; ok {
+		t.Fatal("Error untagged field set")
+	}
+	lead := team["leads"].(map[string]interface{})["backend"].(map[string]interface{})
+	if lead["email"] != "a*c@g***l.com" {
+		t.Fatal("Error map value not masked: ", lead["email"])
+	}
+	users := entry["users"].([]interface{})
+	if users[0].(map[string]interface{})["email"] != "a*c@g***l.com" || users[1] != nil {
+		t.Fatal("Error pointer slice not formatted: ", users)
+	}
+}
+
+func TestNilValuesAndErrors(t *testing.T) {
+	formatter := LogTagFormatter{}
+	type A struct {
+		A *int `log:"aa,mask=last4"`
+	}
+	var nilStruct *A
+
+	b, err := formatter.Format(logrus.WithFields(logrus.Fields{
+		"nilValue":   nil,
+		"nilPointer": nilStruct,
+		"nilField":   A{},
+		"error":      errors.New("dummy error"),
+	}))
+	if err != nil {
+		t.Fatal("Unable to format entry: ", err)
+	}
+
+	entry := make(map[string]interface{})
+	err = json.Unmarshal(b, &entry)
+	if err != nil {
+		t.Fatal("Unable to unmarshal formatted entry: ", err)
+	}
+
+	if entry["nilValue"] != nil || entry["nilPointer"] != nil {
+		t.Fatal("Error nil values not kept")
+	}
+	if entry["nilField"].(map[string]interface{})["aa"] != nil {
+		t.Fatal("Error nil field masked")
+	}
+	if entry["error"] != "dummy error" {
+		t.Fatal("Error error value not kept: ", entry["error"])
+	}
+}
+
+func TestStructEmbeddedWithoutTag(t *testing.T) {
+	formatter := LogTagFormatter{}
+	type Base struct {
+		Token string `log:"token,redact"`
+	}
+	type Request struct {
+		Base
+		Id int `log:"id"`
+	}
+
+	b, err := formatter.Format(logrus.WithField("request", Request{Base: Base{Token: "abc"}, Id: 1}))
+	if err != nil {
+		t.Fatal("Unable to format entry: ", err)
+	}
+
+	entry := make(map[string]interface{})
+	err = json.Unmarshal(b, &entry)
+	if err != nil {
+		t.Fatal("Unable to unmarshal formatted entry: ", err)
+	}
+
+	request := entry["request"].(map[string]interface{})
+	if request["token"] != "[REDACTED]" || request["id"].(float64) != 1 {
+		t.Fatal("Error embedded fields not promoted: ", request)
+	}
+}
+
+type currency string
+
+func (c currency) String() string {
+	return "currency:" + string(c)
+}
+
+func TestTimesAndStringersKept(t *testing.T) {
+	formatter := LogTagFormatter{}
+	type Payment struct {
+		PaidAt   time.Time `log:"paidAt"`
+		Currency currency  `log:"currency"`
+	}
+	paidAt := time.Date(2020, 9, 1, 10, 0, 0, 0, time.UTC)
+
+	b, err := formatter.Format(logrus.WithFields(logrus.Fields{
+		"payment": Payment{PaidAt: paidAt, Currency: "INR"},
+		"paidAt":  paidAt,
+	}))
+	if err != nil {
+		t.Fatal("Unable to format entry: ", err)
+	}
+
+	entry := make(map[string]interface{})
+	err = json.Unmarshal(b, &entry)
+	if err != nil {
+		t.Fatal("Unable to unmarshal formatted entry: ", err)
+	}
+
+	payment := entry["payment"].(map[string]interface{})
+	if payment["paidAt"] != "2020-09-01T10:00:00Z" || payment["currency"] != "INR" {
+		t.Fatal("Error tagged time or stringer not kept: ", payment)
+	}
+	if entry["paidAt"] != "2020-09-01T10:00:00Z" {
+		t.Fatal("Error time not kept: ", entry["paidAt"])
+	}
+}
@@ -7,19 +7,19 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
-func GetLogger(c context.Context) *golaLoggerEntry {
+func GetLogger(c context.Context) Logger {
 	if c == nil {
 		return NewLoggerEntry().WithContext(c).WithField(constants.TRACE_KEY, constants.NO_TRACE_ID)
 	}
 	if ginContext, ok := c.(*gin.Context); ok {
 		logger, ok := ginContext.Get(constants.LOGGER_KEY)
 		if ok {
-			return logger.(*golaLoggerEntry)
+			return logger.(Logger)
 		}
 	}
 	contextLogger := c.Value(constants.LOGGER_KEY)
 	if contextLogger != nil {
-		if logger, ok := contextLogger.(*golaLoggerEntry); ok {
+		if logger, ok := contextLogger.(Logger); ok {
 			if ok {
 				return logger
 			}
@@ -27,3 +27,11 @@ func GetLogger(c context.Context) *golaLoggerEntry {
 	}
 	return NewLoggerEntry().WithContext(c).WithField(constants.TRACE_KEY, constants.NO_TRACE_ID)
 }
+
+// EnrichLogger adds fields to the logger of the request, so that every later
+// GetLogger(c) entry and its span annotation carry them.
+func EnrichLogger(c *gin.Context, fields GolaFields) Logger {
+	logger := GetLogger(c).WithFields(fields)
+	c.Set(constants.LOGGER_KEY, logger)
+	return logger
+}
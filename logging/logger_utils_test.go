@@ -26,7 +26,7 @@ func (suite *LoggerUtilsTestSuite) SetupTest() {
 
 func (suite *LoggerUtilsTestSuite) TestShouldGetDefaultLoggerFromContext() {
 	logger := GetLogger(suite.context)
-	traceID, ok := logger.Data["traceID"]
+	traceID, ok := logger.Fields()["traceID"]
 	suite.True(ok)
 	suite.Equal("no-trace-id", traceID)
 }
@@ -36,14 +36,14 @@ func (suite *LoggerUtilsTestSuite) TestShouldGetLoggerFromGinContextWithCorrelat
 
 	logger := GetLogger(suite.context)
 
-	traceID, ok := logger.Data["traceID"]
+	traceID, ok := logger.Fields()["traceID"]
 	suite.True(ok)
 	suite.Equal("sampleID", traceID)
 }
 
 func (suite *LoggerUtilsTestSuite) TestShouldGetLoggerFromGoContextWithNoTraceId() {
 	logger := GetLogger(context.TODO())
-	traceID, ok := logger.Data["traceID"]
+	traceID, ok := logger.Fields()["traceID"]
 	suite.True(ok)
 	suite.Equal("no-trace-id", traceID)
 }
@@ -51,7 +51,7 @@ func (suite *LoggerUtilsTestSuite) TestShouldGetLoggerFromGoContextWithNoTraceId
 func (suite *LoggerUtilsTestSuite) TestShouldGetLoggerFromGoContextWithCorrelationID() {
 	ctx := context.WithValue(context.Background(), "logger", NewLoggerEntry().WithField("traceID", "sampleID"))
 	logger := GetLogger(ctx)
-	traceID, ok := logger.Data["traceID"]
+	traceID, ok := logger.Fields()["traceID"]
 	suite.True(ok)
 	suite.Equal("sampleID", traceID)
 }
@@ -59,7 +59,17 @@ func (suite *LoggerUtilsTestSuite) TestShouldGetLoggerFromGoContextWithCorrelati
 func (suite *LoggerUtilsTestSuite) TestShouldGetDefaultLoggerWhenContextIsNil() {
 	// ctx := context.WithValue(context.Background(), "logger", NewLoggerEntry().WithField("traceID", "sampleID"))
 	logger := GetLogger(nil)
-	traceID, ok := logger.Data["traceID"]
+	traceID, ok := logger.Fields()["traceID"]
 	suite.True(ok)
 	suite.Equal("no-trace-id", traceID)
 }
+
+func (suite *LoggerUtilsTestSuite) TestShouldEnrichLoggerOfGinContext() {
+	suite.context.Set("logger", NewLoggerEntry().WithContext(suite.context).WithField("traceID", "sampleID"))
+
+	EnrichLogger(suite.context, GolaFields{"user_id": "42"})
+
+	logger := GetLogger(suite.context)
+	suite.Equal("sampleID", logger.Fields()["traceID"])
+	suite.Equal("42", logger.Fields()["user_id"])
+}
@@ -1,27 +1,67 @@
 package logging
 
 import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"strings"
+
 	"github.com/gin-gonic/gin"
 	"github.com/inclusi-blog/gola-utils/constants"
+	"github.com/inclusi-blog/gola-utils/tracing/propagation"
+	"go.opencensus.io/trace"
 )
 
-func LoggingMiddleware(entry *golaLoggerEntry) gin.HandlerFunc {
+func LoggingMiddleware(entry Logger) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		traceID := c.Request.Header.Get(constants.TRACE_ID_HTTP_HEADER)
-		if traceID == "" {
-			traceID = constants.NO_TRACE_ID
-		}
-		//standardLogger := logrus.StandardLogger()
-		jsonFormat := c.Request.Header.Get(constants.JSON_FORMATTER_HTTP_HEADER)
-		if jsonFormat != "" {
-			entry.SetFormatter(constants.JSON)
+		setRequestLogger(c, entry, false)
+		c.Next()
+	}
+}
+
+// LoggingMiddlewareWithRequestDebug works like LoggingMiddleware and logs requests
+// carrying "X-Debug-Log: true" at debug level, whatever the configured levels are.
+// Only use it behind a gateway that strips the header from untrusted callers.
+func LoggingMiddlewareWithRequestDebug(entry Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		golaEntry, isGolaEntry := entry.(*golaLoggerEntry)
+		debug := isGolaEntry && strings.EqualFold(c.Request.Header.Get(constants.DEBUG_LOG_HTTP_HEADER), "true")
+		if debug {
+			registry := registryFor(golaEntry.levelGate())
+			registry.beginDebugRequest()
+			defer registry.endDebugRequest()
 		}
-		//for _, h := range hooks {
-		// standardLogger.AddHook(h)
-		//}
-		logger := entry.WithField(constants.TRACE_KEY, traceID).WithContext(c.Request.Context())
-		c.Set(constants.LOGGER_KEY, logger)
-		c.Set(constants.TRACE_KEY, traceID)
+		setRequestLogger(c, entry, debug)
 		c.Next()
 	}
 }
+
+func setRequestLogger(c *gin.Context, entry Logger, debug bool) {
+	traceID := c.Request.Header.Get(constants.TRACE_ID_HTTP_HEADER)
+	if traceID == "" {
+		traceID = propagation.TraceIDFromRequest(c.Request)
+		if traceID == "" {
+			traceID = requestTraceID(c.Request.Context())
+		}
+		c.Writer.Header().Set(constants.TRACE_ID_HTTP_HEADER, traceID)
+	}
+	logger := entry.WithField(constants.TRACE_KEY, traceID).WithContext(c.Request.Context())
+	if golaEntry, ok := logger.(*golaLoggerEntry); ok {
+		golaEntry.debug = debug
+	}
+	c.Set(constants.LOGGER_KEY, logger)
+	c.Set(constants.TRACE_KEY, traceID)
+}
+
+// requestTraceID returns the trace ID of the span started by tracing.WithTracing for
+// requests without a propagated trace ID, or a new one when the request is not traced.
+func requestTraceID(ctx context.Context) string {
+	if span := trace.FromContext(ctx); span != nil {
+		return span.SpanContext().TraceID.String()
+	}
+	var traceID trace.TraceID
+	if _, err := rand.Read(traceID[:]); err != nil {
+		return constants.NO_TRACE_ID
+	}
+	return hex.EncodeToString(traceID[:])
+}
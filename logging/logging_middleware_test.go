@@ -1,8 +1,15 @@
 package logging
 
 import (
+	"context"
+
 	"github.com/gin-gonic/gin"
+	"github.com/inclusi-blog/gola-utils/constants"
+	"github.com/inclusi-blog/gola-utils/tracing/propagation"
+	"github.com/sirupsen/logrus"
+	logrusTest "github.com/sirupsen/logrus/hooks/test"
 	"github.com/stretchr/testify/suite"
+	"go.opencensus.io/trace"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -86,7 +93,75 @@ func (suite *LoggingMiddlewareTestSuite) TestContextShouldHaveNewCorrelationIdIf
 
 	traceID, ok := suite.context.Keys["traceID"]
 	suite.True(ok)
-	// traceID is Default one
-	id := "no-trace-id"
-	suite.Equal(traceID, id)
+	suite.Regexp("^[0-9a-f]{32}$", traceID)
+	suite.Equal(traceID, suite.context.Keys["logger"].(*golaLoggerEntry).Data["traceID"])
+	suite.Equal(traceID, suite.recorder.Header().Get(constants.TRACE_ID_HTTP_HEADER))
+
+	loggingMiddleware(suite.context)
+	suite.NotEqual(traceID, suite.context.Keys["traceID"])
+}
+
+func (suite *LoggingMiddlewareTestSuite) TestContextShouldHaveTraceIdOfSpanIfNotPassedInRequestHeader() {
+	ctx, span := trace.StartSpan(context.Background(), "/customer-profile")
+	defer span.End()
+	suite.context.Request, _ = http.NewRequest("GET", "/customer-profile?user-id=12345", nil)
+	suite.context.Request = suite.context.Request.WithContext(ctx)
+
+	LoggingMiddleware(NewLoggerEntry())(suite.context)
+
+	suite.Equal(span.SpanContext().TraceID.String(), suite.context.Keys["traceID"])
+	suite.Equal(span.SpanContext().TraceID.String(), suite.recorder.Header().Get(constants.TRACE_ID_HTTP_HEADER))
+}
+
+func (suite *LoggingMiddlewareTestSuite) TestContextShouldHaveTraceIdOfConfiguredPropagationFormat() {
+	suite.Nil(propagation.Configure(propagation.W3C, propagation.B3))
+	defer propagation.Configure(propagation.B3)
+	suite.context.Request, _ = http.NewRequest("GET", "/customer-profile?user-id=12345", nil)
+	suite.context.Request.Header.Set(propagation.TraceParentHeader, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+
+	LoggingMiddleware(NewLoggerEntry())(suite.context)
+
+	suite.Equal("4bf92f3577b34da6a3ce929d0e0e4736", suite.context.Keys["traceID"])
+	suite.Equal("4bf92f3577b34da6a3ce929d0e0e4736", suite.recorder.Header().Get(constants.TRACE_ID_HTTP_HEADER))
+}
+
+func (suite *LoggingMiddlewareTestSuite) TestShouldNotSetResponseHeaderForIncomingTraceId() {
+	suite.context.Request, _ = http.NewRequest("GET", "/customer-profile?user-id=12345", nil)
+	suite.context.Request.Header.Set("X-B3-Traceid", "sampleID")
+
+	LoggingMiddleware(NewLoggerEntry())(suite.context)
+
+	suite.Empty(suite.recorder.Header().Get(constants.TRACE_ID_HTTP_HEADER))
+}
+
+func (suite *LoggingMiddlewareTestSuite) TestShouldLogAtDebugLevelOnlyForRequestWithDebugHeader() {
+	logger, hook := logrusTest.NewNullLogger()
+	defer registries.Delete(logger)
+	entry := newLoggerEntry(context.Background(), logger.WithContext(context.Background()))
+	engine := gin.New()
+	engine.GET("/customer-profile", LoggingMiddlewareWithRequestDebug(entry), func(c *gin.Context) {
+		GetLogger(c).Debug(c.Request.Header.Get(constants.DEBUG_LOG_HTTP_HEADER))
+		c.Status(http.StatusOK)
+	})
+
+	for _, debugHeader := range []string{"true", "false"} {
+		request, _ := http.NewRequest("GET", "/customer-profile", nil)
+		request.Header.Set(constants.DEBUG_LOG_HTTP_HEADER, debugHeader)
+		engine.ServeHTTP(httptest.NewRecorder(), request)
+	}
+
+	suite.Equal(1, len(hook.AllEntries()))
+	suite.Equal("true", hook.LastEntry().Message)
+	suite.Equal(logrus.InfoLevel, logger.GetLevel())
+}
+
+func (suite *LoggingMiddlewareTestSuite) TestShouldNotChangeFormatterForJSONHeader() {
+	logger, _ := logrusTest.NewNullLogger()
+	formatter := logger.Formatter
+	suite.context.Request, _ = http.NewRequest("GET", "/customer-profile", nil)
+	suite.context.Request.Header.Set(constants.JSON_FORMATTER_HTTP_HEADER, "true")
+
+	LoggingMiddleware(newLoggerEntry(context.Background(), logger.WithContext(context.Background())))(suite.context)
+
+	suite.Equal(formatter, logger.Formatter)
 }
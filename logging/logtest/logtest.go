@@ -0,0 +1,191 @@
+// Package logtest captures the entries and span annotations of the gola logger in
+// tests. Every Recorder only sees its own entries and the spans it started, so tests
+// using separate recorders can run in parallel.
+package logtest
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+	"github.com/inclusi-blog/gola-utils/constants"
+	"github.com/inclusi-blog/gola-utils/logging"
+	"go.opencensus.io/trace"
+)
+
+// TestingT is the part of *testing.T used by the assertions.
+type TestingT interface {
+	Errorf(format string, args ...interface{})
+}
+
+type Entry struct {
+	Level   logging.Level
+	Message string
+	Fields  logging.GolaFields
+}
+
+type Annotation struct {
+	SpanName   string
+	Message    string
+	Attributes map[string]interface{}
+}
+
+type Recorder struct {
+	mutex       sync.RWMutex
+	level       logging.Level
+	logger      logging.Logger
+	entries     []Entry
+	traceIDs    map[trace.TraceID]bool
+	annotations []Annotation
+}
+
+// New returns a recorder capturing entries of every level. Call Close once the test
+// is done to stop receiving spans.
+func New() *Recorder {
+	recorder := &Recorder{level: logging.TraceLevel, traceIDs: make(map[trace.TraceID]bool)}
+	recorder.logger = logging.NewLogger(recorder)
+	trace.RegisterExporter(recorder)
+	return recorder
+}
+
+func (r *Recorder) Close() {
+	trace.UnregisterExporter(r)
+}
+
+// Logger returns the capturing logger.
+func (r *Recorder) Logger() logging.Logger {
+	return r.logger
+}
+
+// Install returns a context on which logging.GetLogger returns the capturing logger.
+func (r *Recorder) Install(ctx context.Context) context.Context {
+	return context.WithValue(ctx, constants.LOGGER_KEY, r.logger.WithContext(ctx))
+}
+
+// InstallGin makes logging.GetLogger(c) return the capturing logger, annotating the
+// span of the request context.
+func (r *Recorder) InstallGin(c *gin.Context) {
+	var ctx context.Context = c
+	if c.Request != nil {
+		ctx = c.Request.Context()
+	}
+	c.Set(constants.LOGGER_KEY, r.logger.WithContext(ctx))
+}
+
+// StartSpan starts a sampled span whose annotations are captured once it ends, and
+// installs the capturing logger on its context.
+func (r *Recorder) StartSpan(ctx context.Context, name string) (context.Context, *trace.Span) {
+	ctx, span := trace.StartSpan(ctx, name, trace.WithSampler(trace.AlwaysSample()))
+	r.mutex.Lock()
+	r.traceIDs[span.SpanContext().TraceID] = true
+	r.mutex.Unlock()
+	return r.Install(ctx), span
+}
+
+func (r *Recorder) Log(ctx context.Context, level logging.Level, fields logging.GolaFields, message string) {
+	copiedFields := make(logging.GolaFields, len(fields))
+	for k, v := range fields {
+		copiedFields[k] = v
+	}
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.entries = append(r.entries, Entry{Level: level, Message: message, Fields: copiedFields})
+}
+
+func (r *Recorder) GetLevel() logging.Level {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	return r.level
+}
+
+func (r *Recorder) SetLevel(level logging.Level) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.level = level
+}
+
+func (r *Recorder) ExportSpan(s *trace.SpanData) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if !r.traceIDs[s.TraceID] {
+		return
+	}
+	for _, annotation := range s.Annotations {
+		r.annotations = append(r.annotations, Annotation{SpanName: s.Name, Message: annotation.Message, Attributes: annotation.Attributes})
+	}
+}
+
+func (r *Recorder) Entries() []Entry {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	return append([]Entry(nil), r.entries...)
+}
+
+// Annotations returns the log annotations of the ended spans started by StartSpan.
+func (r *Recorder) Annotations() []Annotation {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	return append([]Annotation(nil), r.annotations...)
+}
+
+func (r *Recorder) Reset() {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.entries = nil
+	r.annotations = nil
+}
+
+// AssertLogged checks that an entry of level was logged whose message contains
+// msgSubstring and whose fields include fields.
+func (r *Recorder) AssertLogged(t TestingT, level logging.Level, msgSubstring string, fields logging.GolaFields) bool {
+	if h, ok := t.(interface{ Helper() }); ok {
+		h.Helper()
+	}
+	entries := r.Entries()
+	for _, entry := range entries {
+		if entry.Level == level && strings.Contains(entry.Message, msgSubstring) && hasFields(entry.Fields, fields) {
+			return true
+		}
+	}
+	t.Errorf("no %s entry containing %q with fields %v was logged, got:\n%s", level, msgSubstring, fields, formatEntries(entries))
+	return false
+}
+
+// AssertNoErrors checks that nothing was logged at error level or above.
+func (r *Recorder) AssertNoErrors(t TestingT) bool {
+	if h, ok := t.(interface{ Helper() }); ok {
+		h.Helper()
+	}
+	var errorEntries []Entry
+	for _, entry := range r.Entries() {
+		if entry.Level <= logging.ErrorLevel {
+			errorEntries = append(errorEntries, entry)
+		}
+	}
+	if len(errorEntries) == 0 {
+		return true
+	}
+	t.Errorf("expected no error entries, got:\n%s", formatEntries(errorEntries))
+	return false
+}
+
+func hasFields(actual logging.GolaFields, expected logging.GolaFields) bool {
+	for k, v := range expected {
+		actualValue, ok := actual[k]
+		if !ok || !reflect.DeepEqual(actualValue, v) {
+			return false
+		}
+	}
+	return true
+}
+
+func formatEntries(entries []Entry) string {
+	var builder strings.Builder
+	for _, entry := range entries {
+		builder.WriteString(fmt.Sprintf("\t%s %q %v\n", entry.Level, entry.Message, entry.Fields))
+	}
+	return builder.String()
+}
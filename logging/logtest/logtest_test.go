@@ -0,0 +1,117 @@
+package logtest
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/inclusi-blog/gola-utils/logging"
+	"github.com/stretchr/testify/suite"
+)
+
+type fakeT struct {
+	errors []string
+}
+
+func (t *fakeT) Errorf(format string, args ...interface{}) {
+	t.errors = append(t.errors, fmt.Sprintf(format, args...))
+}
+
+type LogTestTestSuite struct {
+	suite.Suite
+	recorder *Recorder
+}
+
+func TestLogTestTestSuite(t *testing.T) {
+	suite.Run(t, new(LogTestTestSuite))
+}
+
+func (suite *LogTestTestSuite) SetupTest() {
+	suite.recorder = New()
+}
+
+func (suite *LogTestTestSuite) TearDownTest() {
+	suite.recorder.Close()
+}
+
+func (suite *LogTestTestSuite) TestShouldCaptureEntriesOfInstalledContext() {
+	ctx := suite.recorder.Install(context.Background())
+
+	logging.GetLogger(ctx).WithField("user_id", "42").Infof("loaded %d configs", 2)
+
+	suite.Equal([]Entry{{Level: logging.InfoLevel, Message: "loaded 2 configs", Fields: logging.GolaFields{"user_id": "42"}}}, suite.recorder.Entries())
+	suite.True(suite.recorder.AssertLogged(suite.T(), logging.InfoLevel, "loaded", logging.GolaFields{"user_id": "42"}))
+}
+
+func (suite *LogTestTestSuite) TestShouldCaptureEntriesOfInstalledGinContext() {
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request, _ = http.NewRequest("GET", "/customer-profile", nil)
+	suite.recorder.InstallGin(c)
+
+	logging.GetLogger(c).Debug("customer profile")
+
+	suite.True(suite.recorder.AssertLogged(suite.T(), logging.DebugLevel, "profile", nil))
+}
+
+func (suite *LogTestTestSuite) TestShouldCaptureSpanAnnotationsOfEndedSpans() {
+	ctx, span := suite.recorder.StartSpan(context.Background(), "test-span")
+
+	logging.GetLogger(ctx).Warn("retrying")
+	suite.Empty(suite.recorder.Annotations())
+	span.End()
+
+	annotations := suite.recorder.Annotations()
+	suite.Equal(1, len(annotations))
+	suite.Equal("test-span", annotations[0].SpanName)
+	suite.Equal("retrying", annotations[0].Message)
+	suite.Equal("warning", annotations[0].Attributes["level"])
+}
+
+func (suite *LogTestTestSuite) TestShouldReportMissingEntries() {
+	t := &fakeT{}
+	suite.recorder.Logger().WithField("attempt", 1).Error("request failed")
+
+	suite.False(suite.recorder.AssertLogged(t, logging.ErrorLevel, "request failed", logging.GolaFields{"attempt": 2}))
+	suite.False(suite.recorder.AssertLogged(t, logging.WarnLevel, "request failed", nil))
+	suite.False(suite.recorder.AssertNoErrors(t))
+	suite.Equal(3, len(t.errors))
+	suite.Contains(t.errors[2], `error "request failed" map[attempt:1 caller:logtest/logtest_test.go:`)
+}
+
+func (suite *LogTestTestSuite) TestShouldPassAssertNoErrorsForWarnings() {
+	t := &fakeT{}
+	suite.recorder.Logger().Warn("retrying")
+
+	suite.True(suite.recorder.AssertNoErrors(t))
+	suite.Empty(t.errors)
+}
+
+func (suite *LogTestTestSuite) TestShouldResetCapturedEntries() {
+	suite.recorder.Logger().Info("started")
+	suite.recorder.Reset()
+
+	suite.Empty(suite.recorder.Entries())
+}
+
+func TestShouldKeepRecordersOfParallelTestsApart(t *testing.T) {
+	for i := 0; i < 10; i++ {
+		i := i
+		t.Run(fmt.Sprint(i), func(t *testing.T) {
+			t.Parallel()
+			recorder := New()
+			defer recorder.Close()
+
+			ctx, span := recorder.StartSpan(context.Background(), "parallel-span")
+			logging.GetLogger(ctx).Infof("test %d", i)
+			span.End()
+
+			if len(recorder.Entries()) != 1 || len(recorder.Annotations()) != 1 {
+				t.Fatalf("expected 1 entry and 1 annotation, got %v and %v", recorder.Entries(), recorder.Annotations())
+			}
+			recorder.AssertLogged(t, logging.InfoLevel, fmt.Sprintf("test %d", i), nil)
+		})
+	}
+}
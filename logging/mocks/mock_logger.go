@@ -0,0 +1,707 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: gola_logger.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	gomock "github.com/golang/mock/gomock"
+	logging "github.com/inclusi-blog/gola-utils/logging"
+	reflect "reflect"
+	time "time"
+)
+
+// MockLogger is a mock of Logger interface
+type MockLogger struct {
+	ctrl     *gomock.Controller
+	recorder *MockLoggerMockRecorder
+}
+
+// MockLoggerMockRecorder is the mock recorder for MockLogger
+type MockLoggerMockRecorder struct {
+	mock *MockLogger
+}
+
+// NewMockLogger creates a new mock instance
+func NewMockLogger(ctrl *gomock.Controller) *MockLogger {
+	mock := &MockLogger{ctrl: ctrl}
+	mock.recorder = &MockLoggerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockLogger) EXPECT() *MockLoggerMockRecorder {
+	return m.recorder
+}
+
+// WithError mocks base method
+func (m *MockLogger) WithError(err error) logging.Logger {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WithError", err)
+	ret0, _ := ret[0].(logging.Logger)
+	return ret0
+}
+
+// WithError indicates an expected call of WithError
+func (mr *MockLoggerMockRecorder) WithError(err interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WithError", reflect.TypeOf((*MockLogger)(nil).WithError), err)
+}
+
+// WithField mocks base method
+func (m *MockLogger) WithField(key string, value interface{}) logging.Logger {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WithField", key, value)
+	ret0, _ := ret[0].(logging.Logger)
+	return ret0
+}
+
+// WithField indicates an expected call of WithField
+func (mr *MockLoggerMockRecorder) WithField(key, value interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WithField", reflect.TypeOf((*MockLogger)(nil).WithField), key, value)
+}
+
+// WithFields mocks base method
+func (m *MockLogger) WithFields(fields logging.GolaFields) logging.Logger {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WithFields", fields)
+	ret0, _ := ret[0].(logging.Logger)
+	return ret0
+}
+
+// WithFields indicates an expected call of WithFields
+func (mr *MockLoggerMockRecorder) WithFields(fields interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WithFields", reflect.TypeOf((*MockLogger)(nil).WithFields), fields)
+}
+
+// WithContext mocks base method
+func (m *MockLogger) WithContext(ctx context.Context) logging.Logger {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WithContext", ctx)
+	ret0, _ := ret[0].(logging.Logger)
+	return ret0
+}
+
+// WithContext indicates an expected call of WithContext
+func (mr *MockLoggerMockRecorder) WithContext(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WithContext", reflect.TypeOf((*MockLogger)(nil).WithContext), ctx)
+}
+
+// Named mocks base method
+func (m *MockLogger) Named(name string) logging.Logger {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Named", name)
+	ret0, _ := ret[0].(logging.Logger)
+	return ret0
+}
+
+// Named indicates an expected call of Named
+func (mr *MockLoggerMockRecorder) Named(name interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Named", reflect.TypeOf((*MockLogger)(nil).Named), name)
+}
+
+// Fields mocks base method
+func (m *MockLogger) Fields() logging.GolaFields {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Fields")
+	ret0, _ := ret[0].(logging.GolaFields)
+	return ret0
+}
+
+// Fields indicates an expected call of Fields
+func (mr *MockLoggerMockRecorder) Fields() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Fields", reflect.TypeOf((*MockLogger)(nil).Fields))
+}
+
+// AddHook mocks base method
+func (m *MockLogger) AddHook(hk logging.GolaLoggerHook) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "AddHook", hk)
+}
+
+// AddHook indicates an expected call of AddHook
+func (mr *MockLoggerMockRecorder) AddHook(hk interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddHook", reflect.TypeOf((*MockLogger)(nil).AddHook), hk)
+}
+
+// SetFormatter mocks base method
+func (m *MockLogger) SetFormatter(format string) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SetFormatter", format)
+}
+
+// SetFormatter indicates an expected call of SetFormatter
+func (mr *MockLoggerMockRecorder) SetFormatter(format interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetFormatter", reflect.TypeOf((*MockLogger)(nil).SetFormatter), format)
+}
+
+// SetLevel mocks base method
+func (m *MockLogger) SetLevel(lvl string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetLevel", lvl)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetLevel indicates an expected call of SetLevel
+func (mr *MockLoggerMockRecorder) SetLevel(lvl interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetLevel", reflect.TypeOf((*MockLogger)(nil).SetLevel), lvl)
+}
+
+// Tracef mocks base method
+func (m *MockLogger) Tracef(format string, value ...interface{}) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{format}
+	for _, a := range value {
+		varargs = append(varargs, a)
+	}
+	m.ctrl.Call(m, "Tracef", varargs...)
+}
+
+// Tracef indicates an expected call of Tracef
+func (mr *MockLoggerMockRecorder) Tracef(format interface{}, value ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{format}, value...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Tracef", reflect.TypeOf((*MockLogger)(nil).Tracef), varargs...)
+}
+
+// Debugf mocks base method
+func (m *MockLogger) Debugf(format string, value ...interface{}) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{format}
+	for _, a := range value {
+		varargs = append(varargs, a)
+	}
+	m.ctrl.Call(m, "Debugf", varargs...)
+}
+
+// Debugf indicates an expected call of Debugf
+func (mr *MockLoggerMockRecorder) Debugf(format interface{}, value ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{format}, value...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Debugf", reflect.TypeOf((*MockLogger)(nil).Debugf), varargs...)
+}
+
+// Infof mocks base method
+func (m *MockLogger) Infof(format string, value ...interface{}) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{format}
+	for _, a := range value {
+		varargs = append(varargs, a)
+	}
+	m.ctrl.Call(m, "Infof", varargs...)
+}
+
+// Infof indicates an expected call of Infof
+func (mr *MockLoggerMockRecorder) Infof(format interface{}, value ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{format}, value...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Infof", reflect.TypeOf((*MockLogger)(nil).Infof), varargs...)
+}
+
+// Printf mocks base method
+func (m *MockLogger) Printf(format string, value ...interface{}) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{format}
+	for _, a := range value {
+		varargs = append(varargs, a)
+	}
+	m.ctrl.Call(m, "Printf", varargs...)
+}
+
+// Printf indicates an expected call of Printf
+func (mr *MockLoggerMockRecorder) Printf(format interface{}, value ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{format}, value...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Printf", reflect.TypeOf((*MockLogger)(nil).Printf), varargs...)
+}
+
+// Warnf mocks base method
+func (m *MockLogger) Warnf(format string, value ...interface{}) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{format}
+	for _, a := range value {
+		varargs = append(varargs, a)
+	}
+	m.ctrl.Call(m, "Warnf", varargs...)
+}
+
+// Warnf indicates an expected call of Warnf
+func (mr *MockLoggerMockRecorder) Warnf(format interface{}, value ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{format}, value...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Warnf", reflect.TypeOf((*MockLogger)(nil).Warnf), varargs...)
+}
+
+// Warningf mocks base method
+func (m *MockLogger) Warningf(format string, value ...interface{}) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{format}
+	for _, a := range value {
+		varargs = append(varargs, a)
+	}
+	m.ctrl.Call(m, "Warningf", varargs...)
+}
+
+// Warningf indicates an expected call of Warningf
+func (mr *MockLoggerMockRecorder) Warningf(format interface{}, value ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{format}, value...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Warningf", reflect.TypeOf((*MockLogger)(nil).Warningf), varargs...)
+}
+
+// Errorf mocks base method
+func (m *MockLogger) Errorf(format string, value ...interface{}) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{format}
+	for _, a := range value {
+		varargs = append(varargs, a)
+	}
+	m.ctrl.Call(m, "Errorf", varargs...)
+}
+
+// Errorf indicates an expected call of Errorf
+func (mr *MockLoggerMockRecorder) Errorf(format interface{}, value ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{format}, value...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Errorf", reflect.TypeOf((*MockLogger)(nil).Errorf), varargs...)
+}
+
+// Fatalf mocks base method
+func (m *MockLogger) Fatalf(format string, value ...interface{}) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{format}
+	for _, a := range value {
+		varargs = append(varargs, a)
+	}
+	m.ctrl.Call(m, "Fatalf", varargs...)
+}
+
+// Fatalf indicates an expected call of Fatalf
+func (mr *MockLoggerMockRecorder) Fatalf(format interface{}, value ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{format}, value...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Fatalf", reflect.TypeOf((*MockLogger)(nil).Fatalf), varargs...)
+}
+
+// Panicf mocks base method
+func (m *MockLogger) Panicf(format string, value ...interface{}) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{format}
+	for _, a := range value {
+		varargs = append(varargs, a)
+	}
+	m.ctrl.Call(m, "Panicf", varargs...)
+}
+
+// Panicf indicates an expected call of Panicf
+func (mr *MockLoggerMockRecorder) Panicf(format interface{}, value ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{format}, value...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Panicf", reflect.TypeOf((*MockLogger)(nil).Panicf), varargs...)
+}
+
+// Trace mocks base method
+func (m *MockLogger) Trace(value ...interface{}) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{}
+	for _, a := range value {
+		varargs = append(varargs, a)
+	}
+	m.ctrl.Call(m, "Trace", varargs...)
+}
+
+// Trace indicates an expected call of Trace
+func (mr *MockLoggerMockRecorder) Trace(value ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Trace", reflect.TypeOf((*MockLogger)(nil).Trace), value...)
+}
+
+// Debug mocks base method
+func (m *MockLogger) Debug(value ...interface{}) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{}
+	for _, a := range value {
+		varargs = append(varargs, a)
+	}
+	m.ctrl.Call(m, "Debug", varargs...)
+}
+
+// Debug indicates an expected call of Debug
+func (mr *MockLoggerMockRecorder) Debug(value ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Debug", reflect.TypeOf((*MockLogger)(nil).Debug), value...)
+}
+
+// Info mocks base method
+func (m *MockLogger) Info(value ...interface{}) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{}
+	for _, a := range value {
+		varargs = append(varargs, a)
+	}
+	m.ctrl.Call(m, "Info", varargs...)
+}
+
+// Info indicates an expected call of Info
+func (mr *MockLoggerMockRecorder) Info(value ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Info", reflect.TypeOf((*MockLogger)(nil).Info), value...)
+}
+
+// Print mocks base method
+func (m *MockLogger) Print(value ...interface{}) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{}
+	for _, a := range value {
+		varargs = append(varargs, a)
+	}
+	m.ctrl.Call(m, "Print", varargs...)
+}
+
+// Print indicates an expected call of Print
+func (mr *MockLoggerMockRecorder) Print(value ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Print", reflect.TypeOf((*MockLogger)(nil).Print), value...)
+}
+
+// Warn mocks base method
+func (m *MockLogger) Warn(value ...interface{}) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{}
+	for _, a := range value {
+		varargs = append(varargs, a)
+	}
+	m.ctrl.Call(m, "Warn", varargs...)
+}
+
+// Warn indicates an expected call of Warn
+func (mr *MockLoggerMockRecorder) Warn(value ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Warn", reflect.TypeOf((*MockLogger)(nil).Warn), value...)
+}
+
+// Warning mocks base method
+func (m *MockLogger) Warning(value ...interface{}) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{}
+	for _, a := range value {
+		varargs = append(varargs, a)
+	}
+	m.ctrl.Call(m, "Warning", varargs...)
+}
+
+// Warning indicates an expected call of Warning
+func (mr *MockLoggerMockRecorder) Warning(value ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Warning", reflect.TypeOf((*MockLogger)(nil).Warning), value...)
+}
+
+// Error mocks base method
+func (m *MockLogger) Error(value ...interface{}) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{}
+	for _, a := range value {
+		varargs = append(varargs, a)
+	}
+	m.ctrl.Call(m, "Error", varargs...)
+}
+
+// Error indicates an expected call of Error
+func (mr *MockLoggerMockRecorder) Error(value ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Error", reflect.TypeOf((*MockLogger)(nil).Error), value...)
+}
+
+// Fatal mocks base method
+func (m *MockLogger) Fatal(value ...interface{}) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{}
+	for _, a := range value {
+		varargs = append(varargs, a)
+	}
+	m.ctrl.Call(m, "Fatal", varargs...)
+}
+
+// Fatal indicates an expected call of Fatal
+func (mr *MockLoggerMockRecorder) Fatal(value ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Fatal", reflect.TypeOf((*MockLogger)(nil).Fatal), value...)
+}
+
+// Panic mocks base method
+func (m *MockLogger) Panic(value ...interface{}) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{}
+	for _, a := range value {
+		varargs = append(varargs, a)
+	}
+	m.ctrl.Call(m, "Panic", varargs...)
+}
+
+// Panic indicates an expected call of Panic
+func (mr *MockLoggerMockRecorder) Panic(value ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Panic", reflect.TypeOf((*MockLogger)(nil).Panic), value...)
+}
+
+// Traceln mocks base method
+func (m *MockLogger) Traceln(value ...interface{}) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{}
+	for _, a := range value {
+		varargs = append(varargs, a)
+	}
+	m.ctrl.Call(m, "Traceln", varargs...)
+}
+
+// Traceln indicates an expected call of Traceln
+func (mr *MockLoggerMockRecorder) Traceln(value ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Traceln", reflect.TypeOf((*MockLogger)(nil).Traceln), value...)
+}
+
+// Debugln mocks base method
+func (m *MockLogger) Debugln(value ...interface{}) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{}
+	for _, a := range value {
+		varargs = append(varargs, a)
+	}
+	m.ctrl.Call(m, "Debugln", varargs...)
+}
+
+// Debugln indicates an expected call of Debugln
+func (mr *MockLoggerMockRecorder) Debugln(value ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Debugln", reflect.TypeOf((*MockLogger)(nil).Debugln), value...)
+}
+
+// Infoln mocks base method
+func (m *MockLogger) Infoln(value ...interface{}) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{}
+	for _, a := range value {
+		varargs = append(varargs, a)
+	}
+	m.ctrl.Call(m, "Infoln", varargs...)
+}
+
+// Infoln indicates an expected call of Infoln
+func (mr *MockLoggerMockRecorder) Infoln(value ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Infoln", reflect.TypeOf((*MockLogger)(nil).Infoln), value...)
+}
+
+// Println mocks base method
+func (m *MockLogger) Println(value ...interface{}) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{}
+	for _, a := range value {
+		varargs = append(varargs, a)
+	}
+	m.ctrl.Call(m, "Println", varargs...)
+}
+
+// Println indicates an expected call of Println
+func (mr *MockLoggerMockRecorder) Println(value ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Println", reflect.TypeOf((*MockLogger)(nil).Println), value...)
+}
+
+// Warnln mocks base method
+func (m *MockLogger) Warnln(value ...interface{}) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{}
+	for _, a := range value {
+		varargs = append(varargs, a)
+	}
+	m.ctrl.Call(m, "Warnln", varargs...)
+}
+
+// Warnln indicates an expected call of Warnln
+func (mr *MockLoggerMockRecorder) Warnln(value ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Warnln", reflect.TypeOf((*MockLogger)(nil).Warnln), value...)
+}
+
+// Warningln mocks base method
+func (m *MockLogger) Warningln(value ...interface{}) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{}
+	for _, a := range value {
+		varargs = append(varargs, a)
+	}
+	m.ctrl.Call(m, "Warningln", varargs...)
+}
+
+// Warningln indicates an expected call of Warningln
+func (mr *MockLoggerMockRecorder) Warningln(value ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Warningln", reflect.TypeOf((*MockLogger)(nil).Warningln), value...)
+}
+
+// Errorln mocks base method
+func (m *MockLogger) Errorln(value ...interface{}) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{}
+	for _, a := range value {
+		varargs = append(varargs, a)
+	}
+	m.ctrl.Call(m, "Errorln", varargs...)
+}
+
+// Errorln indicates an expected call of Errorln
+func (mr *MockLoggerMockRecorder) Errorln(value ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Errorln", reflect.TypeOf((*MockLogger)(nil).Errorln), value...)
+}
+
+// Fatalln mocks base method
+func (m *MockLogger) Fatalln(value ...interface{}) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{}
+	for _, a := range value {
+		varargs = append(varargs, a)
+	}
+	m.ctrl.Call(m, "Fatalln", varargs...)
+}
+
+// Fatalln indicates an expected call of Fatalln
+func (mr *MockLoggerMockRecorder) Fatalln(value ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Fatalln", reflect.TypeOf((*MockLogger)(nil).Fatalln), value...)
+}
+
+// Panicln mocks base method
+func (m *MockLogger) Panicln(value ...interface{}) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{}
+	for _, a := range value {
+		varargs = append(varargs, a)
+	}
+	m.ctrl.Call(m, "Panicln", varargs...)
+}
+
+// Panicln indicates an expected call of Panicln
+func (mr *MockLoggerMockRecorder) Panicln(value ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Panicln", reflect.TypeOf((*MockLogger)(nil).Panicln), value...)
+}
+
+// MockConfig is a mock of Config interface
+type MockConfig struct {
+	ctrl     *gomock.Controller
+	recorder *MockConfigMockRecorder
+}
+
+// MockConfigMockRecorder is the mock recorder for MockConfig
+type MockConfigMockRecorder struct {
+	mock *MockConfig
+}
+
+// NewMockConfig creates a new mock instance
+func NewMockConfig(ctrl *gomock.Controller) *MockConfig {
+	mock := &MockConfig{ctrl: ctrl}
+	mock.recorder = &MockConfigMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockConfig) EXPECT() *MockConfigMockRecorder {
+	return m.recorder
+}
+
+// SetLevelFor mocks base method
+func (m *MockConfig) SetLevelFor(name, lvl string, ttl time.Duration) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetLevelFor", name, lvl, ttl)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetLevelFor indicates an expected call of SetLevelFor
+func (mr *MockConfigMockRecorder) SetLevelFor(name, lvl, ttl interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetLevelFor", reflect.TypeOf((*MockConfig)(nil).SetLevelFor), name, lvl, ttl)
+}
+
+// Levels mocks base method
+func (m *MockConfig) Levels() logging.LogLevels {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Levels")
+	ret0, _ := ret[0].(logging.LogLevels)
+	return ret0
+}
+
+// Levels indicates an expected call of Levels
+func (mr *MockConfigMockRecorder) Levels() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Levels", reflect.TypeOf((*MockConfig)(nil).Levels))
+}
+
+// SetSampling mocks base method
+func (m *MockConfig) SetSampling(config logging.SamplingConfig) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetSampling", config)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetSampling indicates an expected call of SetSampling
+func (mr *MockConfigMockRecorder) SetSampling(config interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetSampling", reflect.TypeOf((*MockConfig)(nil).SetSampling), config)
+}
+
+// SetErrorReporting mocks base method
+func (m *MockConfig) SetErrorReporting(config logging.ErrorReportingConfig) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetErrorReporting", config)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetErrorReporting indicates an expected call of SetErrorReporting
+func (mr *MockConfigMockRecorder) SetErrorReporting(config interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetErrorReporting", reflect.TypeOf((*MockConfig)(nil).SetErrorReporting), config)
+}
+
+// SetSpanLogging mocks base method
+func (m *MockConfig) SetSpanLogging(config logging.SpanLoggingConfig) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetSpanLogging", config)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetSpanLogging indicates an expected call of SetSpanLogging
+func (mr *MockConfigMockRecorder) SetSpanLogging(config interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetSpanLogging", reflect.TypeOf((*MockConfig)(nil).SetSpanLogging), config)
+}
+
+// SetAsync mocks base method
+func (m *MockConfig) SetAsync(config logging.AsyncConfig) (*logging.AsyncWriter, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetAsync", config)
+	ret0, _ := ret[0].(*logging.AsyncWriter)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetAsync indicates an expected call of SetAsync
+func (mr *MockConfigMockRecorder) SetAsync(config interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetAsync", reflect.TypeOf((*MockConfig)(nil).SetAsync), config)
+}
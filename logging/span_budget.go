@@ -0,0 +1,210 @@
+package logging
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/inclusi-blog/gola-utils/constants"
+	"github.com/sirupsen/logrus"
+	"go.opencensus.io/trace"
+)
+
+const (
+	SuppressedAnnotationsField = "suppressed_annotations"
+
+	spanBudgetTTL          = 10 * time.Minute
+	spanBudgetPruneEvery   = 1024
+	suppressedLevelPrefix  = "suppressed_"
+	summaryAnnotationLevel = "summary"
+)
+
+// SpanLoggingConfig controls the log annotations of spans. Level is the span-logging
+// level, empty follows the level of the logger. Every span keeps MaxAnnotations
+// annotations. OtherAnnotations of them are left to annotations not made by the logger,
+// like request and response bodies or HTTP client events, which can not be counted
+// while the span runs. Of the log annotations, the last ReservedAnnotations are kept for
+// warn and above, info and below are counted instead. Warn and above are always
+// annotated. Zero values fall back to TRACE_CONFIG_MAX_ANNOTATIONS and a quarter of it.
+type SpanLoggingConfig struct {
+	Level               string `json:"level"`
+	MaxAnnotations      int    `json:"maxAnnotations"`
+	ReservedAnnotations int    `json:"reservedAnnotations"`
+	OtherAnnotations    int    `json:"otherAnnotations"`
+}
+
+type spanLogging struct {
+	level               *logrus.Level
+	maxAnnotations      int
+	reservedAnnotations int
+}
+
+type spanKey struct {
+	traceID trace.TraceID
+	spanID  trace.SpanID
+}
+
+type spanBudget struct {
+	mutex      sync.Mutex
+	annotated  int
+	suppressed map[logrus.Level]int64
+	createdAt  time.Time
+}
+
+// spanLoggings holds the span logging of each logrus logger or backend.
+var spanLoggings sync.Map
+
+// spanBudgets holds the budget of each span that got log annotations. Budgets are
+// removed when the span is exported through NewAnnotationSummaryExporter, or after
+// spanBudgetTTL.
+var spanBudgets sync.Map
+
+var spanBudgetCount int64
+
+var defaultSpanLogging, _ = newSpanLogging(SpanLoggingConfig{})
+
+func newSpanLogging(config SpanLoggingConfig) (*spanLogging, error) {
+	if config.MaxAnnotations < 0 || config.ReservedAnnotations < 0 || config.OtherAnnotations < 0 {
+		return nil, errors.New("span annotation budget must not be negative")
+	}
+	if config.MaxAnnotations == 0 {
+		config.MaxAnnotations = constants.TRACE_CONFIG_MAX_ANNOTATIONS
+	}
+	if config.ReservedAnnotations == 0 {
+		config.ReservedAnnotations = config.MaxAnnotations / 4
+	}
+	if config.OtherAnnotations == 0 {
+		config.OtherAnnotations = config.MaxAnnotations / 4
+	}
+	if config.ReservedAnnotations >= config.MaxAnnotations {
+		return nil, errors.New("reserved span annotations must be less than the maximum")
+	}
+	if config.ReservedAnnotations+config.OtherAnnotations >= config.MaxAnnotations {
+		return nil, errors.New("reserved and other span annotations must be less than the maximum")
+	}
+	logging := &spanLogging{maxAnnotations: config.MaxAnnotations - config.OtherAnnotations, reservedAnnotations: config.ReservedAnnotations}
+	if config.Level != "" {
+		level, err := parseLevel(config.Level)
+		if err != nil {
+			return nil, err
+		}
+		logging.level = &level
+	}
+	return logging, nil
+}
+
+// SetSpanLogging applies the config to every entry of the underlying logrus logger or
+// backend.
+func (c loggerConfig) SetSpanLogging(config SpanLoggingConfig) error {
+	logging, err := newSpanLogging(config)
+	if err != nil {
+		return err
+	}
+	spanLoggings.Store(c.root.levelGate(), logging)
+	return nil
+}
+
+func (l golaLoggerEntry) spanLogging() *spanLogging {
+	if logging, ok := spanLoggings.Load(l.levelGate()); ok {
+		return logging.(*spanLogging)
+	}
+	return defaultSpanLogging
+}
+
+func (l golaLoggerEntry) isSpanLevelEnabled(level logrus.Level) bool {
+	if spanLevel := l.spanLogging().level; spanLevel != nil {
+		return level <= *spanLevel
+	}
+	return l.isLevelEnabled(level)
+}
+
+// allowAnnotation reports whether the span has budget left for an entry of level, and
+// counts the entry as suppressed when it has not.
+func (s *spanLogging) allowAnnotation(span *trace.Span, level logrus.Level) bool {
+	spanContext := span.SpanContext()
+	key := spanKey{traceID: spanContext.TraceID, spanID: spanContext.SpanID}
+	budget, ok := spanBudgets.Load(key)
+	if !ok {
+		budget, ok = spanBudgets.LoadOrStore(key, &spanBudget{suppressed: make(map[logrus.Level]int64), createdAt: time.Now()})
+		if !ok && atomic.AddInt64(&spanBudgetCount, 1)%spanBudgetPruneEvery == 0 {
+			pruneSpanBudgets(time.Now())
+		}
+	}
+	return budget.(*spanBudget).allow(level, s.maxAnnotations, s.reservedAnnotations)
+}
+
+func (b *spanBudget) allow(level logrus.Level, maxAnnotations int, reservedAnnotations int) bool {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	// the last annotation is kept for the summary
+	limit := maxAnnotations - 1
+	if level > logrus.WarnLevel {
+		limit -= reservedAnnotations
+	}
+	if level <= logrus.WarnLevel || b.annotated < limit {
+		b.annotated++
+		return true
+	}
+	b.suppressed[level]++
+	return false
+}
+
+func pruneSpanBudgets(now time.Time) {
+	spanBudgets.Range(func(key, budget interface{}) bool {
+		if now.Sub(budget.(*spanBudget).createdAt) > spanBudgetTTL {
+			spanBudgets.Delete(key)
+		}
+		return true
+	})
+}
+
+type annotationSummaryExporter struct {
+	exporter trace.Exporter
+}
+
+// NewAnnotationSummaryExporter wraps exporter, so that spans whose log annotations ran
+// out of budget end with an annotation summarizing the suppressed entries per level.
+// Only one registered exporter should be wrapped.
+func NewAnnotationSummaryExporter(exporter trace.Exporter) trace.Exporter {
+	return &annotationSummaryExporter{exporter: exporter}
+}
+
+func (e *annotationSummaryExporter) ExportSpan(s *trace.SpanData) {
+	key := spanKey{traceID: s.TraceID, spanID: s.SpanID}
+	budget, ok := spanBudgets.Load(key)
+	if !ok {
+		e.exporter.ExportSpan(s)
+		return
+	}
+	spanBudgets.Delete(key)
+	summary, hasSuppressed := budget.(*spanBudget).summary(s.EndTime)
+	if !hasSuppressed {
+		e.exporter.ExportSpan(s)
+		return
+	}
+	summarized := *s
+	summarized.Annotations = append(append([]trace.Annotation(nil), s.Annotations...), summary)
+	e.exporter.ExportSpan(&summarized)
+}
+
+func (b *spanBudget) summary(at time.Time) (trace.Annotation, bool) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	var total int64
+	attributes := map[string]interface{}{"level": summaryAnnotationLevel}
+	for level, count := range b.suppressed {
+		attributes[suppressedLevelPrefix+level.String()] = count
+		total += count
+	}
+	if total == 0 {
+		return trace.Annotation{}, false
+	}
+	attributes[SuppressedAnnotationsField] = total
+	return trace.Annotation{
+		Time:       at,
+		Message:    fmt.Sprintf("suppressed %d log annotations, span annotation budget exhausted", total),
+		Attributes: attributes,
+	}, true
+}
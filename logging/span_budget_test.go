@@ -0,0 +1,133 @@
+package logging
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	logrusTest "github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/suite"
+	"go.opencensus.io/trace"
+)
+
+type SpanBudgetTestSuite struct {
+	suite.Suite
+	logger   *logrus.Logger
+	hook     *logrusTest.Hook
+	exporter *testExporter
+	summary  trace.Exporter
+	entry    *golaLoggerEntry
+	span     *trace.Span
+}
+
+func TestSpanBudgetTestSuite(t *testing.T) {
+	suite.Run(t, new(SpanBudgetTestSuite))
+}
+
+func (suite *SpanBudgetTestSuite) SetupTest() {
+	suite.logger, suite.hook = logrusTest.NewNullLogger()
+	suite.exporter = &testExporter{}
+	suite.summary = NewAnnotationSummaryExporter(suite.exporter)
+	trace.RegisterExporter(suite.summary)
+	ctx, span := trace.StartSpan(context.Background(), "test-span", trace.WithSampler(trace.AlwaysSample()))
+	suite.span = span
+	suite.entry = newLoggerEntry(ctx, suite.logger.WithContext(context.Background()))
+}
+
+func (suite *SpanBudgetTestSuite) TearDownTest() {
+	trace.UnregisterExporter(suite.summary)
+	spanLoggings.Delete(suite.logger)
+}
+
+func (suite *SpanBudgetTestSuite) annotationMessages() []string {
+	var messages []string
+	for _, annotation := range suite.exporter.SpanData.Annotations {
+		messages = append(messages, annotation.Message)
+	}
+	return messages
+}
+
+func (suite *SpanBudgetTestSuite) TestShouldSuppressInfoAnnotationsAndKeepWarnings() {
+	suite.Nil(configOf(suite.entry).SetSpanLogging(SpanLoggingConfig{MaxAnnotations: 5, ReservedAnnotations: 1, OtherAnnotations: 1}))
+
+	suite.entry.Info("first")
+	suite.entry.Info("second")
+	suite.entry.Info("third")
+	suite.entry.Error("failed")
+	suite.entry.Warn("retrying")
+	suite.entry.Info("fourth")
+	suite.span.End()
+
+	suite.Equal([]string{"first", "second", "failed", "retrying", "suppressed 2 log annotations, span annotation budget exhausted"}, suite.annotationMessages())
+	summary := suite.exporter.SpanData.Annotations[4].Attributes
+	suite.Equal(int64(2), summary[SuppressedAnnotationsField])
+	suite.Equal(int64(2), summary["suppressed_info"])
+	suite.Equal(6, len(suite.hook.AllEntries()))
+}
+
+func (suite *SpanBudgetTestSuite) TestShouldLeaveRoomForAnnotationsNotMadeByLogger() {
+	trace.ApplyConfig(trace.Config{MaxAnnotationEventsPerSpan: 6})
+	defer trace.ApplyConfig(trace.Config{MaxAnnotationEventsPerSpan: trace.DefaultMaxAnnotationEventsPerSpan})
+	ctx, span := trace.StartSpan(context.Background(), "request-span", trace.WithSampler(trace.AlwaysSample()))
+	entry := suite.entry.WithContext(ctx)
+	suite.Nil(configOf(suite.entry).SetSpanLogging(SpanLoggingConfig{MaxAnnotations: 6, ReservedAnnotations: 1, OtherAnnotations: 2}))
+
+	span.Annotate(nil, "request body")
+	for i := 0; i < 5; i++ {
+		entry.Info("query")
+	}
+	entry.Error("failed")
+	span.Annotate(nil, "response body")
+	span.End()
+
+	suite.Equal([]string{"request body", "query", "query", "failed", "response body", "suppressed 3 log annotations, span annotation budget exhausted"}, suite.annotationMessages())
+	suite.Equal(0, suite.exporter.SpanData.DroppedAnnotationCount)
+}
+
+func (suite *SpanBudgetTestSuite) TestShouldNotAddSummaryWithinBudget() {
+	suite.entry.Info("started")
+	suite.span.End()
+
+	suite.Equal([]string{"started"}, suite.annotationMessages())
+}
+
+func (suite *SpanBudgetTestSuite) TestShouldAnnotateSpanBelowLoggerLevel() {
+	suite.Nil(configOf(suite.entry).SetSpanLogging(SpanLoggingConfig{Level: DEBUG}))
+
+	suite.entry.Debug("query")
+	suite.span.End()
+
+	suite.Equal([]string{"query"}, suite.annotationMessages())
+	suite.Equal(0, len(suite.hook.AllEntries()))
+}
+
+func (suite *SpanBudgetTestSuite) TestShouldNotAnnotateSpanAboveSpanLevel() {
+	suite.Nil(configOf(suite.entry).SetSpanLogging(SpanLoggingConfig{Level: WARN}))
+
+	suite.entry.Info("started")
+	suite.span.End()
+
+	suite.Empty(suite.annotationMessages())
+	suite.Equal(1, len(suite.hook.AllEntries()))
+}
+
+func (suite *SpanBudgetTestSuite) TestShouldRejectInvalidSpanLoggingConfig() {
+	suite.EqualError(configOf(suite.entry).SetSpanLogging(SpanLoggingConfig{Level: "verbose"}), `not a valid golaLogger Level: "verbose"`)
+	suite.EqualError(configOf(suite.entry).SetSpanLogging(SpanLoggingConfig{MaxAnnotations: -1}), "span annotation budget must not be negative")
+	suite.EqualError(configOf(suite.entry).SetSpanLogging(SpanLoggingConfig{MaxAnnotations: 2, ReservedAnnotations: 2}), "reserved span annotations must be less than the maximum")
+	suite.EqualError(configOf(suite.entry).SetSpanLogging(SpanLoggingConfig{MaxAnnotations: 4, ReservedAnnotations: 2, OtherAnnotations: 2}), "reserved and other span annotations must be less than the maximum")
+}
+
+func (suite *SpanBudgetTestSuite) TestShouldPruneExpiredSpanBudgets() {
+	suite.entry.Info("started")
+	spanContext := suite.span.SpanContext()
+	key := spanKey{traceID: spanContext.TraceID, spanID: spanContext.SpanID}
+	_, ok := spanBudgets.Load(key)
+	suite.True(ok)
+
+	pruneSpanBudgets(time.Now().Add(spanBudgetTTL + time.Second))
+
+	_, ok = spanBudgets.Load(key)
+	suite.False(ok)
+}
@@ -5,8 +5,11 @@ type MaskFieldKeyType string
 const (
 	maskChar     = "*"
 	fallbackText = "*NA*"
+	RedactedText = "[REDACTED]"
 )
 
 const (
-	Email MaskFieldKeyType = "email"
+	Email  MaskFieldKeyType = "email"
+	Last4  MaskFieldKeyType = "last4"
+	Redact MaskFieldKeyType = "redact"
 )
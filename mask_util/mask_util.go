@@ -2,46 +2,77 @@ package mask_util
 
 import (
 	"context"
-	"github.com/inclusi-blog/gola-utils/logging"
+	"errors"
+	"fmt"
 	"strings"
+
+	"github.com/sirupsen/logrus"
 )
 
 func MaskEmail(ctx context.Context, value string) string {
 	return validateAndMaskField(ctx, Email, value)
 }
 
-func validateAndMaskField(ctx context.Context, key MaskFieldKeyType, value string) string {
-	log := logging.GetLogger(ctx).WithField("class", "mask_util").WithField("method", "validateAndMaskField")
+func MaskLast4(ctx context.Context, value string) string {
+	return validateAndMaskField(ctx, Last4, value)
+}
+
+// Mask masks value the same way as the context aware functions but leaves reporting
+// the error to the caller. The logging package relies on it, so mask_util must not
+// depend on logging.
+func Mask(key MaskFieldKeyType, value string) (string, error) {
 	if strings.TrimSpace(value) == "" {
-		log.Error("cannot mask empty string, so return fallback text")
-		return fallbackText
+		return fallbackText, errors.New("cannot mask empty string, so return fallback text")
 	}
 
 	switch key {
 	case Email:
-		return maskEmail(ctx, value)
+		return maskEmail(value)
+	case Last4:
+		return maskLast4(value), nil
+	case Redact:
+		return RedactedText, nil
 	default:
-		log.Warnf("There is no key found with the name of %v, so return fallback text", key)
-		return fallbackText
+		return fallbackText, fmt.Errorf("There is no key found with the name of %v, so return fallback text", key)
 	}
 }
 
-func maskString(ctx context.Context, targetString string, skipFromFirst, skipFromLast int) string {
-	log := logging.GetLogger(ctx).WithField("class", "mask_util").WithField("method", "maskString")
+func validateAndMaskField(ctx context.Context, key MaskFieldKeyType, value string) string {
+	masked, err := Mask(key, value)
+	if err != nil {
+		logrus.WithContext(ctx).WithField("class", "mask_util").WithField("method", "validateAndMaskField").Error(err)
+	}
+	return masked
+}
+
+func maskString(targetString string, skipFromFirst, skipFromLast int) (string, error) {
 	sumOfMaskCharLen := skipFromFirst + skipFromLast
 	if (sumOfMaskCharLen) > len(targetString) {
-		log.Error("error while masking string")
-		return fallbackText
+		return fallbackText, errors.New("error while masking string")
 	}
 	masked := targetString[:skipFromFirst] + strings.Repeat(maskChar, len(targetString)-(sumOfMaskCharLen)) + targetString[len(targetString)-skipFromLast:]
-	return masked
+	return masked, nil
 }
 
-func maskEmail(ctx context.Context, email string) string {
+func maskEmail(email string) (string, error) {
 	array := strings.Split(email, "@")
+	if len(array) != 2 || !strings.Contains(array[1], ".") {
+		return fallbackText, errors.New("cannot mask invalid email, so return fallback text")
+	}
 	domainArray := strings.Split(array[1], ".")
-	username := maskString(ctx, array[0], 1, 1)
-	mailSuffix := maskString(ctx, domainArray[0], 1, 1)
+	username, usernameErr := maskString(array[0], 1, 1)
+	mailSuffix, mailSuffixErr := maskString(domainArray[0], 1, 1)
 	maskedEmail := username + "@" + mailSuffix + "." + strings.Join(domainArray[1:], ".")
-	return maskedEmail
+	if usernameErr != nil {
+		return maskedEmail, usernameErr
+	}
+	return maskedEmail, mailSuffixErr
+}
+
+func maskLast4(value string) string {
+	runes := []rune(value)
+	if len(runes) <= 4 {
+		return strings.Repeat(maskChar, len(runes))
+	}
+	return strings.Repeat(maskChar, len(runes)-4) + string(runes[len(runes)-4:])
 }
@@ -47,3 +47,28 @@ func (suite *MaskUtilsTestSuite) TestMaskEmail_ShouldReturnErrorIfEmailValueIsEm
 
 	suite.Equal(fallbackText, actual)
 }
+
+func (suite *MaskUtilsTestSuite) TestMaskEmail_ShouldReturnFallbackIfEmailIsInvalid() {
+	actual := MaskEmail(suite.context, "gobinath")
+
+	suite.Equal(fallbackText, actual)
+}
+
+func (suite *MaskUtilsTestSuite) TestMaskLast4_ShouldRevealOnlyLastFourCharacters() {
+	suite.Equal("************1111", MaskLast4(suite.context, "4111111111111111"))
+	suite.Equal("***", MaskLast4(suite.context, "411"))
+}
+
+func (suite *MaskUtilsTestSuite) TestMask_ShouldReturnErrorForUnknownKey() {
+	actual, err := Mask("phone", "9876543210")
+
+	suite.Equal(fallbackText, actual)
+	suite.EqualError(err, "There is no key found with the name of phone, so return fallback text")
+}
+
+func (suite *MaskUtilsTestSuite) TestMask_ShouldRedactValue() {
+	actual, err := Mask(Redact, "eyJhbGciOiJIUzI1NiJ9")
+
+	suite.Nil(err)
+	suite.Equal(RedactedText, actual)
+}
@@ -0,0 +1,103 @@
+package middleware
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/inclusi-blog/gola-utils/constants"
+	"github.com/inclusi-blog/gola-utils/logging"
+	"github.com/inclusi-blog/gola-utils/model"
+)
+
+const (
+	MethodField        = "method"
+	RouteField         = "route"
+	PathField          = "path"
+	StatusField        = "status"
+	LatencyField       = "latency_ms"
+	RequestBytesField  = "request_bytes"
+	ResponseBytesField = "response_bytes"
+	UserAgentField     = "user_agent"
+	ErrorsField        = "errors"
+)
+
+// AccessLogMiddleware writes one entry per request through the gola logger, so it
+// should be registered after LoggingMiddleware to carry the trace ID of the request.
+// Requests are logged at info level, requests slower than the configured threshold at
+// warn level and requests failing with a 5xx status at error level. SkipPaths are
+// matched against both the route template and the request path. Log sampling counts
+// entries per method, route template and status class, so busy routes do not crowd out
+// the others.
+func AccessLogMiddleware(config model.AccessLogConfig) gin.HandlerFunc {
+	skipPaths := make(map[string]bool, len(config.SkipPaths))
+	for _, path := range config.SkipPaths {
+		skipPaths[path] = true
+	}
+	slowRequestThreshold := time.Duration(config.SlowRequestThresholdInMillis) * time.Millisecond
+
+	return func(c *gin.Context) {
+		startedAt := time.Now()
+		c.Next()
+
+		route := c.FullPath()
+		if skipPaths[route] || skipPaths[c.Request.URL.Path] {
+			return
+		}
+		latency := time.Since(startedAt)
+		status := c.Writer.Status()
+
+		logger := logging.GetLogger(c).WithFields(logging.GolaFields{
+			MethodField:                        c.Request.Method,
+			RouteField:                         route,
+			PathField:                          c.Request.URL.Path,
+			StatusField:                        status,
+			LatencyField:                       float64(latency) / float64(time.Millisecond),
+			RequestBytesField:                  nonNegative(c.Request.ContentLength),
+			ResponseBytesField:                 nonNegative(int64(c.Writer.Size())),
+			constants.TRACING_CLIENT_PUBLIC_IP: clientIP(c),
+			UserAgentField:                     c.Request.UserAgent(),
+			constants.TRACE_KEY:                traceID(c),
+			constants.TRACING_SESSION_ID:       c.Request.Header.Get(constants.TRACING_SESSION_HEADER_KEY),
+			logging.SamplingKeyField:           fmt.Sprintf("%s %s %dxx", c.Request.Method, route, status/100),
+		})
+		if len(c.Errors) > 0 {
+			logger = logger.WithField(ErrorsField, c.Errors.String())
+		}
+
+		switch {
+		case status >= 500:
+			logger.Errorf("%s %s %d %s", c.Request.Method, c.Request.URL.Path, status, latency)
+		case slowRequestThreshold > 0 && latency >= slowRequestThreshold:
+			logger.Warnf("%s %s %d %s slow request", c.Request.Method, c.Request.URL.Path, status, latency)
+		default:
+			logger.Infof("%s %s %d %s", c.Request.Method, c.Request.URL.Path, status, latency)
+		}
+	}
+}
+
+// clientIP prefers the first address of X-Original-Forwarded-For set by the ingress.
+func clientIP(c *gin.Context) string {
+	if forwardedFor := c.Request.Header.Get(constants.TRACING_CLIENT_PUBLIC_IP_HEADER); forwardedFor != "" {
+		return strings.TrimSpace(strings.Split(forwardedFor, ",")[0])
+	}
+	return c.ClientIP()
+}
+
+func traceID(c *gin.Context) string {
+	if traceID := c.GetString(constants.TRACE_KEY); traceID != "" {
+		return traceID
+	}
+	if traceID := c.Request.Header.Get(constants.TRACE_ID_HTTP_HEADER); traceID != "" {
+		return traceID
+	}
+	return constants.NO_TRACE_ID
+}
+
+func nonNegative(size int64) int64 {
+	if size < 0 {
+		return 0
+	}
+	return size
+}
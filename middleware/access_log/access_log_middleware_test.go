@@ -0,0 +1,144 @@
+package middleware
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/inclusi-blog/gola-utils/constants"
+	"github.com/inclusi-blog/gola-utils/logging"
+	"github.com/inclusi-blog/gola-utils/model"
+	"github.com/sirupsen/logrus"
+	logrusTest "github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/suite"
+)
+
+type AccessLogMiddlewareTest struct {
+	suite.Suite
+	hook *logrusTest.Hook
+}
+
+func TestAccessLogMiddlewareTestSuite(t *testing.T) {
+	suite.Run(t, new(AccessLogMiddlewareTest))
+}
+
+func (suite *AccessLogMiddlewareTest) SetupSuite() {
+	suite.hook = logrusTest.NewLocal(logrus.StandardLogger())
+}
+
+func (suite *AccessLogMiddlewareTest) SetupTest() {
+	suite.hook.Reset()
+}
+
+func (suite *AccessLogMiddlewareTest) engine(config model.AccessLogConfig, handler gin.HandlerFunc) *gin.Engine {
+	engine := gin.New()
+	engine.Use(logging.LoggingMiddleware(logging.NewLoggerEntry()), AccessLogMiddleware(config))
+	engine.POST("/api/users/:id", handler)
+	engine.GET("/health", handler)
+	return engine
+}
+
+func (suite *AccessLogMiddlewareTest) TestShouldLogRequest() {
+	engine := suite.engine(model.AccessLogConfig{}, func(c *gin.Context) {
+		c.String(http.StatusCreated, "created")
+	})
+	request, _ := http.NewRequest("POST", "/api/users/42", bytes.NewBufferString(`{"name":"abc"}`))
+	request.Header.Set(constants.TRACE_ID_HTTP_HEADER, "trace-1")
+	request.Header.Set(constants.TRACING_SESSION_HEADER_KEY, "session-1")
+	request.Header.Set(constants.TRACING_CLIENT_PUBLIC_IP_HEADER, "203.0.113.7, 10.0.0.1")
+	request.Header.Set("User-Agent", "gola-test")
+
+	engine.ServeHTTP(httptest.NewRecorder(), request)
+
+	entry := suite.hook.LastEntry()
+	suite.Equal(1, len(suite.hook.AllEntries()))
+	suite.Equal(logrus.InfoLevel, entry.Level)
+	suite.Equal("POST", entry.Data[MethodField])
+	suite.Equal("/api/users/:id", entry.Data[RouteField])
+	suite.Equal("/api/users/42", entry.Data[PathField])
+	suite.Equal(http.StatusCreated, entry.Data[StatusField])
+	suite.Equal(int64(14), entry.Data[RequestBytesField])
+	suite.Equal(int64(7), entry.Data[ResponseBytesField])
+	suite.Equal("203.0.113.7", entry.Data[constants.TRACING_CLIENT_PUBLIC_IP])
+	suite.Equal("gola-test", entry.Data[UserAgentField])
+	suite.Equal("trace-1", entry.Data[constants.TRACE_KEY])
+	suite.Equal("session-1", entry.Data[constants.TRACING_SESSION_ID])
+	suite.Equal("POST /api/users/:id 2xx", entry.Data[logging.SamplingKeyField])
+	suite.Contains(entry.Data, LatencyField)
+}
+
+func (suite *AccessLogMiddlewareTest) TestShouldSampleRequestsPerRouteAndStatusClass() {
+	suite.Nil(logging.DefaultConfig().SetSampling(logging.SamplingConfig{Levels: map[string]logging.LevelSampling{logging.INFO: {First: 1}}}))
+	defer func() { suite.Nil(logging.DefaultConfig().SetSampling(logging.SamplingConfig{})) }()
+	engine := suite.engine(model.AccessLogConfig{}, func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	for _, path := range []string{"/api/users/1", "/api/users/2"} {
+		request, _ := http.NewRequest(http.MethodPost, path, nil)
+		engine.ServeHTTP(httptest.NewRecorder(), request)
+	}
+	for i := 0; i < 2; i++ {
+		request, _ := http.NewRequest(http.MethodGet, "/health", nil)
+		engine.ServeHTTP(httptest.NewRecorder(), request)
+	}
+
+	var routes []interface{}
+	for _, entry := range suite.hook.AllEntries() {
+		routes = append(routes, entry.Data[RouteField])
+	}
+	suite.Equal([]interface{}{"/api/users/:id", "/health"}, routes)
+}
+
+func (suite *AccessLogMiddlewareTest) TestShouldSkipConfiguredPaths() {
+	engine := suite.engine(model.AccessLogConfig{SkipPaths: []string{"/health"}}, func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+	request, _ := http.NewRequest("GET", "/health", nil)
+
+	engine.ServeHTTP(httptest.NewRecorder(), request)
+
+	suite.Equal(0, len(suite.hook.AllEntries()))
+}
+
+func (suite *AccessLogMiddlewareTest) TestShouldLogSlowRequestAsWarning() {
+	engine := suite.engine(model.AccessLogConfig{SlowRequestThresholdInMillis: 5}, func(c *gin.Context) {
+		time.Sleep(10 * time.Millisecond)
+		c.Status(http.StatusOK)
+	})
+	request, _ := http.NewRequest("GET", "/health", nil)
+	recorder := httptest.NewRecorder()
+
+	engine.ServeHTTP(recorder, request)
+
+	suite.Equal(logrus.WarnLevel, suite.hook.LastEntry().Level)
+	suite.Equal(recorder.Header().Get(constants.TRACE_ID_HTTP_HEADER), suite.hook.LastEntry().Data[constants.TRACE_KEY])
+}
+
+func (suite *AccessLogMiddlewareTest) TestShouldLogServerErrorsAsErrorWithGinErrors() {
+	engine := suite.engine(model.AccessLogConfig{}, func(c *gin.Context) {
+		_ = c.AbortWithError(http.StatusInternalServerError, errors.New("db unavailable"))
+	})
+	request, _ := http.NewRequest("GET", "/health", nil)
+
+	engine.ServeHTTP(httptest.NewRecorder(), request)
+
+	suite.Equal(logrus.ErrorLevel, suite.hook.LastEntry().Level)
+	suite.Equal("Error #01: db unavailable\n", suite.hook.LastEntry().Data[ErrorsField])
+}
+
+func (suite *AccessLogMiddlewareTest) TestShouldFallBackToClientIPWithoutForwardedHeader() {
+	engine := suite.engine(model.AccessLogConfig{}, func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+	request, _ := http.NewRequest("GET", "/health", nil)
+	request.RemoteAddr = "192.0.2.1:1234"
+
+	engine.ServeHTTP(httptest.NewRecorder(), request)
+
+	suite.Equal("192.0.2.1", suite.hook.LastEntry().Data[constants.TRACING_CLIENT_PUBLIC_IP])
+}
@@ -0,0 +1,45 @@
+package middleware
+
+import (
+	"context"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/inclusi-blog/gola-utils/constants"
+	"github.com/inclusi-blog/gola-utils/http/util"
+	"github.com/inclusi-blog/gola-utils/logging"
+	"github.com/inclusi-blog/gola-utils/model"
+)
+
+// DeadlineMiddleware sets a deadline on the incoming request context from the
+// X-Request-Timeout header sent by the caller, falling back to the configured default.
+// The budget is capped at MaxTimeoutInMillis when it is set. Calls made through
+// HttpRequest with this context forward the remaining budget further down the chain.
+func DeadlineMiddleware(config model.DeadlineConfig) gin.HandlerFunc {
+	defaultTimeout := time.Duration(config.DefaultTimeoutInMillis) * time.Millisecond
+	maxTimeout := time.Duration(config.MaxTimeoutInMillis) * time.Millisecond
+
+	return func(c *gin.Context) {
+		timeout, hasTimeout := defaultTimeout, defaultTimeout > 0
+		if encodedTimeout := c.Request.Header.Get(constants.REQUEST_TIMEOUT_HTTP_HEADER); encodedTimeout != "" {
+			requestedTimeout, err := util.DecodeTimeout(encodedTimeout)
+			if err != nil {
+				logging.GetLogger(c).Warnf("deadline middleware - ignoring invalid %s header: %v", constants.REQUEST_TIMEOUT_HTTP_HEADER, err)
+			} else {
+				timeout, hasTimeout = requestedTimeout, true
+			}
+		}
+		if maxTimeout > 0 && (!hasTimeout || timeout > maxTimeout) {
+			timeout, hasTimeout = maxTimeout, true
+		}
+		if !hasTimeout {
+			c.Next()
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), timeout)
+		defer cancel()
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+	}
+}
@@ -0,0 +1,77 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/inclusi-blog/gola-utils/constants"
+	"github.com/inclusi-blog/gola-utils/model"
+	"github.com/stretchr/testify/suite"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+type DeadlineMiddlewareTest struct {
+	suite.Suite
+	recorder *httptest.ResponseRecorder
+}
+
+func TestDeadlineMiddlewareTestSuite(t *testing.T) {
+	suite.Run(t, new(DeadlineMiddlewareTest))
+}
+
+func (suite *DeadlineMiddlewareTest) SetupTest() {
+	suite.recorder = httptest.NewRecorder()
+}
+
+func (suite *DeadlineMiddlewareTest) serve(config model.DeadlineConfig, encodedTimeout string) (time.Duration, bool) {
+	var remaining time.Duration
+	var hasDeadline bool
+	engine := gin.New()
+	engine.GET("/api/resource", DeadlineMiddleware(config), func(c *gin.Context) {
+		var deadline time.Time
+		deadline, hasDeadline = c.Request.Context().Deadline()
+		remaining = time.Until(deadline)
+		c.Status(http.StatusOK)
+	})
+	request, _ := http.NewRequest("GET", "/api/resource", nil)
+	if encodedTimeout != "" {
+		request.Header.Set(constants.REQUEST_TIMEOUT_HTTP_HEADER, encodedTimeout)
+	}
+	engine.ServeHTTP(suite.recorder, request)
+	return remaining, hasDeadline
+}
+
+func (suite *DeadlineMiddlewareTest) TestShouldSetDeadlineFromRequestHeader() {
+	remaining, hasDeadline := suite.serve(model.DeadlineConfig{}, "2S")
+
+	suite.True(hasDeadline)
+	suite.True(remaining > time.Second && remaining <= 2*time.Second)
+}
+
+func (suite *DeadlineMiddlewareTest) TestShouldNotSetDeadlineWithoutHeaderOrDefault() {
+	_, hasDeadline := suite.serve(model.DeadlineConfig{}, "")
+
+	suite.False(hasDeadline)
+}
+
+func (suite *DeadlineMiddlewareTest) TestShouldUseDefaultTimeoutWhenHeaderIsMissingOrInvalid() {
+	remaining, hasDeadline := suite.serve(model.DeadlineConfig{DefaultTimeoutInMillis: 500}, "soon")
+
+	suite.True(hasDeadline)
+	suite.True(remaining > 0 && remaining <= 500*time.Millisecond)
+}
+
+func (suite *DeadlineMiddlewareTest) TestShouldCapRequestedTimeoutAtMaximum() {
+	remaining, hasDeadline := suite.serve(model.DeadlineConfig{MaxTimeoutInMillis: 1000}, "5M")
+
+	suite.True(hasDeadline)
+	suite.True(remaining > 0 && remaining <= time.Second)
+}
+
+func (suite *DeadlineMiddlewareTest) TestShouldExpireImmediatelyWhenNoBudgetIsLeft() {
+	remaining, hasDeadline := suite.serve(model.DeadlineConfig{}, "0n")
+
+	suite.True(hasDeadline)
+	suite.True(remaining <= 0)
+}
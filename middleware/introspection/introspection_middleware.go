@@ -9,6 +9,7 @@ import (
 	"github.com/inclusi-blog/gola-utils/middleware/introspection/oauth-middleware/http"
 	"github.com/inclusi-blog/gola-utils/middleware/introspection/oauth-middleware/service"
 	oauthUtils "github.com/inclusi-blog/gola-utils/oauth"
+	"github.com/inclusi-blog/gola-utils/tracing/propagation"
 	"go.opencensus.io/plugin/ochttp"
 	"net"
 	gohttp "net/http"
@@ -40,7 +41,7 @@ func NewIntrospectionAndDecryptionMiddleware(protectedUrlService service.Protect
 		}).DialContext,
 	}
 
-	httpClient := &gohttp.Client{Transport: &ochttp.Transport{Base: transport}}
+	httpClient := &gohttp.Client{Transport: &ochttp.Transport{Base: transport, Propagation: propagation.Default()}}
 	httpRequestBuilder := request.NewHttpRequestBuilder(httpClient)
 
 	return introspectionMiddleware{
@@ -0,0 +1,115 @@
+package middleware
+
+import (
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/inclusi-blog/gola-utils/constants"
+	"github.com/inclusi-blog/gola-utils/logging"
+	"github.com/inclusi-blog/gola-utils/mask_util"
+	oauthConstants "github.com/inclusi-blog/gola-utils/middleware/introspection/oauth-middleware/constants"
+	"github.com/inclusi-blog/gola-utils/model"
+)
+
+const (
+	UserIdField  = "user_id"
+	EmailField   = "email"
+	SubjectField = "subject"
+)
+
+// DefaultLogEnrichmentFields is used when no fields are configured.
+var DefaultLogEnrichmentFields = []string{
+	UserIdField,
+	EmailField,
+	SubjectField,
+	constants.TRACING_SESSION_ID,
+	constants.TRACING_APP_VERSION,
+	constants.TRACING_DEVICE_INFO,
+}
+
+type fieldExtractor func(c *gin.Context, idToken *model.IdToken) string
+
+var fieldExtractors = map[string]fieldExtractor{
+	UserIdField: func(_ *gin.Context, idToken *model.IdToken) string {
+		return idTokenValue(idToken, func(token *model.IdToken) string { return token.UserId })
+	},
+	EmailField: func(c *gin.Context, idToken *model.IdToken) string {
+		email := idTokenValue(idToken, func(token *model.IdToken) string { return token.Email })
+		if email == "" {
+			return ""
+		}
+		return mask_util.MaskEmail(c, email)
+	},
+	SubjectField: func(_ *gin.Context, idToken *model.IdToken) string {
+		return idTokenValue(idToken, func(token *model.IdToken) string { return token.Subject })
+	},
+	constants.TRACING_SESSION_ID:  headerExtractor(constants.TRACING_SESSION_HEADER_KEY),
+	constants.TRACING_APP_VERSION: headerExtractor(constants.TRACING_APP_VERSION_HEADER_KEY),
+	constants.TRACING_DEVICE_INFO: headerExtractor(constants.TRACING_DEVICE_INFO_HEADER_KEY),
+	constants.TRACING_CLIENT_PUBLIC_IP: func(c *gin.Context, _ *model.IdToken) string {
+		return strings.TrimSpace(strings.Split(c.Request.Header.Get(constants.TRACING_CLIENT_PUBLIC_IP_HEADER), ",")[0])
+	},
+}
+
+// LogEnrichmentMiddleware attaches identity, session and device fields to the logger
+// stored by LoggingMiddleware. Register it after the middleware decrypting the id
+// token (DecryptIdToken or the introspection middleware) so that the identity fields
+// are available. The email is always masked, fields without a value are left out.
+func LogEnrichmentMiddleware(config model.LogEnrichmentConfig) gin.HandlerFunc {
+	fields := config.Fields
+	if len(fields) == 0 {
+		fields = DefaultLogEnrichmentFields
+	}
+	extractors := make(map[string]fieldExtractor, len(fields))
+	for _, field := range fields {
+		extractor, ok := fieldExtractors[field]
+		if !ok {
+			logging.NewLoggerEntry().Warnf("log enrichment middleware - ignoring unknown field %q", field)
+			continue
+		}
+		extractors[field] = extractor
+	}
+
+	return func(c *gin.Context) {
+		idToken := idTokenFrom(c)
+		enrichedFields := make(logging.GolaFields, len(extractors))
+		for field, extractor := range extractors {
+			if value := extractor(c, idToken); value != "" {
+				enrichedFields[field] = value
+			}
+		}
+		if len(enrichedFields) > 0 {
+			logging.EnrichLogger(c, enrichedFields)
+		}
+		c.Next()
+	}
+}
+
+func idTokenFrom(c *gin.Context) *model.IdToken {
+	for _, key := range []string{constants.CONTEXT_ENC_ID_TOKEN, oauthConstants.ContextDecryptedIdTokenKey} {
+		value, ok := c.Get(key)
+		if !ok {
+			continue
+		}
+		switch idToken := value.(type) {
+		case model.IdToken:
+			return &idToken
+		case *model.IdToken:
+			return idToken
+		}
+	}
+	return nil
+}
+
+func idTokenValue(idToken *model.IdToken, value func(token *model.IdToken) string) string {
+	if idToken == nil {
+		return ""
+	}
+	return value(idToken)
+}
+
+func headerExtractor(header string) fieldExtractor {
+	return func(c *gin.Context, _ *model.IdToken) string {
+		return c.Request.Header.Get(header)
+	}
+}
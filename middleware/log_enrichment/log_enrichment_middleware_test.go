@@ -0,0 +1,87 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/inclusi-blog/gola-utils/constants"
+	"github.com/inclusi-blog/gola-utils/logging"
+	oauthConstants "github.com/inclusi-blog/gola-utils/middleware/introspection/oauth-middleware/constants"
+	"github.com/inclusi-blog/gola-utils/model"
+	"github.com/stretchr/testify/suite"
+)
+
+type LogEnrichmentMiddlewareTest struct {
+	suite.Suite
+	idToken model.IdToken
+}
+
+func TestLogEnrichmentMiddlewareTestSuite(t *testing.T) {
+	suite.Run(t, new(LogEnrichmentMiddlewareTest))
+}
+
+func (suite *LogEnrichmentMiddlewareTest) SetupTest() {
+	suite.idToken = model.IdToken{UserId: "42", Username: "gobinath", Email: "gobinath@yahoo.co.in", Subject: "sub-42"}
+}
+
+func (suite *LogEnrichmentMiddlewareTest) serve(config model.LogEnrichmentConfig, setIdToken gin.HandlerFunc, request *http.Request) logging.GolaFields {
+	var fields logging.GolaFields
+	engine := gin.New()
+	engine.Use(logging.LoggingMiddleware(logging.NewLoggerEntry()), setIdToken, LogEnrichmentMiddleware(config))
+	engine.GET("/api/users", func(c *gin.Context) {
+		fields = logging.GetLogger(c).Fields()
+		c.Status(http.StatusOK)
+	})
+	engine.ServeHTTP(httptest.NewRecorder(), request)
+	return fields
+}
+
+func (suite *LogEnrichmentMiddlewareTest) TestShouldEnrichLoggerWithDefaultFields() {
+	request, _ := http.NewRequest("GET", "/api/users", nil)
+	request.Header.Set(constants.TRACE_ID_HTTP_HEADER, "trace-1")
+	request.Header.Set(constants.TRACING_SESSION_HEADER_KEY, "session-1")
+	request.Header.Set(constants.TRACING_APP_VERSION_HEADER_KEY, "1.2.0")
+	request.Header.Set(constants.TRACING_DEVICE_INFO_HEADER_KEY, "android")
+
+	fields := suite.serve(model.LogEnrichmentConfig{}, func(c *gin.Context) {
+		c.Set(constants.CONTEXT_ENC_ID_TOKEN, suite.idToken)
+	}, request)
+
+	suite.Equal(logging.GolaFields{
+		constants.TRACE_KEY:           "trace-1",
+		UserIdField:                   "42",
+		EmailField:                    "g******h@y***o.co.in",
+		SubjectField:                  "sub-42",
+		constants.TRACING_SESSION_ID:  "session-1",
+		constants.TRACING_APP_VERSION: "1.2.0",
+		constants.TRACING_DEVICE_INFO: "android",
+	}, fields)
+}
+
+func (suite *LogEnrichmentMiddlewareTest) TestShouldUseIdTokenDecryptedByIntrospectionAndSelectedFields() {
+	request, _ := http.NewRequest("GET", "/api/users", nil)
+	request.Header.Set(constants.TRACE_ID_HTTP_HEADER, "trace-1")
+	request.Header.Set(constants.TRACING_CLIENT_PUBLIC_IP_HEADER, "203.0.113.7, 10.0.0.1")
+	request.Header.Set(constants.TRACING_SESSION_HEADER_KEY, "session-1")
+
+	fields := suite.serve(model.LogEnrichmentConfig{Fields: []string{UserIdField, constants.TRACING_CLIENT_PUBLIC_IP, "unknown"}}, func(c *gin.Context) {
+		c.Set(oauthConstants.ContextDecryptedIdTokenKey, &suite.idToken)
+	}, request)
+
+	suite.Equal(logging.GolaFields{
+		constants.TRACE_KEY:                "trace-1",
+		UserIdField:                        "42",
+		constants.TRACING_CLIENT_PUBLIC_IP: "203.0.113.7",
+	}, fields)
+}
+
+func (suite *LogEnrichmentMiddlewareTest) TestShouldLeaveOutMissingValues() {
+	request, _ := http.NewRequest("GET", "/api/users", nil)
+	request.Header.Set(constants.TRACE_ID_HTTP_HEADER, "trace-1")
+
+	fields := suite.serve(model.LogEnrichmentConfig{}, func(c *gin.Context) {}, request)
+
+	suite.Equal(logging.GolaFields{constants.TRACE_KEY: "trace-1"}, fields)
+}
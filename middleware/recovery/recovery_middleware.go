@@ -0,0 +1,106 @@
+package middleware
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"runtime/debug"
+	"syscall"
+
+	"github.com/gin-gonic/gin"
+	"github.com/inclusi-blog/gola-utils/constants"
+	"github.com/inclusi-blog/gola-utils/golaerror"
+	"github.com/inclusi-blog/gola-utils/logging"
+	"go.opencensus.io/trace"
+)
+
+const (
+	InternalServerErrorCode    = "ERR_INTERNAL_SERVER_ERROR"
+	InternalServerErrorMessage = "Internal server error"
+	PanicField                 = "panic"
+)
+
+// AlertFunc is called after the response for a recovered panic is written.
+type AlertFunc func(c *gin.Context, recovered interface{}, stack []byte)
+
+type PanicErrorData struct {
+	TraceID string `json:"traceId"`
+}
+
+// RecoveryMiddleware recovers panics of later handlers. The panic and its stack are
+// logged through logging.GetLogger, the current span is marked as failed and the
+// caller gets a golaerror.Error with the trace ID of the request. alert is optional,
+// panics of alert itself are logged and ignored. Use it after LoggingMiddleware and
+// instead of gin.Recovery.
+func RecoveryMiddleware(alert AlertFunc) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		defer func() {
+			recovered := recover()
+			if recovered == nil {
+				return
+			}
+			stack := debug.Stack()
+			message := fmt.Sprint(recovered)
+			logger := logging.GetLogger(c).WithFields(logging.GolaFields{
+				PanicField:              message,
+				logging.StackTraceField: string(stack),
+			})
+			if err, isError := recovered.(error); isError {
+				logger = logger.WithError(err)
+			}
+			logger.Errorf("recovery middleware - recovered from panic: %s", message)
+			markSpanAsFailed(c, message)
+
+			if isBrokenConnection(recovered) {
+				c.Abort()
+			} else if c.Writer.Written() {
+				c.AbortWithStatus(http.StatusInternalServerError)
+			} else {
+				c.AbortWithStatusJSON(http.StatusInternalServerError, golaerror.New(InternalServerErrorCode, InternalServerErrorMessage, PanicErrorData{TraceID: traceID(c)}))
+			}
+			if alert != nil {
+				notify(c, alert, recovered, stack)
+			}
+		}()
+		c.Next()
+	}
+}
+
+func markSpanAsFailed(c *gin.Context, message string) {
+	span := trace.FromContext(c.Request.Context())
+	if span == nil {
+		return
+	}
+	span.SetStatus(trace.Status{Code: trace.StatusCodeInternal, Message: message})
+	span.AddAttributes(trace.BoolAttribute("error", true))
+}
+
+func traceID(c *gin.Context) string {
+	if traceID := c.GetString(constants.TRACE_KEY); traceID != "" && traceID != constants.NO_TRACE_ID {
+		return traceID
+	}
+	if span := trace.FromContext(c.Request.Context()); span != nil {
+		return span.SpanContext().TraceID.String()
+	}
+	return constants.NO_TRACE_ID
+}
+
+// isBrokenConnection reports whether the client went away, the response can not be
+// written then. Like gin.Recovery only broken pipes and connection resets count, other
+// network errors, such as failed outbound calls, are server errors.
+func isBrokenConnection(recovered interface{}) bool {
+	err, isError := recovered.(error)
+	if !isError {
+		return false
+	}
+	return errors.Is(err, syscall.EPIPE) || errors.Is(err, syscall.ECONNRESET)
+}
+
+func notify(c *gin.Context, alert AlertFunc, recovered interface{}, stack []byte) {
+	defer func() {
+		if alertPanic := recover(); alertPanic != nil {
+			logging.GetLogger(c).Errorf("recovery middleware - alert panicked: %v", alertPanic)
+		}
+	}()
+	alert(c, recovered, stack)
+}
@@ -0,0 +1,158 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"syscall"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/inclusi-blog/gola-utils/constants"
+	"github.com/inclusi-blog/gola-utils/logging"
+	"github.com/inclusi-blog/gola-utils/logging/logtest"
+	"github.com/inclusi-blog/gola-utils/tracing/tracetest"
+	"github.com/stretchr/testify/suite"
+	"go.opencensus.io/trace"
+)
+
+type RecoveryMiddlewareTest struct {
+	suite.Suite
+	recorder *httptest.ResponseRecorder
+	logs     *logtest.Recorder
+	exporter *tracetest.Exporter
+}
+
+func TestRecoveryMiddlewareTestSuite(t *testing.T) {
+	suite.Run(t, new(RecoveryMiddlewareTest))
+}
+
+func (suite *RecoveryMiddlewareTest) SetupTest() {
+	suite.recorder = httptest.NewRecorder()
+	suite.logs = logtest.New()
+	suite.exporter = tracetest.Start()
+}
+
+func (suite *RecoveryMiddlewareTest) TearDownTest() {
+	suite.logs.Close()
+	suite.exporter.Stop()
+}
+
+func (suite *RecoveryMiddlewareTest) serve(alert AlertFunc, handler gin.HandlerFunc, traceID string) {
+	engine := gin.New()
+	engine.Use(logging.LoggingMiddleware(suite.logs.Logger()), RecoveryMiddleware(alert))
+	engine.GET("/api/resource", handler)
+	ctx, span := trace.StartSpan(context.Background(), "/api/resource", trace.WithSampler(trace.AlwaysSample()))
+	request, _ := http.NewRequest("GET", "/api/resource", nil)
+	if traceID != "" {
+		request.Header.Set(constants.TRACE_ID_HTTP_HEADER, traceID)
+	}
+	engine.ServeHTTP(suite.recorder, request.WithContext(ctx))
+	span.End()
+}
+
+func (suite *RecoveryMiddlewareTest) TestShouldReturnGolaErrorWithTraceID() {
+	suite.serve(nil, func(c *gin.Context) {
+		panic("nil map")
+	}, "463ac35c9f6413ad")
+
+	suite.Equal(http.StatusInternalServerError, suite.recorder.Code)
+	suite.JSONEq(`{"errorCode":"ERR_INTERNAL_SERVER_ERROR","errorMessage":"Internal server error","additionalData":{"traceId":"463ac35c9f6413ad"}}`, suite.recorder.Body.String())
+}
+
+func (suite *RecoveryMiddlewareTest) TestShouldUseSpanTraceIDWithoutTraceHeader() {
+	suite.serve(nil, func(c *gin.Context) {
+		panic("nil map")
+	}, "")
+
+	suite.Contains(suite.recorder.Body.String(), fmt.Sprintf(`"traceId":"%s"`, suite.exporter.Spans()[0].TraceID))
+}
+
+func (suite *RecoveryMiddlewareTest) TestShouldLogPanicWithStack() {
+	err := errors.New("index out of range")
+	suite.serve(nil, func(c *gin.Context) {
+		panic(err)
+	}, "463ac35c9f6413ad")
+
+	suite.True(suite.logs.AssertLogged(suite.T(), logging.ErrorLevel, "recovered from panic: index out of range", logging.GolaFields{
+		PanicField:          "index out of range",
+		logging.ErrorField:  err,
+		constants.TRACE_KEY: "463ac35c9f6413ad",
+	}))
+	suite.Contains(suite.logs.Entries()[0].Fields[logging.StackTraceField], "recovery_middleware_test.go")
+}
+
+func (suite *RecoveryMiddlewareTest) TestShouldMarkSpanAsFailed() {
+	suite.serve(nil, func(c *gin.Context) {
+		panic("nil map")
+	}, "")
+
+	span := suite.exporter.Spans()[0]
+	suite.Equal(trace.Status{Code: trace.StatusCodeInternal, Message: "nil map"}, span.Status)
+	suite.Equal(true, span.Attributes["error"])
+	suite.Equal("recovery middleware - recovered from panic: nil map", span.Annotations[0].Message)
+}
+
+func (suite *RecoveryMiddlewareTest) TestShouldKeepWrittenResponse() {
+	suite.serve(nil, func(c *gin.Context) {
+		c.String(http.StatusOK, "partial")
+		panic("nil map")
+	}, "")
+
+	suite.Equal(http.StatusOK, suite.recorder.Code)
+	suite.Equal("partial", suite.recorder.Body.String())
+}
+
+func (suite *RecoveryMiddlewareTest) TestShouldNotWriteResponseForBrokenClientConnection() {
+	suite.serve(nil, func(c *gin.Context) {
+		panic(&net.OpError{Op: "write", Net: "tcp", Err: os.NewSyscallError("write", syscall.EPIPE)})
+	}, "463ac35c9f6413ad")
+
+	suite.Empty(suite.recorder.Body.String())
+	suite.Equal(true, suite.exporter.Attributes("/api/resource")["error"])
+}
+
+func (suite *RecoveryMiddlewareTest) TestShouldReturnServerErrorForFailedOutboundConnection() {
+	suite.serve(nil, func(c *gin.Context) {
+		panic(&net.OpError{Op: "dial", Net: "tcp", Err: os.NewSyscallError("connect", syscall.ECONNREFUSED)})
+	}, "463ac35c9f6413ad")
+
+	suite.Equal(http.StatusInternalServerError, suite.recorder.Code)
+	suite.Contains(suite.recorder.Body.String(), InternalServerErrorCode)
+}
+
+func (suite *RecoveryMiddlewareTest) TestShouldCallAlert() {
+	var alerted interface{}
+	suite.serve(func(c *gin.Context, recovered interface{}, stack []byte) {
+		alerted = recovered
+		suite.NotEmpty(stack)
+	}, func(c *gin.Context) {
+		panic("nil map")
+	}, "")
+
+	suite.Equal("nil map", alerted)
+}
+
+func (suite *RecoveryMiddlewareTest) TestShouldLogPanickingAlert() {
+	suite.serve(func(c *gin.Context, recovered interface{}, stack []byte) {
+		panic("smtp down")
+	}, func(c *gin.Context) {
+		panic("nil map")
+	}, "")
+
+	suite.Equal(http.StatusInternalServerError, suite.recorder.Code)
+	suite.True(suite.logs.AssertLogged(suite.T(), logging.ErrorLevel, "alert panicked: smtp down", nil))
+}
+
+func (suite *RecoveryMiddlewareTest) TestShouldPassRequestsWithoutPanic() {
+	suite.serve(nil, func(c *gin.Context) {
+		c.Status(http.StatusNoContent)
+	}, "")
+
+	suite.Equal(http.StatusNoContent, suite.recorder.Code)
+	suite.logs.AssertNoErrors(suite.T())
+}
@@ -5,9 +5,9 @@ import (
 	"context"
 	"github.com/gin-gonic/gin"
 	"github.com/inclusi-blog/gola-utils/logging"
+	"github.com/inclusi-blog/gola-utils/redaction"
 	span "github.com/inclusi-blog/gola-utils/trace"
 	"go.opencensus.io/plugin/ochttp"
-	"go.opencensus.io/trace"
 	"io/ioutil"
 	"net/http"
 	"strings"
@@ -35,6 +35,17 @@ func HttpRequestResponseTracingAllMiddlewareWithCustomHealthEndpoint(healthEndpo
 	return HttpRequestResponseTracingMiddleware(nil, healthEndpoint, nil, nil)
 }
 
+func HttpRequestResponseTracingAllMiddlewareWithRedactor(redactor redaction.Redactor) gin.HandlerFunc {
+	return HttpRequestResponseTracingMiddlewareWithRedactor(nil, "/healthz", redactor)
+}
+
+func HttpRequestResponseTracingMiddlewareWithRedactor(apisToBeIgnored []IgnoreRequestResponseLogs, healthEndpoint string, redactor redaction.Redactor) gin.HandlerFunc {
+	redactionHook := func(_ *gin.Context, body []byte) (string, error) {
+		return redactor.TraceHook(body)
+	}
+	return HttpRequestResponseTracingMiddleware(apisToBeIgnored, healthEndpoint, redactionHook, redactionHook)
+}
+
 func HttpRequestResponseTracingMiddleware(apisToBeIgnored []IgnoreRequestResponseLogs, healthEndpoint string, requestHook traceHookFunc, responseHook traceHookFunc) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
 		logger := logging.GetLogger(ctx)
@@ -55,18 +66,18 @@ func HttpRequestResponseTracingMiddleware(apisToBeIgnored []IgnoreRequestRespons
 	}
 }
 
-func createNewSpan(ctx context.Context, httpRequest *http.Request) *trace.Span {
+func createNewSpan(ctx context.Context, httpRequest *http.Request) span.Span {
 	logger := logging.GetLogger(ctx)
 	currentSpan, _ := span.New().Continue(ctx, httpRequest)
 	logDescription := httpRequest.URL.RequestURI()
 	spanName := logDescription + " | " + REQUEST + "/" + RESPONSE
 	logger.Debug("Creating new child span with span name ", spanName)
-	_, dataSpan := trace.StartSpanWithRemoteParent(ctx, spanName, currentSpan.SpanContext())
+	_, dataSpan := span.StartSpanWithRemoteParent(ctx, spanName, currentSpan.SpanContext())
 	logger.Debug("Created new child span with span name ", spanName)
 	return dataSpan
 }
 
-func logHttpRequest(ctx *gin.Context, dataSpan *trace.Span, httpRequest *http.Request, apisToBeIgnored []IgnoreRequestResponseLogs, requestHook traceHookFunc) {
+func logHttpRequest(ctx *gin.Context, dataSpan span.Span, httpRequest *http.Request, apisToBeIgnored []IgnoreRequestResponseLogs, requestHook traceHookFunc) {
 	logger := logging.GetLogger(ctx)
 	if !isRequestLogAllowed(httpRequest.URL.RequestURI(), apisToBeIgnored) {
 		logger.Debug("request payload for api is not allowed to log")
@@ -87,7 +98,7 @@ func logHttpRequest(ctx *gin.Context, dataSpan *trace.Span, httpRequest *http.Re
 		newRequestBody, err := requestHook(ctx, requestBodyBytes)
 		if err != nil {
 			logger.Debug("logHttpRequest: Request Hook function failed")
-			addAnnotation(dataSpan, span.EscapeSpecialChar(requestBodyBytes), REQUEST, httpRequest.URL.RequestURI())
+			addAnnotation(dataSpan, redaction.NotLogged(err), REQUEST, httpRequest.URL.RequestURI())
 		} else {
 			logger.Debug("logHttpRequest: Added request payload in newly created span")
 			addAnnotation(dataSpan, span.EscapeSpecialChar([]byte(newRequestBody)), REQUEST, httpRequest.URL.RequestURI())
@@ -104,7 +115,7 @@ func addAndGetCustomResponseWriter(ctx *gin.Context) *responseBodyWriter {
 	return responseWriter
 }
 
-func logHttpResponse(ctx *gin.Context, dataSpan *trace.Span, responseWriter *responseBodyWriter, apisToBeIgnored []IgnoreRequestResponseLogs, responseHook traceHookFunc) {
+func logHttpResponse(ctx *gin.Context, dataSpan span.Span, responseWriter *responseBodyWriter, apisToBeIgnored []IgnoreRequestResponseLogs, responseHook traceHookFunc) {
 	logger := logging.GetLogger(ctx)
 	if !isResponseLogAllowed(ctx.Request.URL.RequestURI(), apisToBeIgnored) {
 		logger.Debug("response for api is not allowed to log")
@@ -121,7 +132,7 @@ func logHttpResponse(ctx *gin.Context, dataSpan *trace.Span, responseWriter *res
 		responseBodyFromHook, err := responseHook(ctx, requestBodyBytes)
 		if err != nil {
 			logger.Debug("logHttpResponse: Response Hook function failed")
-			addAnnotation(dataSpan, span.EscapeSpecialChar(requestBodyBytes), RESPONSE, ctx.Request.URL.RequestURI())
+			addAnnotation(dataSpan, redaction.NotLogged(err), RESPONSE, ctx.Request.URL.RequestURI())
 		} else {
 			logger.Debug("logHttpResponse: Added response body in newly created span. Closing the span.")
 			addAnnotation(dataSpan, span.EscapeSpecialChar([]byte(responseBodyFromHook)), RESPONSE, ctx.Request.URL.RequestURI())
@@ -154,15 +165,15 @@ func isLogAllowed(requestURI string, apisToBeIgnored []IgnoreRequestResponseLogs
 	return true
 }
 
-func addAnnotation(dataSpan *trace.Span, logValue, logKey, logDescription string) {
-	dataSpan.Annotate([]trace.Attribute{
-		trace.StringAttribute(logKey, logValue),
+func addAnnotation(dataSpan span.Span, logValue, logKey, logDescription string) {
+	dataSpan.Annotate([]span.Attribute{
+		span.StringAttribute(logKey, logValue),
 	}, logDescription)
 }
 
-func addResponseTags(statusCode int, span *trace.Span) {
-	span.AddAttributes(trace.Int64Attribute(ochttp.StatusCodeAttribute, int64(statusCode)))
+func addResponseTags(statusCode int, dataSpan span.Span) {
+	dataSpan.AddAttributes(span.Int64Attribute(ochttp.StatusCodeAttribute, int64(statusCode)))
 	if statusCode >= 400 {
-		span.AddAttributes(trace.BoolAttribute(ERROR, true))
+		dataSpan.AddAttributes(span.BoolAttribute(ERROR, true))
 	}
 }
@@ -5,11 +5,14 @@ import (
 	"encoding/json"
 	"errors"
 	"github.com/gin-gonic/gin"
+	"github.com/inclusi-blog/gola-utils/redaction"
+	"github.com/inclusi-blog/gola-utils/tracing/tracetest"
 	"github.com/stretchr/testify/suite"
 	"go.opencensus.io/plugin/ochttp"
 	"go.opencensus.io/trace"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 )
 
@@ -30,14 +33,6 @@ func (suite *HttpRequestResponseMiddlewareTest) SetupTest() {
 	suite.ginEngine = gin.Default()
 }
 
-type spanExporter struct {
-	spans []*trace.SpanData
-}
-
-func (s *spanExporter) ExportSpan(d *trace.SpanData) {
-	s.spans = append(s.spans, d)
-}
-
 type transactionsRequest struct {
 	BatchSize  int `json:"batch_size"`
 	PageNumber int `json:"page_number"`
@@ -49,8 +44,8 @@ type transactionsResponse struct {
 }
 
 func (suite *HttpRequestResponseMiddlewareTest) TestShouldAddRequestResponseAnnotationsInNewChildSpan() {
-	t := spanExporter{}
-	trace.RegisterExporter(&t)
+	t := tracetest.Start()
+	defer t.Stop()
 	url := "/api/transactions"
 	request := transactionsRequest{BatchSize: 20, PageNumber: 4}
 	response := []transactionsResponse{
@@ -70,8 +65,8 @@ func (suite *HttpRequestResponseMiddlewareTest) TestShouldAddRequestResponseAnno
 	suite.ginEngine.ServeHTTP(suite.recorder, r.WithContext(ctx))
 
 	s.End()
-	suite.Len(t.spans, 2)
-	childSpan := t.spans[0]
+	suite.Len(t.Spans(), 2)
+	childSpan := t.Spans()[0]
 	suite.Equal(int64(http.StatusOK), childSpan.Attributes[ochttp.StatusCodeAttribute])
 	suite.Len(childSpan.Annotations, 2)
 
@@ -88,8 +83,8 @@ func (suite *HttpRequestResponseMiddlewareTest) TestShouldAddRequestResponseAnno
 }
 
 func (suite *HttpRequestResponseMiddlewareTest) TestShouldAddRequestResponseAnnotationsInNewChildSpanWithRequestResponseHooks() {
-	t := spanExporter{}
-	trace.RegisterExporter(&t)
+	t := tracetest.Start()
+	defer t.Stop()
 	url := "/api/transactions"
 	request := transactionsRequest{BatchSize: 20, PageNumber: 4}
 	response := []transactionsResponse{
@@ -120,8 +115,8 @@ func (suite *HttpRequestResponseMiddlewareTest) TestShouldAddRequestResponseAnno
 	suite.ginEngine.ServeHTTP(suite.recorder, r.WithContext(ctx))
 
 	s.End()
-	suite.Len(t.spans, 2)
-	childSpan := t.spans[0]
+	suite.Len(t.Spans(), 2)
+	childSpan := t.Spans()[0]
 	suite.Equal(int64(http.StatusOK), childSpan.Attributes[ochttp.StatusCodeAttribute])
 	suite.Len(childSpan.Annotations, 2)
 
@@ -138,8 +133,8 @@ func (suite *HttpRequestResponseMiddlewareTest) TestShouldAddRequestResponseAnno
 }
 
 func (suite *HttpRequestResponseMiddlewareTest) TestShouldAddRequestResponseAnnotationsInNewChildSpanWithOnlyResponseHooks() {
-	t := spanExporter{}
-	trace.RegisterExporter(&t)
+	t := tracetest.Start()
+	defer t.Stop()
 	url := "/api/transactions"
 	request := transactionsRequest{BatchSize: 20, PageNumber: 4}
 	response := []transactionsResponse{
@@ -165,8 +160,8 @@ func (suite *HttpRequestResponseMiddlewareTest) TestShouldAddRequestResponseAnno
 	suite.ginEngine.ServeHTTP(suite.recorder, r.WithContext(ctx))
 
 	s.End()
-	suite.Len(t.spans, 2)
-	childSpan := t.spans[0]
+	suite.Len(t.Spans(), 2)
+	childSpan := t.Spans()[0]
 	suite.Equal(int64(http.StatusOK), childSpan.Attributes[ochttp.StatusCodeAttribute])
 	suite.Len(childSpan.Annotations, 2)
 
@@ -183,8 +178,8 @@ func (suite *HttpRequestResponseMiddlewareTest) TestShouldAddRequestResponseAnno
 }
 
 func (suite *HttpRequestResponseMiddlewareTest) TestShouldAddRequestResponseAnnotationsInNewChildSpanWithResponseHookFailed() {
-	t := spanExporter{}
-	trace.RegisterExporter(&t)
+	t := tracetest.Start()
+	defer t.Stop()
 	url := "/api/transactions"
 	request := transactionsRequest{BatchSize: 20, PageNumber: 4}
 	response := []transactionsResponse{
@@ -202,15 +197,14 @@ func (suite *HttpRequestResponseMiddlewareTest) TestShouldAddRequestResponseAnno
 			c.JSON(http.StatusOK, response)
 		})
 	requestBody, _ := json.Marshal(request)
-	responseBody, _ := json.Marshal(response)
 	r, _ := http.NewRequest("POST", url, bytes.NewBuffer(requestBody))
 	ctx, s := trace.StartSpan(r.Context(), "test-span", trace.WithSampler(trace.AlwaysSample()), trace.WithSpanKind(trace.SpanKindServer))
 
 	suite.ginEngine.ServeHTTP(suite.recorder, r.WithContext(ctx))
 
 	s.End()
-	suite.Len(t.spans, 2)
-	childSpan := t.spans[0]
+	suite.Len(t.Spans(), 2)
+	childSpan := t.Spans()[0]
 	suite.Equal(int64(http.StatusOK), childSpan.Attributes[ochttp.StatusCodeAttribute])
 	suite.Len(childSpan.Annotations, 2)
 
@@ -221,14 +215,14 @@ func (suite *HttpRequestResponseMiddlewareTest) TestShouldAddRequestResponseAnno
 	suite.Len(childSpan.Annotations[1].Attributes, 1)
 
 	suite.Equal(string(requestBody), childSpan.Annotations[0].Attributes["request"])
-	suite.Equal(string(responseBody), childSpan.Annotations[1].Attributes["response"])
+	suite.Equal("body not logged: resHook failed", childSpan.Annotations[1].Attributes["response"])
 
 	suite.Nil(childSpan.Attributes["error"])
 }
 
 func (suite *HttpRequestResponseMiddlewareTest) TestShouldAddRequestResponseAnnotationsInNewChildSpanWithOnlyRequestHooks() {
-	t := spanExporter{}
-	trace.RegisterExporter(&t)
+	t := tracetest.Start()
+	defer t.Stop()
 	url := "/api/transactions"
 	request := transactionsRequest{BatchSize: 20, PageNumber: 4}
 	response := []transactionsResponse{
@@ -254,8 +248,8 @@ func (suite *HttpRequestResponseMiddlewareTest) TestShouldAddRequestResponseAnno
 	suite.ginEngine.ServeHTTP(suite.recorder, r.WithContext(ctx))
 
 	s.End()
-	suite.Len(t.spans, 2)
-	childSpan := t.spans[0]
+	suite.Len(t.Spans(), 2)
+	childSpan := t.Spans()[0]
 	suite.Equal(int64(http.StatusOK), childSpan.Attributes[ochttp.StatusCodeAttribute])
 	suite.Len(childSpan.Annotations, 2)
 
@@ -272,8 +266,8 @@ func (suite *HttpRequestResponseMiddlewareTest) TestShouldAddRequestResponseAnno
 }
 
 func (suite *HttpRequestResponseMiddlewareTest) TestShouldAddRequestResponseAnnotationsInNewChildSpanWithRequestHookFailed() {
-	t := spanExporter{}
-	trace.RegisterExporter(&t)
+	t := tracetest.Start()
+	defer t.Stop()
 	url := "/api/transactions"
 	request := transactionsRequest{BatchSize: 20, PageNumber: 4}
 	response := []transactionsResponse{
@@ -298,8 +292,8 @@ func (suite *HttpRequestResponseMiddlewareTest) TestShouldAddRequestResponseAnno
 	suite.ginEngine.ServeHTTP(suite.recorder, r.WithContext(ctx))
 
 	s.End()
-	suite.Len(t.spans, 2)
-	childSpan := t.spans[0]
+	suite.Len(t.Spans(), 2)
+	childSpan := t.Spans()[0]
 	suite.Equal(int64(http.StatusOK), childSpan.Attributes[ochttp.StatusCodeAttribute])
 	suite.Len(childSpan.Annotations, 2)
 
@@ -309,15 +303,15 @@ func (suite *HttpRequestResponseMiddlewareTest) TestShouldAddRequestResponseAnno
 	suite.Len(childSpan.Annotations[0].Attributes, 1)
 	suite.Len(childSpan.Annotations[1].Attributes, 1)
 
-	suite.Equal(string(requestBody), childSpan.Annotations[0].Attributes["request"])
+	suite.Equal("body not logged: req hook failed", childSpan.Annotations[0].Attributes["request"])
 	suite.Equal(string(responseBody), childSpan.Annotations[1].Attributes["response"])
 
 	suite.Nil(childSpan.Attributes["error"])
 }
 
 func (suite *HttpRequestResponseMiddlewareTest) TestShouldAddNOBODYCONTENTAsRequestAnnotationIfRequestBodyIsEmpty() {
-	t := spanExporter{}
-	trace.RegisterExporter(&t)
+	t := tracetest.Start()
+	defer t.Stop()
 	url := "/api/transactions"
 	response := []transactionsResponse{
 		{TransactionId: 111, TransactionType: "Transfer funds"},
@@ -335,8 +329,8 @@ func (suite *HttpRequestResponseMiddlewareTest) TestShouldAddNOBODYCONTENTAsRequ
 	suite.ginEngine.ServeHTTP(suite.recorder, r.WithContext(ctx))
 
 	s.End()
-	suite.Len(t.spans, 2)
-	childSpan := t.spans[0]
+	suite.Len(t.Spans(), 2)
+	childSpan := t.Spans()[0]
 	suite.Equal(int64(http.StatusOK), childSpan.Attributes[ochttp.StatusCodeAttribute])
 	suite.Len(childSpan.Annotations, 2)
 
@@ -353,8 +347,8 @@ func (suite *HttpRequestResponseMiddlewareTest) TestShouldAddNOBODYCONTENTAsRequ
 }
 
 func (suite *HttpRequestResponseMiddlewareTest) TestShouldAddNOBODYCONTENTAsRequestAnnotationIfRequestBodyIsEmptyWithHooks() {
-	t := spanExporter{}
-	trace.RegisterExporter(&t)
+	t := tracetest.Start()
+	defer t.Stop()
 	url := "/api/transactions"
 	response := []transactionsResponse{
 		{TransactionId: 111, TransactionType: "Transfer funds"},
@@ -382,8 +376,8 @@ func (suite *HttpRequestResponseMiddlewareTest) TestShouldAddNOBODYCONTENTAsRequ
 	suite.ginEngine.ServeHTTP(suite.recorder, r.WithContext(ctx))
 
 	s.End()
-	suite.Len(t.spans, 2)
-	childSpan := t.spans[0]
+	suite.Len(t.Spans(), 2)
+	childSpan := t.Spans()[0]
 	suite.Equal(int64(http.StatusOK), childSpan.Attributes[ochttp.StatusCodeAttribute])
 	suite.Len(childSpan.Annotations, 2)
 
@@ -400,8 +394,8 @@ func (suite *HttpRequestResponseMiddlewareTest) TestShouldAddNOBODYCONTENTAsRequ
 }
 
 func (suite *HttpRequestResponseMiddlewareTest) TestShouldAddBase64EncodedTextAsResponseAnnotationIfResponseBodyContainsInvalidChars() {
-	t := spanExporter{}
-	trace.RegisterExporter(&t)
+	t := tracetest.Start()
+	defer t.Stop()
 	url := "/api/transactions"
 	request := transactionsRequest{BatchSize: 20, PageNumber: 4}
 	response := []byte{1, 128, 129, 200}
@@ -418,8 +412,8 @@ func (suite *HttpRequestResponseMiddlewareTest) TestShouldAddBase64EncodedTextAs
 	suite.ginEngine.ServeHTTP(suite.recorder, r.WithContext(ctx))
 
 	s.End()
-	suite.Len(t.spans, 2)
-	childSpan := t.spans[0]
+	suite.Len(t.Spans(), 2)
+	childSpan := t.Spans()[0]
 	suite.Equal(int64(http.StatusOK), childSpan.Attributes[ochttp.StatusCodeAttribute])
 	suite.Len(childSpan.Annotations, 2)
 
@@ -436,8 +430,8 @@ func (suite *HttpRequestResponseMiddlewareTest) TestShouldAddBase64EncodedTextAs
 }
 
 func (suite *HttpRequestResponseMiddlewareTest) TestShouldAddNOBODYCONTENTAsResponseAnnotationsIfResponseBodyIsEmpty() {
-	t := spanExporter{}
-	trace.RegisterExporter(&t)
+	t := tracetest.Start()
+	defer t.Stop()
 	url := "/api/transactions"
 	request := transactionsRequest{BatchSize: 20, PageNumber: 4}
 	suite.ginEngine.POST(url,
@@ -453,8 +447,8 @@ func (suite *HttpRequestResponseMiddlewareTest) TestShouldAddNOBODYCONTENTAsResp
 	suite.ginEngine.ServeHTTP(suite.recorder, r.WithContext(ctx))
 
 	s.End()
-	suite.Len(t.spans, 2)
-	childSpan := t.spans[0]
+	suite.Len(t.Spans(), 2)
+	childSpan := t.Spans()[0]
 	suite.Equal(int64(http.StatusOK), childSpan.Attributes[ochttp.StatusCodeAttribute])
 	suite.Len(childSpan.Annotations, 2)
 
@@ -471,8 +465,8 @@ func (suite *HttpRequestResponseMiddlewareTest) TestShouldAddNOBODYCONTENTAsResp
 }
 
 func (suite *HttpRequestResponseMiddlewareTest) TestShouldSetErrorAttributeInChildSpanIfResponseIsNotSuccess() {
-	t := spanExporter{}
-	trace.RegisterExporter(&t)
+	t := tracetest.Start()
+	defer t.Stop()
 	url := "/api/transactions"
 	request := transactionsRequest{BatchSize: 20, PageNumber: 4}
 	suite.ginEngine.POST(url,
@@ -487,14 +481,14 @@ func (suite *HttpRequestResponseMiddlewareTest) TestShouldSetErrorAttributeInChi
 	suite.ginEngine.ServeHTTP(suite.recorder, r.WithContext(ctx))
 
 	s.End()
-	childSpan := t.spans[0]
+	childSpan := t.Spans()[0]
 	suite.Equal(int64(http.StatusForbidden), childSpan.Attributes[ochttp.StatusCodeAttribute])
 	suite.Equal(true, childSpan.Attributes["error"])
 }
 
 func (suite *HttpRequestResponseMiddlewareTest) TestShouldExcludeTracingForHealthEndpoint() {
-	t := spanExporter{}
-	trace.RegisterExporter(&t)
+	t := tracetest.Start()
+	defer t.Stop()
 	url := "/api/v1/healthz"
 	request := transactionsRequest{BatchSize: 20, PageNumber: 4}
 	suite.ginEngine.GET(url,
@@ -509,12 +503,12 @@ func (suite *HttpRequestResponseMiddlewareTest) TestShouldExcludeTracingForHealt
 	suite.ginEngine.ServeHTTP(suite.recorder, r.WithContext(ctx))
 
 	s.End()
-	suite.Len(t.spans, 1)
+	suite.Len(t.Spans(), 1)
 }
 
 func (suite *HttpRequestResponseMiddlewareTest) TestShouldExcludeTracingForCustomHealthEndpoint() {
-	t := spanExporter{}
-	trace.RegisterExporter(&t)
+	t := tracetest.Start()
+	defer t.Stop()
 	healthEndpoint := "/api/v1/info"
 	request := transactionsRequest{BatchSize: 20, PageNumber: 4}
 	suite.ginEngine.GET(healthEndpoint,
@@ -529,12 +523,12 @@ func (suite *HttpRequestResponseMiddlewareTest) TestShouldExcludeTracingForCusto
 	suite.ginEngine.ServeHTTP(suite.recorder, r.WithContext(ctx))
 
 	s.End()
-	suite.Len(t.spans, 1)
+	suite.Len(t.Spans(), 1)
 }
 
 func (suite *HttpRequestResponseMiddlewareTest) TestShouldNotLogRequestAndResponseByDefaultIfApiIsIncludedInIgnoredApisList() {
-	t := spanExporter{}
-	trace.RegisterExporter(&t)
+	t := tracetest.Start()
+	defer t.Stop()
 	url := "/api/transactions"
 	request := transactionsRequest{BatchSize: 20, PageNumber: 4}
 	response := []transactionsResponse{
@@ -557,14 +551,14 @@ func (suite *HttpRequestResponseMiddlewareTest) TestShouldNotLogRequestAndRespon
 	suite.ginEngine.ServeHTTP(suite.recorder, r.WithContext(ctx))
 
 	s.End()
-	childSpan := t.spans[0]
+	childSpan := t.Spans()[0]
 	suite.Equal(expectedRequestLog, childSpan.Annotations[0].Attributes["request"])
 	suite.Equal(expectedResponseLog, childSpan.Annotations[1].Attributes["response"])
 }
 
 func (suite *HttpRequestResponseMiddlewareTest) TestShouldLogRequestOnlyIfApiIsIncludedInIgnoredApisListAndAllowedRequestIsSetToTrue() {
-	t := spanExporter{}
-	trace.RegisterExporter(&t)
+	t := tracetest.Start()
+	defer t.Stop()
 	url := "/api/transactions"
 	request := transactionsRequest{BatchSize: 20, PageNumber: 4}
 	response := []transactionsResponse{
@@ -586,14 +580,14 @@ func (suite *HttpRequestResponseMiddlewareTest) TestShouldLogRequestOnlyIfApiIsI
 	suite.ginEngine.ServeHTTP(suite.recorder, r.WithContext(ctx))
 
 	s.End()
-	childSpan := t.spans[0]
+	childSpan := t.Spans()[0]
 	suite.Equal(string(requestBody), childSpan.Annotations[0].Attributes["request"])
 	suite.Equal(expectedResponseLog, childSpan.Annotations[1].Attributes["response"])
 }
 
 func (suite *HttpRequestResponseMiddlewareTest) TestShouldLogResponseOnlyIfApiIsIncludedInIgnoredApisListAndAllowedResponseIsSetToTrue() {
-	t := spanExporter{}
-	trace.RegisterExporter(&t)
+	t := tracetest.Start()
+	defer t.Stop()
 	url := "/api/transactions"
 	request := transactionsRequest{BatchSize: 20, PageNumber: 4}
 	response := []transactionsResponse{
@@ -616,7 +610,71 @@ func (suite *HttpRequestResponseMiddlewareTest) TestShouldLogResponseOnlyIfApiIs
 	suite.ginEngine.ServeHTTP(suite.recorder, r.WithContext(ctx))
 
 	s.End()
-	childSpan := t.spans[0]
+	childSpan := t.Spans()[0]
 	suite.Equal(expectedRequestLog, childSpan.Annotations[0].Attributes["request"])
 	suite.Equal(string(responseBody), childSpan.Annotations[1].Attributes["response"])
 }
+
+func (suite *HttpRequestResponseMiddlewareTest) TestShouldRedactRequestResponseAnnotationsWithRedactor() {
+	t := tracetest.Start()
+	defer t.Stop()
+	url := "/api/transactions"
+	request := transactionsRequest{BatchSize: 20, PageNumber: 4}
+	response := []transactionsResponse{
+		{TransactionId: 111, TransactionType: "Transfer funds"},
+	}
+	redactor, _ := redaction.NewRedactor(redaction.Config{Rules: []redaction.Rule{
+		{Path: "$.batch_size", Strategy: redaction.Drop},
+		{Path: "transaction_type", Strategy: redaction.PartialMask, RevealFirst: 2, RevealLast: 0},
+	}})
+
+	suite.ginEngine.POST(url,
+		HttpRequestResponseTracingAllMiddlewareWithRedactor(redactor),
+		func(c *gin.Context) {
+			c.JSON(http.StatusOK, response)
+		})
+	requestBody, _ := json.Marshal(request)
+	r, _ := http.NewRequest("POST", url, bytes.NewBuffer(requestBody))
+	ctx, s := trace.StartSpan(r.Context(), "test-span", trace.WithSampler(trace.AlwaysSample()), trace.WithSpanKind(trace.SpanKindServer))
+
+	suite.ginEngine.ServeHTTP(suite.recorder, r.WithContext(ctx))
+
+	s.End()
+	suite.Len(t.Spans(), 2)
+	childSpan := t.Spans()[0]
+	suite.Equal(`{"page_number":4}`, childSpan.Annotations[0].Attributes["request"])
+	suite.Equal(`[{"transaction_id":111,"transaction_type":"Tr************"}]`, childSpan.Annotations[1].Attributes["response"])
+}
+
+func (suite *HttpRequestResponseMiddlewareTest) TestShouldNotLogFormEncodedBodyWithRedactor() {
+	t := tracetest.Start()
+	defer t.Stop()
+	url := "/api/login"
+	redactor, _ := redaction.NewRedactor(redaction.Config{Rules: []redaction.Rule{{Path: "password", Strategy: redaction.FullMask}}})
+	strictRedactor, _ := redaction.NewRedactor(redaction.Config{FailOnNonJSON: true})
+	suite.ginEngine.POST(url,
+		HttpRequestResponseTracingAllMiddlewareWithRedactor(redactor),
+		func(c *gin.Context) {
+			c.Data(http.StatusOK, "application/x-www-form-urlencoded", []byte("password=secret"))
+		})
+	suite.ginEngine.PUT(url,
+		HttpRequestResponseTracingAllMiddlewareWithRedactor(strictRedactor),
+		func(c *gin.Context) {
+			c.Data(http.StatusOK, "application/x-www-form-urlencoded", []byte("password=secret"))
+		})
+
+	for _, method := range []string{http.MethodPost, http.MethodPut} {
+		r, _ := http.NewRequest(method, url, strings.NewReader("username=ada&password=secret"))
+		r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		ctx, s := trace.StartSpan(r.Context(), "test-span", trace.WithSampler(trace.AlwaysSample()), trace.WithSpanKind(trace.SpanKindServer))
+		suite.ginEngine.ServeHTTP(httptest.NewRecorder(), r.WithContext(ctx))
+		s.End()
+	}
+
+	spans := t.SpansNamed(url + " | request/response")
+	suite.Len(spans, 2)
+	suite.Equal("Payload not logged as it is not a JSON document", spans[0].Annotations[0].Attributes["request"])
+	suite.Equal("Payload not logged as it is not a JSON document", spans[0].Annotations[1].Attributes["response"])
+	suite.Equal("body not logged: payload is not a valid JSON document", spans[1].Annotations[0].Attributes["request"])
+	suite.Equal("body not logged: payload is not a valid JSON document", spans[1].Annotations[1].Attributes["response"])
+}
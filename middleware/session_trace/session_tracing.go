@@ -3,32 +3,27 @@ package middleware
 import (
 	"github.com/gin-gonic/gin"
 	"github.com/inclusi-blog/gola-utils/constants"
+	"github.com/inclusi-blog/gola-utils/tracing/propagation"
 	"go.opencensus.io/trace"
 )
 
 func SessionTracingMiddleware(c *gin.Context) {
 	span := trace.FromContext(c.Request.Context())
 
-	sessionHeaderKey := c.Request.Header.Get(constants.TRACING_SESSION_HEADER_KEY)
-	if sessionHeaderKey != "" {
-		span.AddAttributes(trace.StringAttribute(constants.TRACING_SESSION_ID, sessionHeaderKey))
+	baggage := propagation.BaggageFromRequest(c.Request)
+	for _, key := range []string{constants.TRACING_SESSION_ID, constants.TRACING_APP_VERSION, constants.TRACING_DEVICE_INFO} {
+		if value := baggage[key]; value != "" {
+			span.AddAttributes(trace.StringAttribute(key, value))
+		}
 	}
 	clientIp := c.Request.Header.Get(constants.TRACING_CLIENT_PUBLIC_IP_HEADER)
 	if clientIp != "" {
 		span.AddAttributes(trace.StringAttribute(constants.TRACING_CLIENT_PUBLIC_IP, clientIp))
 	}
-	appVersion := c.Request.Header.Get(constants.TRACING_APP_VERSION_HEADER_KEY)
-	if appVersion != "" {
-		span.AddAttributes(trace.StringAttribute(constants.TRACING_APP_VERSION, appVersion))
-	}
-
-	deviceInfo := c.Request.Header.Get(constants.TRACING_DEVICE_INFO_HEADER_KEY)
-	if deviceInfo != "" {
-		span.AddAttributes(trace.StringAttribute(constants.TRACING_DEVICE_INFO, deviceInfo))
-	}
 	traceId := c.Request.Header.Get(constants.TRACE_ID_HTTP_HEADER)
 	if traceId != "" {
 		c.Writer.Header().Add(constants.TRACE_ID_HTTP_HEADER, traceId)
 	}
+	c.Request = c.Request.WithContext(propagation.ContextWithBaggage(c.Request.Context(), baggage))
 	c.Next()
 }
@@ -8,6 +8,7 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/inclusi-blog/gola-utils/constants"
+	"github.com/inclusi-blog/gola-utils/tracing/propagation"
 	"github.com/stretchr/testify/suite"
 	"go.opencensus.io/trace"
 )
@@ -188,3 +189,24 @@ func (suite *SessionTracingMiddlewareTestSuite) TestShouldSetTraceIdHttpHeaderWh
 	s.End()
 	suite.Equal("traceId", suite.context.Writer.Header().Get(constants.TRACE_ID_HTTP_HEADER))
 }
+
+func (suite *SessionTracingMiddlewareTestSuite) TestShouldSetSessionAttributesAndContextBaggageFromBaggageHeader() {
+	t := testExporter{}
+	trace.RegisterExporter(&t)
+	r, _ := http.NewRequest("GEt", "/dummy", nil)
+	r.Header.Set(propagation.BaggageHeader, "session_tracing_id=12345,app_version=version%201;ttl=60,tenant=gola")
+	r.Header.Set(constants.TRACING_DEVICE_INFO_HEADER_KEY, "deviceInfo")
+	ctx, s := trace.StartSpan(suite.context, "test-span", trace.WithSampler(trace.AlwaysSample()), trace.WithSpanKind(trace.SpanKindServer))
+	suite.context.Request = r.WithContext(ctx)
+	SessionTracingMiddleware(suite.context)
+	s.End()
+	suite.Equal("12345", t.SpanData.Attributes[constants.TRACING_SESSION_ID])
+	suite.Equal("version 1", t.SpanData.Attributes[constants.TRACING_APP_VERSION])
+	suite.Equal("deviceInfo", t.SpanData.Attributes[constants.TRACING_DEVICE_INFO])
+	suite.Equal(propagation.Baggage{
+		constants.TRACING_SESSION_ID:  "12345",
+		constants.TRACING_APP_VERSION: "version 1",
+		constants.TRACING_DEVICE_INFO: "deviceInfo",
+		"tenant":                      "gola",
+	}, propagation.BaggageFromContext(suite.context.Request.Context()))
+}
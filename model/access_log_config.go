@@ -0,0 +1,6 @@
+package model
+
+type AccessLogConfig struct {
+	SkipPaths                    []string `json:"skipPaths"`
+	SlowRequestThresholdInMillis int      `json:"slowRequestThresholdInMillis"`
+}
@@ -0,0 +1,6 @@
+package model
+
+type DeadlineConfig struct {
+	DefaultTimeoutInMillis int `json:"defaultTimeoutInMillis"`
+	MaxTimeoutInMillis     int `json:"maxTimeoutInMillis"`
+}
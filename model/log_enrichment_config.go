@@ -0,0 +1,5 @@
+package model
+
+type LogEnrichmentConfig struct {
+	Fields []string `json:"fields"`
+}
@@ -0,0 +1,13 @@
+package model
+
+// OTLPConfig configures the OTLP trace exporter. Protocol is "grpc", the default, with
+// Endpoint as host:port, or "http" with Endpoint as URL or host:port.
+type OTLPConfig struct {
+	Endpoint              string            `json:"endpoint" validate:"required"`
+	Protocol              string            `json:"protocol"`
+	Insecure              bool              `json:"insecure"`
+	Headers               map[string]string `json:"headers"`
+	TimeoutInMillis       int               `json:"timeoutInMillis"`
+	BatchSize             int               `json:"batchSize"`
+	FlushIntervalInMillis int               `json:"flushIntervalInMillis"`
+}
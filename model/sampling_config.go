@@ -0,0 +1,25 @@
+package model
+
+// SamplerConfig is one sampling strategy, Type is "always", "never", "probabilistic"
+// with Probability between 0 and 1, or "rate-limited" with up to TracesPerSecond traces.
+// An empty Type samples always.
+type SamplerConfig struct {
+	Type            string  `json:"type"`
+	Probability     float64 `json:"probability"`
+	TracesPerSecond float64 `json:"tracesPerSecond"`
+}
+
+// SamplingRule samples requests matching Path, a path.Match pattern like
+// "/api/drafts/*", and Method, empty for every method, with its own Sampler.
+type SamplingRule struct {
+	Path    string        `json:"path"`
+	Method  string        `json:"method"`
+	Sampler SamplerConfig `json:"sampler"`
+}
+
+type SamplingConfig struct {
+	Default       SamplerConfig  `json:"default"`
+	Rules         []SamplingRule `json:"rules"`
+	HonorUpstream bool           `json:"honorUpstream"`
+	SampleErrors  bool           `json:"sampleErrors"`
+}
@@ -0,0 +1,93 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: neo4j_client.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	gomock "github.com/golang/mock/gomock"
+	neo4j "github.com/neo4j/neo4j-go-driver/v4/neo4j"
+	reflect "reflect"
+)
+
+// MockNeo4jClient is a mock of Neo4jClient interface
+type MockNeo4jClient struct {
+	ctrl     *gomock.Controller
+	recorder *MockNeo4jClientMockRecorder
+}
+
+// MockNeo4jClientMockRecorder is the mock recorder for MockNeo4jClient
+type MockNeo4jClientMockRecorder struct {
+	mock *MockNeo4jClient
+}
+
+// NewMockNeo4jClient creates a new mock instance
+func NewMockNeo4jClient(ctrl *gomock.Controller) *MockNeo4jClient {
+	mock := &MockNeo4jClient{ctrl: ctrl}
+	mock.recorder = &MockNeo4jClientMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockNeo4jClient) EXPECT() *MockNeo4jClientMockRecorder {
+	return m.recorder
+}
+
+// ReadTransaction mocks base method
+func (m *MockNeo4jClient) ReadTransaction(ctx context.Context, work neo4j.TransactionWork) (interface{}, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReadTransaction", ctx, work)
+	ret0, _ := ret[0].(interface{})
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReadTransaction indicates an expected call of ReadTransaction
+func (mr *MockNeo4jClientMockRecorder) ReadTransaction(ctx, work interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadTransaction", reflect.TypeOf((*MockNeo4jClient)(nil).ReadTransaction), ctx, work)
+}
+
+// WriteTransaction mocks base method
+func (m *MockNeo4jClient) WriteTransaction(ctx context.Context, work neo4j.TransactionWork) (interface{}, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WriteTransaction", ctx, work)
+	ret0, _ := ret[0].(interface{})
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// WriteTransaction indicates an expected call of WriteTransaction
+func (mr *MockNeo4jClientMockRecorder) WriteTransaction(ctx, work interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WriteTransaction", reflect.TypeOf((*MockNeo4jClient)(nil).WriteTransaction), ctx, work)
+}
+
+// HealthCheck mocks base method
+func (m *MockNeo4jClient) HealthCheck() error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "HealthCheck")
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// HealthCheck indicates an expected call of HealthCheck
+func (mr *MockNeo4jClientMockRecorder) HealthCheck() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HealthCheck", reflect.TypeOf((*MockNeo4jClient)(nil).HealthCheck))
+}
+
+// Close mocks base method
+func (m *MockNeo4jClient) Close() error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Close")
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Close indicates an expected call of Close
+func (mr *MockNeo4jClientMockRecorder) Close() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Close", reflect.TypeOf((*MockNeo4jClient)(nil).Close))
+}
@@ -0,0 +1,144 @@
+package neo4j_util
+
+import (
+	"context"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/inclusi-blog/gola-utils/logging"
+	"github.com/neo4j/neo4j-go-driver/v4/neo4j"
+	"go.opencensus.io/trace"
+)
+
+const (
+	ReadTransactionSpanName  = "neo4j.ReadTransaction"
+	WriteTransactionSpanName = "neo4j.WriteTransaction"
+	SystemAttribute          = "db.system"
+	DatabaseAttribute        = "db.name"
+	AttemptsAttribute        = "db.neo4j.attempts"
+	ErrorAttribute           = "error"
+
+	neo4jSystem = "neo4j"
+)
+
+var NewDriverFunc = neo4j.NewDriver
+
+type Neo4jClient interface {
+	// ReadTransaction runs work in a read transaction traced in a span of ctx, statements
+	// run by work are traced in child spans. Transient errors are retried by the driver
+	// for up to MaxTransactionRetryTimeInSeconds.
+	ReadTransaction(ctx context.Context, work neo4j.TransactionWork) (interface{}, error)
+	WriteTransaction(ctx context.Context, work neo4j.TransactionWork) (interface{}, error)
+	// HealthCheck returns an error when the database cannot be reached.
+	HealthCheck() error
+	Close() error
+}
+
+type neo4jClient struct {
+	driver           neo4j.Driver
+	database         string
+	redactParameters bool
+}
+
+// NewNeo4jClient builds a driver from config and verifies it can connect. Like
+// NewRedisClientWith, the client is returned along with a failed verification.
+func NewNeo4jClient(config Neo4jConfig) (Neo4jClient, error) {
+	auth := neo4j.NoAuth()
+	if config.Username != "" {
+		auth = neo4j.BasicAuth(config.Username, config.Password, config.Realm)
+	}
+	driver, err := NewDriverFunc(config.URI, auth, func(driverConfig *neo4j.Config) {
+		setDriverConfig(config, driverConfig)
+	})
+	if err != nil {
+		logging.NewLoggerEntry().Error("error while creating neo4j driver ", err.Error())
+		return nil, err
+	}
+	client := neo4jClient{driver: driver, database: config.Database, redactParameters: config.RedactParameters}
+	return client, client.HealthCheck()
+}
+
+func setDriverConfig(config Neo4jConfig, driverConfig *neo4j.Config) {
+	if config.MaxConnectionPoolSize > 0 {
+		driverConfig.MaxConnectionPoolSize = config.MaxConnectionPoolSize
+	}
+	if config.MaxConnectionLifetimeInSeconds > 0 {
+		driverConfig.MaxConnectionLifetime = time.Duration(config.MaxConnectionLifetimeInSeconds) * time.Second
+	}
+	if config.ConnectionAcquisitionTimeoutInSeconds > 0 {
+		driverConfig.ConnectionAcquisitionTimeout = time.Duration(config.ConnectionAcquisitionTimeoutInSeconds) * time.Second
+	}
+	if config.SocketConnectTimeoutInSeconds > 0 {
+		driverConfig.SocketConnectTimeout = time.Duration(config.SocketConnectTimeoutInSeconds) * time.Second
+	}
+	if config.MaxTransactionRetryTimeInSeconds > 0 {
+		driverConfig.MaxTransactionRetryTime = time.Duration(config.MaxTransactionRetryTimeInSeconds) * time.Second
+	}
+}
+
+func (c neo4jClient) ReadTransaction(ctx context.Context, work neo4j.TransactionWork) (interface{}, error) {
+	return c.transaction(ctx, ReadTransactionSpanName, neo4j.AccessModeRead, work)
+}
+
+func (c neo4jClient) WriteTransaction(ctx context.Context, work neo4j.TransactionWork) (interface{}, error) {
+	return c.transaction(ctx, WriteTransactionSpanName, neo4j.AccessModeWrite, work)
+}
+
+// transaction annotates the span with the error of every attempt the driver retries.
+func (c neo4jClient) transaction(ctx context.Context, spanName string, mode neo4j.AccessMode, work neo4j.TransactionWork) (interface{}, error) {
+	logger := logging.GetLogger(ctx)
+	if ginContext, ok := ctx.(*gin.Context); ok && ginContext.Request != nil {
+		ctx = ginContext.Request.Context()
+	}
+	ctx, span := trace.StartSpan(ctx, spanName, trace.WithSpanKind(trace.SpanKindClient))
+	defer span.End()
+	span.AddAttributes(trace.StringAttribute(SystemAttribute, neo4jSystem), trace.StringAttribute(DatabaseAttribute, c.database))
+
+	session := c.driver.NewSession(neo4j.SessionConfig{AccessMode: mode, DatabaseName: c.database})
+	defer func() {
+		if err := session.Close(); err != nil {
+			logger.Errorf("neo4j client - error while closing session: %v", err)
+		}
+	}()
+	attempts := 0
+	var lastErr error
+	tracedWork := func(tx neo4j.Transaction) (interface{}, error) {
+		attempts++
+		if lastErr != nil {
+			span.Annotate([]trace.Attribute{trace.Int64Attribute("attempt", int64(attempts)), trace.StringAttribute("error", lastErr.Error())}, "retrying transaction")
+		}
+		result, err := work(tracedTransaction{Transaction: tx, ctx: ctx, database: c.database, redactParameters: c.redactParameters})
+		lastErr = err
+		return result, err
+	}
+	var result interface{}
+	var err error
+	if mode == neo4j.AccessModeRead {
+		result, err = session.ReadTransaction(tracedWork)
+	} else {
+		result, err = session.WriteTransaction(tracedWork)
+	}
+	span.AddAttributes(trace.Int64Attribute(AttemptsAttribute, int64(attempts)))
+	if err != nil {
+		setError(span, err)
+		logger.Errorf("neo4j client - transaction failed after %d attempts: %v", attempts, err)
+	}
+	return result, err
+}
+
+func (c neo4jClient) HealthCheck() error {
+	if err := c.driver.VerifyConnectivity(); err != nil {
+		logging.NewLoggerEntry().Error("unable to connect to neo4j ", err.Error())
+		return err
+	}
+	return nil
+}
+
+func (c neo4jClient) Close() error {
+	return c.driver.Close()
+}
+
+func setError(span *trace.Span, err error) {
+	span.AddAttributes(trace.BoolAttribute(ErrorAttribute, true))
+	span.SetStatus(trace.Status{Code: trace.StatusCodeUnknown, Message: err.Error()})
+}
@@ -0,0 +1,271 @@
+package neo4j_util
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/inclusi-blog/gola-utils/constants"
+	"github.com/inclusi-blog/gola-utils/logging"
+	"github.com/inclusi-blog/gola-utils/tracing/tracetest"
+	"github.com/neo4j/neo4j-go-driver/v4/neo4j"
+	"github.com/sirupsen/logrus"
+	logrusTest "github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/suite"
+	"go.opencensus.io/trace"
+)
+
+type fakeDriver struct {
+	neo4j.Driver
+	session        *fakeSession
+	sessionConfigs []neo4j.SessionConfig
+	connectivity   error
+	closed         bool
+}
+
+func (d *fakeDriver) NewSession(config neo4j.SessionConfig) neo4j.Session {
+	d.sessionConfigs = append(d.sessionConfigs, config)
+	return d.session
+}
+
+func (d *fakeDriver) Target() url.URL {
+	return url.URL{}
+}
+
+func (d *fakeDriver) VerifyConnectivity() error {
+	return d.connectivity
+}
+
+func (d *fakeDriver) Close() error {
+	d.closed = true
+	return nil
+}
+
+// fakeSession retries work on transient errors like the driver does.
+type fakeSession struct {
+	neo4j.Session
+	tx     *fakeTransaction
+	closed bool
+}
+
+func (s *fakeSession) ReadTransaction(work neo4j.TransactionWork, _ ...func(*neo4j.TransactionConfig)) (interface{}, error) {
+	return s.run(work)
+}
+
+func (s *fakeSession) WriteTransaction(work neo4j.TransactionWork, _ ...func(*neo4j.TransactionConfig)) (interface{}, error) {
+	return s.run(work)
+}
+
+func (s *fakeSession) run(work neo4j.TransactionWork) (interface{}, error) {
+	var err error
+	for attempt := 0; attempt < 3; attempt++ {
+		var result interface{}
+		if result, err = work(s.tx); err == nil {
+			return result, nil
+		}
+		if neo4jError, ok := err.(*neo4j.Neo4jError); !ok || neo4jError.Classification() != "TransientError" {
+			return nil, err
+		}
+	}
+	return nil, err
+}
+
+func (s *fakeSession) Close() error {
+	s.closed = true
+	return nil
+}
+
+type fakeTransaction struct {
+	neo4j.Transaction
+	statements []string
+	errors     []error
+	result     neo4j.Result
+}
+
+func (tx *fakeTransaction) Run(cypher string, _ map[string]interface{}) (neo4j.Result, error) {
+	tx.statements = append(tx.statements, cypher)
+	if len(tx.errors) > 0 {
+		err := tx.errors[0]
+		tx.errors = tx.errors[1:]
+		return nil, err
+	}
+	return tx.result, nil
+}
+
+type Neo4jClientTestSuite struct {
+	suite.Suite
+	driver   *fakeDriver
+	client   Neo4jClient
+	exporter *tracetest.Exporter
+	ctx      context.Context
+	parent   *trace.Span
+}
+
+func TestNeo4jClientTestSuite(t *testing.T) {
+	suite.Run(t, new(Neo4jClientTestSuite))
+}
+
+func (suite *Neo4jClientTestSuite) SetupTest() {
+	suite.driver = &fakeDriver{session: &fakeSession{tx: &fakeTransaction{}}}
+	suite.client = neo4jClient{driver: suite.driver, database: "graph"}
+	suite.exporter = tracetest.Start()
+	suite.ctx, suite.parent = trace.StartSpan(context.Background(), "handler", trace.WithSampler(trace.AlwaysSample()))
+}
+
+func (suite *Neo4jClientTestSuite) TearDownTest() {
+	suite.exporter.Stop()
+	NewDriverFunc = neo4j.NewDriver
+}
+
+func (suite *Neo4jClientTestSuite) TestShouldBuildDriverFromConfig() {
+	var target string
+	var driverConfig neo4j.Config
+	NewDriverFunc = func(uri string, _ neo4j.AuthToken, configurers ...func(*neo4j.Config)) (neo4j.Driver, error) {
+		target = uri
+		for _, configure := range configurers {
+			configure(&driverConfig)
+		}
+		return suite.driver, nil
+	}
+
+	client, err := NewNeo4jClient(Neo4jConfig{
+		URI:                                   "neo4j://graph:7687",
+		Username:                              "neo4j",
+		Password:                              "secret",
+		MaxConnectionPoolSize:                 20,
+		MaxConnectionLifetimeInSeconds:        300,
+		ConnectionAcquisitionTimeoutInSeconds: 5,
+		SocketConnectTimeoutInSeconds:         2,
+		MaxTransactionRetryTimeInSeconds:      10,
+	})
+
+	suite.Nil(err)
+	suite.NotNil(client)
+	suite.Equal("neo4j://graph:7687", target)
+	suite.Equal(neo4j.Config{
+		MaxConnectionPoolSize:        20,
+		MaxConnectionLifetime:        300 * time.Second,
+		ConnectionAcquisitionTimeout: 5 * time.Second,
+		SocketConnectTimeout:         2 * time.Second,
+		MaxTransactionRetryTime:      10 * time.Second,
+	}, driverConfig)
+}
+
+func (suite *Neo4jClientTestSuite) TestShouldReturnErrorWhenDriverCannotConnect() {
+	suite.driver.connectivity = errors.New("connection refused")
+	NewDriverFunc = func(string, neo4j.AuthToken, ...func(*neo4j.Config)) (neo4j.Driver, error) {
+		return suite.driver, nil
+	}
+
+	client, err := NewNeo4jClient(Neo4jConfig{URI: "bolt://graph:7687"})
+
+	suite.EqualError(err, "connection refused")
+	suite.NotNil(client)
+}
+
+func (suite *Neo4jClientTestSuite) TestShouldReturnErrorWhenDriverCannotBeCreated() {
+	NewDriverFunc = func(string, neo4j.AuthToken, ...func(*neo4j.Config)) (neo4j.Driver, error) {
+		return nil, errors.New("unsupported scheme")
+	}
+
+	client, err := NewNeo4jClient(Neo4jConfig{URI: "http://graph"})
+
+	suite.EqualError(err, "unsupported scheme")
+	suite.Nil(client)
+}
+
+func (suite *Neo4jClientTestSuite) TestShouldTraceReadTransactionAndStatements() {
+	result, err := suite.client.ReadTransaction(suite.ctx, func(tx neo4j.Transaction) (interface{}, error) {
+		_, err := tx.Run("MATCH (d:Draft) RETURN d", nil)
+		return "drafts", err
+	})
+	suite.parent.End()
+
+	suite.Nil(err)
+	suite.Equal("drafts", result)
+	suite.Equal([]neo4j.SessionConfig{{AccessMode: neo4j.AccessModeRead, DatabaseName: "graph"}}, suite.driver.sessionConfigs)
+	suite.True(suite.driver.session.closed)
+	suite.Equal("handler\n  neo4j.ReadTransaction\n    neo4j.run\n", suite.exporter.Trees()[0].String())
+	suite.Equal(map[string]interface{}{
+		SystemAttribute:   "neo4j",
+		DatabaseAttribute: "graph",
+		AttemptsAttribute: int64(1),
+	}, suite.exporter.Attributes(ReadTransactionSpanName))
+	suite.Equal("MATCH (d:Draft) RETURN d", suite.exporter.Attributes(RunSpanName)[StatementAttribute])
+}
+
+func (suite *Neo4jClientTestSuite) TestShouldTakeSpanFromGinContext() {
+	ginContext, _ := gin.CreateTestContext(httptest.NewRecorder())
+	request, _ := http.NewRequest(http.MethodPost, "/api/drafts", nil)
+	ginContext.Request = request.WithContext(suite.ctx)
+
+	_, err := suite.client.WriteTransaction(ginContext, func(tx neo4j.Transaction) (interface{}, error) {
+		return nil, nil
+	})
+	suite.parent.End()
+
+	suite.Nil(err)
+	suite.Equal(neo4j.AccessModeWrite, suite.driver.sessionConfigs[0].AccessMode)
+	span, _ := suite.exporter.Span(WriteTransactionSpanName)
+	suite.Equal(suite.parent.SpanContext().SpanID, span.ParentSpanID)
+}
+
+func (suite *Neo4jClientTestSuite) TestShouldAnnotateRetriedAttempts() {
+	transient := &neo4j.Neo4jError{Code: "Neo.TransientError.Transaction.DeadlockDetected", Msg: "deadlock"}
+	suite.driver.session.tx.errors = []error{transient}
+
+	_, err := suite.client.WriteTransaction(suite.ctx, func(tx neo4j.Transaction) (interface{}, error) {
+		_, err := tx.Run("CREATE (d:Draft)", map[string]interface{}{"title": "draft"})
+		return nil, err
+	})
+
+	suite.Nil(err)
+	suite.Equal(int64(2), suite.exporter.Attributes(WriteTransactionSpanName)[AttemptsAttribute])
+	annotations := suite.exporter.Annotations(WriteTransactionSpanName)
+	suite.Equal("retrying transaction", annotations[0].Message)
+	suite.Equal(int64(2), annotations[0].Attributes["attempt"])
+	runs := suite.exporter.SpansNamed(RunSpanName)
+	suite.Equal(true, runs[0].Attributes[ErrorAttribute])
+	suite.Nil(runs[1].Attributes[ErrorAttribute])
+}
+
+func (suite *Neo4jClientTestSuite) TestShouldMarkFailedTransactionAsError() {
+	_, err := suite.client.WriteTransaction(suite.ctx, func(tx neo4j.Transaction) (interface{}, error) {
+		return nil, errors.New("constraint violated")
+	})
+
+	suite.EqualError(err, "constraint violated")
+	span, _ := suite.exporter.Span(WriteTransactionSpanName)
+	suite.Equal(true, span.Attributes[ErrorAttribute])
+	suite.Equal("constraint violated", span.Message)
+}
+
+func (suite *Neo4jClientTestSuite) TestShouldLogFailedTransactionWithLoggerOfCaller() {
+	hook := logrusTest.NewLocal(logrus.StandardLogger())
+	defer logrus.StandardLogger().ReplaceHooks(make(logrus.LevelHooks))
+	ginContext, _ := gin.CreateTestContext(httptest.NewRecorder())
+	ginContext.Request, _ = http.NewRequest(http.MethodPost, "/api/drafts", nil)
+	ginContext.Set(constants.LOGGER_KEY, logging.NewLoggerEntry().WithField(constants.TRACE_KEY, "trace-1"))
+
+	_, err := suite.client.WriteTransaction(ginContext, func(tx neo4j.Transaction) (interface{}, error) {
+		return nil, errors.New("constraint violated")
+	})
+
+	suite.EqualError(err, "constraint violated")
+	suite.Equal("neo4j client - transaction failed after 1 attempts: constraint violated", hook.LastEntry().Message)
+	suite.Equal("trace-1", hook.LastEntry().Data[constants.TRACE_KEY])
+}
+
+func (suite *Neo4jClientTestSuite) TestShouldCheckHealthAndClose() {
+	suite.Nil(suite.client.HealthCheck())
+	suite.driver.connectivity = errors.New("connection refused")
+	suite.EqualError(suite.client.HealthCheck(), "connection refused")
+
+	suite.Nil(suite.client.Close())
+	suite.True(suite.driver.closed)
+}
@@ -0,0 +1,17 @@
+package neo4j_util
+
+// Neo4jConfig configures the driver of NewNeo4jClient, zero values keep the driver defaults.
+// Without Username the driver connects without authentication.
+type Neo4jConfig struct {
+	URI                                   string `json:"uri"`
+	Username                              string `json:"username"`
+	Password                              string `json:"password"`
+	Realm                                 string `json:"realm"`
+	Database                              string `json:"database"`
+	MaxConnectionPoolSize                 int    `json:"max_connection_pool_size"`
+	MaxConnectionLifetimeInSeconds        int    `json:"max_connection_lifetime_in_seconds"`
+	ConnectionAcquisitionTimeoutInSeconds int    `json:"connection_acquisition_timeout_in_seconds"`
+	SocketConnectTimeoutInSeconds         int    `json:"socket_connect_timeout_in_seconds"`
+	MaxTransactionRetryTimeInSeconds      int    `json:"max_transaction_retry_time_in_seconds"`
+	RedactParameters                      bool   `json:"redact_parameters"`
+}
@@ -0,0 +1,189 @@
+package neo4j_util
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/neo4j/neo4j-go-driver/v4/neo4j"
+)
+
+const tagName = "neo4j"
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// CollectInto maps every remaining record of result to a new element of dest, a pointer
+// to a slice, see ScanRecord.
+func CollectInto(result neo4j.Result, dest interface{}) error {
+	slice := reflect.ValueOf(dest)
+	if slice.Kind() != reflect.Ptr || slice.Elem().Kind() != reflect.Slice {
+		return fmt.Errorf("neo4j result destination must be a pointer to a slice, got %T", dest)
+	}
+	slice = slice.Elem()
+	elementType := slice.Type().Elem()
+	for result.Next() {
+		element := reflect.New(elementType)
+		if err := ScanRecord(result.Record(), element.Interface()); err != nil {
+			return err
+		}
+		slice.Set(reflect.Append(slice, element.Elem()))
+	}
+	return result.Err()
+}
+
+// ScanRecord maps record to dest, a pointer. Struct fields are set from the value of the
+// key named by their neo4j tag, their json tag or their name, in that order. The properties
+// of a single node, relationship or map returned by the record are mapped as well, values
+// of the record's own keys take precedence. Other types are set from the only value of
+// the record.
+func ScanRecord(record *neo4j.Record, dest interface{}) error {
+	target := reflect.ValueOf(dest)
+	if target.Kind() != reflect.Ptr || target.IsNil() {
+		return fmt.Errorf("neo4j record destination must be a non-nil pointer, got %T", dest)
+	}
+	target = target.Elem()
+	for target.Kind() == reflect.Ptr {
+		if target.IsNil() {
+			target.Set(reflect.New(target.Type().Elem()))
+		}
+		target = target.Elem()
+	}
+	if target.Kind() != reflect.Struct || target.Type() == timeType {
+		if len(record.Values) != 1 {
+			return fmt.Errorf("neo4j record with %d values cannot be mapped to %s", len(record.Values), target.Type())
+		}
+		return assign(record.Values[0], target, record.Keys[0])
+	}
+	values := map[string]interface{}{}
+	if len(record.Values) == 1 {
+		if properties, ok := propertiesOf(record.Values[0]); ok {
+			values = properties
+		}
+	}
+	for i, key := range record.Keys {
+		values[key] = record.Values[i]
+	}
+	return assignFields(values, target)
+}
+
+func propertiesOf(value interface{}) (map[string]interface{}, bool) {
+	switch value := value.(type) {
+	case neo4j.Node:
+		return value.Props, true
+	case neo4j.Relationship:
+		return value.Props, true
+	case map[string]interface{}:
+		return value, true
+	}
+	return nil, false
+}
+
+func assignFields(values map[string]interface{}, target reflect.Value) error {
+	for i := 0; i < target.NumField(); i++ {
+		field := target.Type().Field(i)
+		name := fieldName(field)
+		if field.PkgPath != "" || name == "-" {
+			continue
+		}
+		if value, ok := values[name]; ok {
+			if err := assign(value, target.Field(i), name); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func fieldName(field reflect.StructField) string {
+	for _, tag := range []string{tagName, "json"} {
+		if name := strings.Split(field.Tag.Get(tag), ",")[0]; name != "" {
+			return name
+		}
+	}
+	return field.Name
+}
+
+// assign sets target to value, converting numbers, temporal types, lists and properties.
+func assign(value interface{}, target reflect.Value, name string) error {
+	if value == nil {
+		target.Set(reflect.Zero(target.Type()))
+		return nil
+	}
+	if target.Kind() == reflect.Ptr {
+		element := reflect.New(target.Type().Elem())
+		if err := assign(value, element.Elem(), name); err != nil {
+			return err
+		}
+		target.Set(element)
+		return nil
+	}
+	source := reflect.ValueOf(value)
+	switch {
+	case source.Type().AssignableTo(target.Type()):
+		target.Set(source)
+		return nil
+	case isNumber(source.Kind()) && isNumber(target.Kind()):
+		converted, ok := convertNumber(source, target.Type())
+		if !ok {
+			return fmt.Errorf("neo4j value %s of %v does not fit into %s", name, value, target.Type())
+		}
+		target.Set(converted)
+		return nil
+	case source.Kind() == reflect.Struct && target.Type() == timeType && source.Type().ConvertibleTo(timeType):
+		target.Set(source.Convert(target.Type()))
+		return nil
+	case target.Kind() == reflect.Struct:
+		if properties, ok := propertiesOf(value); ok {
+			return assignFields(properties, target)
+		}
+	case target.Kind() == reflect.Slice && source.Kind() == reflect.Slice:
+		slice := reflect.MakeSlice(target.Type(), source.Len(), source.Len())
+		for i := 0; i < source.Len(); i++ {
+			if err := assign(source.Index(i).Interface(), slice.Index(i), name); err != nil {
+				return err
+			}
+		}
+		target.Set(slice)
+		return nil
+	}
+	return fmt.Errorf("cannot map neo4j value %s of type %T to %s", name, value, target.Type())
+}
+
+// convertNumber converts source to target, it fails when the value overflows target,
+// changes its sign or loses its fraction. Floats may lose precision to float32.
+func convertNumber(source reflect.Value, target reflect.Type) (reflect.Value, bool) {
+	converted := source.Convert(target)
+	if isNegative(source) != isNegative(converted) {
+		return reflect.Value{}, false
+	}
+	if isFloat(source.Kind()) && isFloat(target.Kind()) {
+		return converted, math.IsInf(converted.Float(), 0) == math.IsInf(source.Float(), 0)
+	}
+	return converted, converted.Convert(source.Type()).Interface() == source.Interface()
+}
+
+func isNegative(value reflect.Value) bool {
+	switch {
+	case isFloat(value.Kind()):
+		return value.Float() < 0
+	case value.Kind() >= reflect.Int && value.Kind() <= reflect.Int64:
+		return value.Int() < 0
+	}
+	return false
+}
+
+func isFloat(kind reflect.Kind) bool {
+	return kind == reflect.Float32 || kind == reflect.Float64
+}
+
+func isNumber(kind reflect.Kind) bool {
+	switch kind {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,170 @@
+package neo4j_util
+
+import (
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/neo4j/neo4j-go-driver/v4/neo4j"
+	"github.com/stretchr/testify/suite"
+)
+
+type author struct {
+	Name string `json:"name"`
+}
+
+type draft struct {
+	ID        int       `neo4j:"draftId"`
+	Title     string    `json:"title"`
+	Tags      []string  `json:"tags"`
+	Rating    float32   `json:"rating"`
+	CreatedAt time.Time `json:"createdAt"`
+	Author    *author   `json:"author"`
+	Ignored   string    `neo4j:"-"`
+}
+
+type fakeResult struct {
+	neo4j.Result
+	records []*neo4j.Record
+	current *neo4j.Record
+	err     error
+}
+
+func (r *fakeResult) Next() bool {
+	if len(r.records) == 0 {
+		return false
+	}
+	r.current, r.records = r.records[0], r.records[1:]
+	return true
+}
+
+func (r *fakeResult) Record() *neo4j.Record {
+	return r.current
+}
+
+func (r *fakeResult) Err() error {
+	return r.err
+}
+
+type RecordMapperTestSuite struct {
+	suite.Suite
+}
+
+func TestRecordMapperTestSuite(t *testing.T) {
+	suite.Run(t, new(RecordMapperTestSuite))
+}
+
+func (suite *RecordMapperTestSuite) TestShouldMapRecordKeysToFields() {
+	createdAt := time.Date(2020, 9, 1, 10, 0, 0, 0, time.UTC)
+	record := &neo4j.Record{
+		Keys: []string{"draftId", "title", "tags", "rating", "createdAt", "author", "Ignored"},
+		Values: []interface{}{
+			int64(7), "Graphs", []interface{}{"go", "neo4j"}, 4.5, neo4j.LocalDateTime(createdAt),
+			neo4j.Node{Props: map[string]interface{}{"name": "Ada"}}, "value",
+		},
+	}
+	var mapped draft
+
+	err := ScanRecord(record, &mapped)
+
+	suite.Nil(err)
+	suite.Equal(draft{ID: 7, Title: "Graphs", Tags: []string{"go", "neo4j"}, Rating: 4.5, CreatedAt: createdAt, Author: &author{Name: "Ada"}}, mapped)
+}
+
+func (suite *RecordMapperTestSuite) TestShouldMapPropertiesOfSingleNode() {
+	record := &neo4j.Record{
+		Keys:   []string{"d"},
+		Values: []interface{}{neo4j.Node{Labels: []string{"Draft"}, Props: map[string]interface{}{"draftId": int64(7), "title": "Graphs", "author": nil}}},
+	}
+	var mapped draft
+
+	err := ScanRecord(record, &mapped)
+
+	suite.Nil(err)
+	suite.Equal(draft{ID: 7, Title: "Graphs"}, mapped)
+}
+
+func (suite *RecordMapperTestSuite) TestShouldMapSingleValueToScalar() {
+	var count int
+
+	err := ScanRecord(&neo4j.Record{Keys: []string{"count(d)"}, Values: []interface{}{int64(3)}}, &count)
+
+	suite.Nil(err)
+	suite.Equal(3, count)
+}
+
+func (suite *RecordMapperTestSuite) TestShouldReturnErrorForIncompatibleValues() {
+	var mapped draft
+	err := ScanRecord(&neo4j.Record{Keys: []string{"title", "rating"}, Values: []interface{}{int64(1), 1.0}}, &mapped)
+	suite.EqualError(err, "cannot map neo4j value title of type int64 to string")
+
+	var count int
+	err = ScanRecord(&neo4j.Record{Keys: []string{"a", "b"}, Values: []interface{}{int64(1), int64(2)}}, &count)
+	suite.EqualError(err, "neo4j record with 2 values cannot be mapped to int")
+
+	err = ScanRecord(&neo4j.Record{}, mapped)
+	suite.EqualError(err, "neo4j record destination must be a non-nil pointer, got neo4j_util.draft")
+}
+
+func (suite *RecordMapperTestSuite) TestShouldReturnErrorForNumbersNotFittingTarget() {
+	var small int8
+	err := ScanRecord(&neo4j.Record{Keys: []string{"count"}, Values: []interface{}{int64(300)}}, &small)
+	suite.EqualError(err, "neo4j value count of 300 does not fit into int8")
+
+	var unsigned uint
+	err = ScanRecord(&neo4j.Record{Keys: []string{"count"}, Values: []interface{}{int64(-1)}}, &unsigned)
+	suite.EqualError(err, "neo4j value count of -1 does not fit into uint")
+
+	var count int
+	err = ScanRecord(&neo4j.Record{Keys: []string{"count"}, Values: []interface{}{2.5}}, &count)
+	suite.EqualError(err, "neo4j value count of 2.5 does not fit into int")
+
+	var signed int64
+	err = ScanRecord(&neo4j.Record{Keys: []string{"count"}, Values: []interface{}{uint64(math.MaxUint64)}}, &signed)
+	suite.EqualError(err, "neo4j value count of 18446744073709551615 does not fit into int64")
+
+	var rating float32
+	err = ScanRecord(&neo4j.Record{Keys: []string{"rating"}, Values: []interface{}{math.MaxFloat64}}, &rating)
+	suite.EqualError(err, "neo4j value rating of 1.7976931348623157e+308 does not fit into float32")
+}
+
+func (suite *RecordMapperTestSuite) TestShouldConvertNumbersFittingTarget() {
+	var mapped struct {
+		Count  int8    `json:"count"`
+		Total  uint    `json:"total"`
+		Rating float32 `json:"rating"`
+		Score  int     `json:"score"`
+	}
+
+	err := ScanRecord(&neo4j.Record{Keys: []string{"count", "total", "rating", "score"}, Values: []interface{}{int64(-128), int64(42), 4.1, 3.0}}, &mapped)
+
+	suite.Nil(err)
+	suite.Equal(int8(-128), mapped.Count)
+	suite.Equal(uint(42), mapped.Total)
+	suite.Equal(float32(4.1), mapped.Rating)
+	suite.Equal(3, mapped.Score)
+}
+
+func (suite *RecordMapperTestSuite) TestShouldCollectRecordsIntoSlice() {
+	result := &fakeResult{records: []*neo4j.Record{
+		{Keys: []string{"title"}, Values: []interface{}{"first"}},
+		{Keys: []string{"title"}, Values: []interface{}{"second"}},
+	}}
+	var drafts []*draft
+
+	err := CollectInto(result, &drafts)
+
+	suite.Nil(err)
+	suite.Equal([]*draft{{Title: "first"}, {Title: "second"}}, drafts)
+}
+
+func (suite *RecordMapperTestSuite) TestShouldReturnResultErrorWhenCollecting() {
+	var titles []string
+
+	err := CollectInto(&fakeResult{err: errors.New("stream failed")}, &titles)
+	suite.EqualError(err, "stream failed")
+
+	err = CollectInto(&fakeResult{}, titles)
+	suite.EqualError(err, "neo4j result destination must be a pointer to a slice, got []string")
+}
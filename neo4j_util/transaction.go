@@ -0,0 +1,60 @@
+package neo4j_util
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/neo4j/neo4j-go-driver/v4/neo4j"
+	"go.opencensus.io/trace"
+)
+
+const (
+	RunSpanName         = "neo4j.run"
+	StatementAttribute  = "db.statement"
+	ParametersAttribute = "db.neo4j.parameters"
+
+	redactedValue = "****"
+)
+
+// tracedTransaction traces every statement in a child span of the transaction span. The
+// span ends once the statement is accepted, records are streamed afterwards.
+type tracedTransaction struct {
+	neo4j.Transaction
+	ctx              context.Context
+	database         string
+	redactParameters bool
+}
+
+func (tx tracedTransaction) Run(cypher string, params map[string]interface{}) (neo4j.Result, error) {
+	_, span := trace.StartSpan(tx.ctx, RunSpanName, trace.WithSpanKind(trace.SpanKindClient))
+	defer span.End()
+	span.AddAttributes(
+		trace.StringAttribute(SystemAttribute, neo4jSystem),
+		trace.StringAttribute(DatabaseAttribute, tx.database),
+		trace.StringAttribute(StatementAttribute, cypher),
+	)
+	if len(params) > 0 {
+		span.AddAttributes(trace.StringAttribute(ParametersAttribute, encodeParameters(params, tx.redactParameters)))
+	}
+	result, err := tx.Transaction.Run(cypher, params)
+	if err != nil {
+		setError(span, err)
+	}
+	return result, err
+}
+
+// encodeParameters encodes params as JSON, with redact only their names are kept.
+func encodeParameters(params map[string]interface{}, redact bool) string {
+	if redact {
+		redacted := make(map[string]interface{}, len(params))
+		for name := range params {
+			redacted[name] = redactedValue
+		}
+		params = redacted
+	}
+	encoded, err := json.Marshal(params)
+	if err != nil {
+		return err.Error()
+	}
+	return string(encoded)
+}
@@ -0,0 +1,59 @@
+package neo4j_util
+
+import (
+	"context"
+	"testing"
+
+	"github.com/inclusi-blog/gola-utils/tracing/tracetest"
+	"github.com/stretchr/testify/suite"
+	"go.opencensus.io/trace"
+)
+
+type TransactionTestSuite struct {
+	suite.Suite
+	exporter *tracetest.Exporter
+	ctx      context.Context
+}
+
+func TestTransactionTestSuite(t *testing.T) {
+	suite.Run(t, new(TransactionTestSuite))
+}
+
+func (suite *TransactionTestSuite) SetupTest() {
+	suite.exporter = tracetest.Start()
+	suite.ctx, _ = trace.StartSpan(context.Background(), "transaction", trace.WithSampler(trace.AlwaysSample()))
+}
+
+func (suite *TransactionTestSuite) TearDownTest() {
+	suite.exporter.Stop()
+}
+
+func (suite *TransactionTestSuite) TestShouldTraceStatementWithParameters() {
+	tx := tracedTransaction{Transaction: &fakeTransaction{}, ctx: suite.ctx, database: "graph"}
+
+	_, err := tx.Run("MATCH (d:Draft {id: $id}) RETURN d", map[string]interface{}{"id": 7, "email": "ada@gola.xyz"})
+
+	suite.Nil(err)
+	suite.Equal(map[string]interface{}{
+		SystemAttribute:     "neo4j",
+		DatabaseAttribute:   "graph",
+		StatementAttribute:  "MATCH (d:Draft {id: $id}) RETURN d",
+		ParametersAttribute: `{"email":"ada@gola.xyz","id":7}`,
+	}, suite.exporter.Attributes(RunSpanName))
+}
+
+func (suite *TransactionTestSuite) TestShouldRedactParameters() {
+	tx := tracedTransaction{Transaction: &fakeTransaction{}, ctx: suite.ctx, redactParameters: true}
+
+	_, _ = tx.Run("MATCH (u:User {email: $email}) RETURN u", map[string]interface{}{"email": "ada@gola.xyz"})
+
+	suite.Equal(`{"email":"****"}`, suite.exporter.Attributes(RunSpanName)[ParametersAttribute])
+}
+
+func (suite *TransactionTestSuite) TestShouldOmitEmptyParameters() {
+	tx := tracedTransaction{Transaction: &fakeTransaction{}, ctx: suite.ctx}
+
+	_, _ = tx.Run("MATCH (d:Draft) RETURN count(d)", nil)
+
+	suite.NotContains(suite.exporter.Attributes(RunSpanName), ParametersAttribute)
+}
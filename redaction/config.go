@@ -0,0 +1,41 @@
+package redaction
+
+type MaskStrategy string
+
+const (
+	FullMask    MaskStrategy = "full"
+	PartialMask MaskStrategy = "partial"
+	Hash        MaskStrategy = "hash"
+	Drop        MaskStrategy = "drop"
+)
+
+const (
+	defaultRevealLast = 4
+	maskChar          = "*"
+	fullMaskValue     = "****"
+	hashPrefix        = "sha256:"
+	nonJSONMessage    = "Payload not logged as it is not a JSON document"
+	notLoggedPrefix   = "body not logged: "
+)
+
+// Rule selects fields with a JSON path and redacts them with the given strategy.
+//
+// Paths starting with "$" are anchored at the document root ("$.password"). Other
+// paths match at any depth ("card.number" matches "$.card.number" and
+// "$.payment.card.number"), so a leading "*" matches zero or more levels ("*.email"
+// matches "$.email" too). Each segment is a glob matched against the field name, "*"
+// matches exactly one level and "**" matches any number of levels. Array indices
+// ("items[*].price" or "items[0].price") are ignored, rules apply to every element.
+type Rule struct {
+	Path        string       `json:"path" validate:"required"`
+	Strategy    MaskStrategy `json:"strategy" validate:"required,oneof=full partial hash drop"`
+	RevealFirst int          `json:"revealFirst"`
+	RevealLast  int          `json:"revealLast"`
+}
+
+type Config struct {
+	Rules []Rule `json:"rules" validate:"dive"`
+	// FailOnNonJSON makes Redact return ErrNotJSON for payloads that are not JSON
+	// documents, which are replaced by a placeholder otherwise.
+	FailOnNonJSON bool `json:"failOnNonJSON"`
+}
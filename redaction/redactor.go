@@ -0,0 +1,232 @@
+package redaction
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+)
+
+const anyDepth = "**"
+
+var ErrNotJSON = errors.New("payload is not a valid JSON document")
+
+type Redactor interface {
+	Redact(payload []byte) ([]byte, error)
+	TraceHook(payload []byte) (string, error)
+}
+
+type compiledRule struct {
+	Rule
+	segments []string
+}
+
+type redactor struct {
+	rules         []compiledRule
+	failOnNonJSON bool
+}
+
+func NewRedactor(config Config) (Redactor, error) {
+	rules := make([]compiledRule, 0, len(config.Rules))
+	for _, rule := range config.Rules {
+		compiled, err := compileRule(rule)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, compiled)
+	}
+	return redactor{rules: rules, failOnNonJSON: config.FailOnNonJSON}, nil
+}
+
+func compileRule(rule Rule) (compiledRule, error) {
+	switch rule.Strategy {
+	case FullMask, PartialMask, Hash, Drop:
+	default:
+		return compiledRule{}, fmt.Errorf("not a valid redaction strategy: %q", rule.Strategy)
+	}
+	if rule.RevealFirst < 0 || rule.RevealLast < 0 {
+		return compiledRule{}, fmt.Errorf("reveal counts must not be negative for path %q", rule.Path)
+	}
+
+	rawPath := strings.TrimSpace(rule.Path)
+	anchored := strings.HasPrefix(rawPath, "$")
+	rawPath = strings.TrimPrefix(rawPath, "$")
+
+	var segments []string
+	if !anchored {
+		segments = append(segments, anyDepth)
+	}
+	for _, segment := range strings.Split(rawPath, ".") {
+		if index := strings.Index(segment, "["); index >= 0 {
+			segment = segment[:index]
+		}
+		if segment == "" {
+			continue
+		}
+		if _, err := path.Match(segment, ""); err != nil {
+			return compiledRule{}, fmt.Errorf("invalid redaction path %q: %v", rule.Path, err)
+		}
+		segments = append(segments, segment)
+	}
+	if !anchored {
+		// A leading "*" of an unanchored path is already covered by matching at any
+		// depth, so "*.email" also matches a top level "email".
+		for len(segments) > 2 && segments[1] == "*" {
+			segments = append(segments[:1], segments[2:]...)
+		}
+	}
+	if len(segments) == 0 || segments[len(segments)-1] == anyDepth {
+		return compiledRule{}, fmt.Errorf("redaction path %q does not select a field", rule.Path)
+	}
+	return compiledRule{Rule: rule, segments: segments}, nil
+}
+
+// Redact applies the configured rules to a JSON payload. Empty payloads are returned
+// as is, anything else that is not JSON is replaced by a placeholder, or yields ErrNotJSON
+// with FailOnNonJSON.
+func (r redactor) Redact(payload []byte) ([]byte, error) {
+	if len(bytes.TrimSpace(payload)) == 0 {
+		return payload, nil
+	}
+	if !json.Valid(payload) {
+		if r.failOnNonJSON {
+			return nil, ErrNotJSON
+		}
+		return []byte(nonJSONMessage), nil
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(payload))
+	decoder.UseNumber()
+	var document interface{}
+	if err := decoder.Decode(&document); err != nil {
+		return nil, err
+	}
+
+	var buffer bytes.Buffer
+	encoder := json.NewEncoder(&buffer)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(r.walk(document, nil)); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(buffer.Bytes(), []byte("\n")), nil
+}
+
+// TraceHook matches request.TraceHookFunc so a redactor can be used directly as a
+// request or response trace hook. Callers log NotLogged(err) when it fails.
+func (r redactor) TraceHook(payload []byte) (string, error) {
+	redacted, err := r.Redact(payload)
+	if err != nil {
+		return "", err
+	}
+	return string(redacted), nil
+}
+
+// NotLogged returns the placeholder logged instead of a body a trace hook failed on, the
+// body itself is never logged as it may hold the very fields the hook redacts.
+func NotLogged(err error) string {
+	return notLoggedPrefix + err.Error()
+}
+
+func (r redactor) walk(value interface{}, fieldPath []string) interface{} {
+	switch typedValue := value.(type) {
+	case map[string]interface{}:
+		for key, child := range typedValue {
+			childPath := make([]string, len(fieldPath), len(fieldPath)+1)
+			copy(childPath, fieldPath)
+			childPath = append(childPath, key)
+
+			if rule, found := r.matchingRule(childPath); found {
+				if rule.Strategy == Drop {
+					delete(typedValue, key)
+				} else {
+					typedValue[key] = rule.apply(child)
+				}
+				continue
+			}
+			typedValue[key] = r.walk(child, childPath)
+		}
+		return typedValue
+	case []interface{}:
+		for index, child := range typedValue {
+			typedValue[index] = r.walk(child, fieldPath)
+		}
+		return typedValue
+	default:
+		return value
+	}
+}
+
+func (r redactor) matchingRule(fieldPath []string) (compiledRule, bool) {
+	for _, rule := range r.rules {
+		if matchSegments(rule.segments, fieldPath) {
+			return rule, true
+		}
+	}
+	return compiledRule{}, false
+}
+
+func matchSegments(pattern []string, fieldPath []string) bool {
+	if len(pattern) == 0 {
+		return len(fieldPath) == 0
+	}
+	if pattern[0] == anyDepth {
+		for skip := 0; skip <= len(fieldPath); skip++ {
+			if matchSegments(pattern[1:], fieldPath[skip:]) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(fieldPath) == 0 {
+		return false
+	}
+	if matched, _ := path.Match(pattern[0], fieldPath[0]); !matched {
+		return false
+	}
+	return matchSegments(pattern[1:], fieldPath[1:])
+}
+
+func (rule compiledRule) apply(value interface{}) interface{} {
+	text := stringValue(value)
+	switch rule.Strategy {
+	case PartialMask:
+		return partialMask(text, rule.RevealFirst, rule.RevealLast)
+	case Hash:
+		sum := sha256.Sum256([]byte(text))
+		return hashPrefix + hex.EncodeToString(sum[:])
+	default:
+		return fullMaskValue
+	}
+}
+
+func stringValue(value interface{}) string {
+	switch typedValue := value.(type) {
+	case string:
+		return typedValue
+	case json.Number:
+		return typedValue.String()
+	default:
+		encoded, err := json.Marshal(typedValue)
+		if err != nil {
+			return fmt.Sprint(typedValue)
+		}
+		return string(encoded)
+	}
+}
+
+func partialMask(text string, revealFirst, revealLast int) string {
+	if revealFirst == 0 && revealLast == 0 {
+		revealLast = defaultRevealLast
+	}
+	runes := []rune(text)
+	if revealFirst+revealLast >= len(runes) {
+		return fullMaskValue
+	}
+	return string(runes[:revealFirst]) +
+		strings.Repeat(maskChar, len(runes)-revealFirst-revealLast) +
+		string(runes[len(runes)-revealLast:])
+}
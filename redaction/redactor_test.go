@@ -0,0 +1,135 @@
+package redaction
+
+import (
+	"github.com/stretchr/testify/suite"
+	"testing"
+)
+
+type RedactorTestSuite struct {
+	suite.Suite
+}
+
+func TestRedactorTestSuite(t *testing.T) {
+	suite.Run(t, new(RedactorTestSuite))
+}
+
+func (suite RedactorTestSuite) newRedactor(rules ...Rule) Redactor {
+	redactor, err := NewRedactor(Config{Rules: rules})
+	suite.Nil(err)
+	return redactor
+}
+
+func (suite RedactorTestSuite) TestShouldFullyMaskAnchoredRootField() {
+	redactor := suite.newRedactor(Rule{Path: "$.password", Strategy: FullMask})
+
+	actual, err := redactor.Redact([]byte(`{"password":"secret","user":{"password":"nested"}}`))
+
+	suite.Nil(err)
+	suite.JSONEq(`{"password":"****","user":{"password":"nested"}}`, string(actual))
+}
+
+func (suite RedactorTestSuite) TestShouldMatchUnanchoredPathAtAnyDepth() {
+	redactor := suite.newRedactor(Rule{Path: "card.number", Strategy: PartialMask})
+
+	actual, err := redactor.Redact([]byte(`{"card":{"number":"4111111111111111"},"payment":{"card":{"number":"5500000000000004"}}}`))
+
+	suite.Nil(err)
+	suite.JSONEq(`{"card":{"number":"************1111"},"payment":{"card":{"number":"************0004"}}}`, string(actual))
+}
+
+func (suite RedactorTestSuite) TestShouldMatchWildcardSegmentInsideArrays() {
+	redactor := suite.newRedactor(Rule{Path: "*.email", Strategy: PartialMask, RevealFirst: 1, RevealLast: 4})
+
+	actual, err := redactor.Redact([]byte(`{"users":[{"email":"abc@gola.com"},{"email":"xyz@gola.com"}]}`))
+
+	suite.Nil(err)
+	suite.JSONEq(`{"users":[{"email":"a*******.com"},{"email":"x*******.com"}]}`, string(actual))
+}
+
+func (suite RedactorTestSuite) TestShouldMatchLeadingWildcardOfUnanchoredPathAtTopLevel() {
+	redactor := suite.newRedactor(Rule{Path: "*.email", Strategy: PartialMask, RevealFirst: 1, RevealLast: 4})
+
+	actual, err := redactor.Redact([]byte(`{"email":"root@gola.com","user":{"contact":{"email":"abc@gola.com"}}}`))
+
+	suite.Nil(err)
+	suite.JSONEq(`{"email":"r********.com","user":{"contact":{"email":"a*******.com"}}}`, string(actual))
+}
+
+func (suite RedactorTestSuite) TestShouldKeepWildcardOfAnchoredPath() {
+	redactor := suite.newRedactor(Rule{Path: "$.*.email", Strategy: FullMask})
+
+	actual, err := redactor.Redact([]byte(`{"email":"root@gola.com","user":{"email":"abc@gola.com"}}`))
+
+	suite.Nil(err)
+	suite.JSONEq(`{"email":"root@gola.com","user":{"email":"****"}}`, string(actual))
+}
+
+func (suite RedactorTestSuite) TestShouldHashAndDropFields() {
+	redactor := suite.newRedactor(
+		Rule{Path: "$.userId", Strategy: Hash},
+		Rule{Path: "token*", Strategy: Drop},
+	)
+
+	actual, err := redactor.Redact([]byte(`{"userId":"abc","tokens":{"access":"a"},"data":{"tokenHash":"b","amount":10.50}}`))
+
+	suite.Nil(err)
+	suite.JSONEq(`{"userId":"sha256:ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad","data":{"amount":10.50}}`, string(actual))
+}
+
+func (suite RedactorTestSuite) TestShouldFullyMaskWhenValueIsShorterThanRevealedCharacters() {
+	redactor := suite.newRedactor(Rule{Path: "pin", Strategy: PartialMask})
+
+	actual, err := redactor.Redact([]byte(`{"pin":1234}`))
+
+	suite.Nil(err)
+	suite.Equal(`{"pin":"****"}`, string(actual))
+}
+
+func (suite RedactorTestSuite) TestShouldNotEscapeHTMLCharacters() {
+	redactor := suite.newRedactor(Rule{Path: "password", Strategy: FullMask})
+
+	actual, err := redactor.Redact([]byte(`{"name":"a & b <c>","password":"x"}`))
+
+	suite.Nil(err)
+	suite.Equal(`{"name":"a & b <c>","password":"****"}`, string(actual))
+}
+
+func (suite RedactorTestSuite) TestShouldReturnEmptyPayloadAsIs() {
+	redactor := suite.newRedactor(Rule{Path: "password", Strategy: FullMask})
+
+	actual, err := redactor.Redact([]byte{})
+
+	suite.Nil(err)
+	suite.Empty(actual)
+}
+
+func (suite RedactorTestSuite) TestShouldReplaceNonJSONPayloadByDefault() {
+	redactor := suite.newRedactor(Rule{Path: "password", Strategy: FullMask})
+
+	for _, payload := range []string{`user=ada&password=secret`, `<login><password>secret</password></login>`, `password secret`} {
+		actual, err := redactor.TraceHook([]byte(payload))
+
+		suite.Nil(err)
+		suite.Equal(nonJSONMessage, actual)
+	}
+}
+
+func (suite RedactorTestSuite) TestShouldReturnErrorForNonJSONPayloadWhenConfigured() {
+	redactor, _ := NewRedactor(Config{FailOnNonJSON: true})
+
+	_, err := redactor.TraceHook([]byte(`password=secret`))
+
+	suite.Equal(ErrNotJSON, err)
+	suite.Equal("body not logged: payload is not a valid JSON document", NotLogged(err))
+}
+
+func (suite RedactorTestSuite) TestShouldReturnErrorForInvalidRules() {
+	_, err := NewRedactor(Config{Rules: []Rule{{Path: "password", Strategy: "encrypt"}}})
+	suite.EqualError(err, `not a valid redaction strategy: "encrypt"`)
+
+	_, err = NewRedactor(Config{Rules: []Rule{{Path: "$", Strategy: FullMask}}})
+	suite.EqualError(err, `redaction path "$" does not select a field`)
+
+	_, err = NewRedactor(Config{Rules: []Rule{{Path: `user.name\`, Strategy: FullMask}}})
+	suite.NotNil(err)
+}
@@ -0,0 +1,181 @@
+package redis_util
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-redis/redis/v7"
+	"go.opencensus.io/stats"
+	"go.opencensus.io/stats/view"
+	"go.opencensus.io/tag"
+	"go.opencensus.io/trace"
+)
+
+const (
+	SpanNamePrefix        = "redis."
+	PipelineCommand       = "pipeline"
+	SystemAttribute       = "db.system"
+	CommandAttribute      = "db.operation"
+	DBAttribute           = "db.redis.database_index"
+	KeyAttribute          = "db.redis.key"
+	PipelineSizeAttribute = "db.redis.pipeline_size"
+	ErrorAttribute        = "error"
+
+	StatusOK    = "ok"
+	StatusMiss  = "miss"
+	StatusError = "error"
+
+	redisSystem  = "redis"
+	keySeparator = ":"
+	redactedPart = "*"
+)
+
+var (
+	// LatencyMeasure records the latency of every command and pipeline in milliseconds.
+	LatencyMeasure = stats.Float64("gola/redis/client/latency", "Latency of redis commands", stats.UnitMilliseconds)
+	CommandKey     = tag.MustNewKey("redis_command")
+	StatusKey      = tag.MustNewKey("redis_status")
+
+	// LatencyView distributes LatencyMeasure per command and status, register it with
+	// view.Register to export it.
+	LatencyView = &view.View{
+		Name:        "gola/redis/client/latency",
+		Description: "Latency distribution of redis commands",
+		Measure:     LatencyMeasure,
+		Aggregation: view.Distribution(0.5, 1, 2, 5, 10, 25, 50, 100, 250, 500, 1000, 2500, 5000),
+		TagKeys:     []tag.Key{CommandKey, StatusKey},
+	}
+)
+
+// commands without a key as first argument
+var keylessCommands = map[string]bool{
+	"auth": true, "client": true, "cluster": true, "command": true, "config": true, "dbsize": true,
+	"discard": true, "echo": true, "exec": true, "flushall": true, "flushdb": true, "info": true,
+	"multi": true, "ping": true, "quit": true, "script": true, "select": true, "time": true,
+	"publish": true, "pubsub": true, "subscribe": true, "unsubscribe": true, "psubscribe": true,
+	"punsubscribe": true,
+}
+
+// scripting commands with the script or its SHA as first argument, followed by the number
+// of keys and the keys
+var scriptCommands = map[string]bool{"eval": true, "evalsha": true, "eval_ro": true, "evalsha_ro": true}
+
+type startTimeKey struct{}
+
+type instrumentationHook struct {
+	db         int
+	redactKeys bool
+}
+
+// NewInstrumentationHook returns a hook tracing every command and pipeline of a client in
+// a span of the context the client runs with, see redis.Client.WithContext, and recording
+// LatencyMeasure. With redactKeys only the first segment of keys like "session:1234" is
+// kept, as "session:*", keys without segments are replaced by "*".
+func NewInstrumentationHook(db int, redactKeys bool) redis.Hook {
+	return instrumentationHook{db: db, redactKeys: redactKeys}
+}
+
+func (h instrumentationHook) BeforeProcess(ctx context.Context, cmd redis.Cmder) (context.Context, error) {
+	ctx, span := h.start(ctx, cmd.Name())
+	if key, ok := keyOf(cmd); ok {
+		span.AddAttributes(trace.StringAttribute(KeyAttribute, h.redact(key)))
+	}
+	return ctx, nil
+}
+
+func (h instrumentationHook) AfterProcess(ctx context.Context, cmd redis.Cmder) error {
+	end(ctx, cmd.Name(), cmd.Err())
+	return nil
+}
+
+func (h instrumentationHook) BeforeProcessPipeline(ctx context.Context, cmds []redis.Cmder) (context.Context, error) {
+	ctx, span := h.start(ctx, PipelineCommand)
+	span.AddAttributes(trace.Int64Attribute(PipelineSizeAttribute, int64(len(cmds))))
+	return ctx, nil
+}
+
+// AfterProcessPipeline ends the pipeline span with the first error of its commands.
+func (h instrumentationHook) AfterProcessPipeline(ctx context.Context, cmds []redis.Cmder) error {
+	var err error
+	for _, cmd := range cmds {
+		if cmd.Err() != nil && cmd.Err() != redis.Nil {
+			err = cmd.Err()
+			break
+		}
+	}
+	end(ctx, PipelineCommand, err)
+	return nil
+}
+
+func (h instrumentationHook) start(ctx context.Context, command string) (context.Context, *trace.Span) {
+	ctx, span := trace.StartSpan(ctx, SpanNamePrefix+command, trace.WithSpanKind(trace.SpanKindClient))
+	span.AddAttributes(
+		trace.StringAttribute(SystemAttribute, redisSystem),
+		trace.StringAttribute(CommandAttribute, command),
+		trace.Int64Attribute(DBAttribute, int64(h.db)),
+	)
+	return context.WithValue(ctx, startTimeKey{}, time.Now()), span
+}
+
+// end ends the span of ctx, a missing key is not treated as error.
+func end(ctx context.Context, command string, err error) {
+	span := trace.FromContext(ctx)
+	status := StatusOK
+	switch {
+	case err == redis.Nil:
+		status = StatusMiss
+	case err != nil:
+		status = StatusError
+		span.AddAttributes(trace.BoolAttribute(ErrorAttribute, true))
+		span.SetStatus(trace.Status{Code: trace.StatusCodeUnknown, Message: err.Error()})
+	}
+	if start, ok := ctx.Value(startTimeKey{}).(time.Time); ok {
+		latency := float64(time.Since(start)) / float64(time.Millisecond)
+		_ = stats.RecordWithTags(ctx, []tag.Mutator{tag.Upsert(CommandKey, command), tag.Upsert(StatusKey, status)}, LatencyMeasure.M(latency))
+	}
+	span.End()
+}
+
+// keyOf returns the key a command works on, the match pattern for scan and the first key
+// for scripts.
+func keyOf(cmd redis.Cmder) (string, bool) {
+	args := cmd.Args()
+	if scriptCommands[cmd.Name()] {
+		if len(args) < 4 {
+			return "", false
+		}
+		if numKeys, err := strconv.Atoi(fmt.Sprint(args[2])); err != nil || numKeys < 1 {
+			return "", false
+		}
+		return fmt.Sprint(args[3]), true
+	}
+	if cmd.Name() == "scan" {
+		for i := 2; i+1 < len(args); i++ {
+			if option, ok := args[i].(string); ok && strings.EqualFold(option, "match") {
+				return fmt.Sprint(args[i+1]), true
+			}
+		}
+		return "", false
+	}
+	if keylessCommands[cmd.Name()] || len(args) < 2 {
+		return "", false
+	}
+	return fmt.Sprint(args[1]), true
+}
+
+func (h instrumentationHook) redact(key string) string {
+	if !h.redactKeys {
+		return key
+	}
+	segments := strings.Split(key, keySeparator)
+	if len(segments) == 1 {
+		return redactedPart
+	}
+	for i := 1; i < len(segments); i++ {
+		segments[i] = redactedPart
+	}
+	return strings.Join(segments, keySeparator)
+}
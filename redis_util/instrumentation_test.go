@@ -0,0 +1,184 @@
+package redis_util
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/gin-gonic/gin"
+	"github.com/go-redis/redis/v7"
+	"github.com/inclusi-blog/gola-utils/tracing/tracetest"
+	"github.com/stretchr/testify/suite"
+	"go.opencensus.io/stats/view"
+	"go.opencensus.io/trace"
+)
+
+type InstrumentationTestSuite struct {
+	suite.Suite
+	mockRedis *miniredis.Miniredis
+	exporter  *tracetest.Exporter
+	ctx       context.Context
+	parent    *trace.Span
+}
+
+func TestInstrumentationTestSuite(t *testing.T) {
+	suite.Run(t, new(InstrumentationTestSuite))
+}
+
+func (suite *InstrumentationTestSuite) SetupTest() {
+	var err error
+	suite.mockRedis, err = miniredis.Run()
+	suite.Require().Nil(err)
+	suite.exporter = tracetest.Start()
+	suite.ctx, suite.parent = trace.StartSpan(context.Background(), "handler", trace.WithSampler(trace.AlwaysSample()))
+}
+
+func (suite *InstrumentationTestSuite) TearDownTest() {
+	suite.exporter.Stop()
+	suite.mockRedis.Close()
+}
+
+func (suite *InstrumentationTestSuite) newStore(redactKeys bool) RedisStore {
+	store, err := NewRedisClientWith(RedisStoreConfig{
+		Host:                  suite.mockRedis.Host(),
+		Port:                  suite.mockRedis.Port(),
+		Db:                    0,
+		ReadTimeoutInSeconds:  10,
+		WriteTimeoutInSeconds: 10,
+		DialTimeoutInSeconds:  10,
+		RedactKeys:            redactKeys,
+	})
+	suite.Require().Nil(err)
+	return store
+}
+
+func (suite *InstrumentationTestSuite) TestShouldTraceCommandAsChildSpan() {
+	err := suite.newStore(false).Set(suite.ctx, "session:1234", "value", 10)
+	suite.parent.End()
+
+	suite.Nil(err)
+	suite.Equal([]string{"redis.set", "handler"}, suite.exporter.Names())
+	span, _ := suite.exporter.Span("redis.set")
+	suite.Equal(suite.parent.SpanContext().SpanID, span.ParentSpanID)
+	suite.Equal(trace.SpanKindClient, span.SpanKind)
+	suite.True(span.EndTime.After(span.StartTime))
+	suite.Equal(map[string]interface{}{
+		SystemAttribute:  "redis",
+		CommandAttribute: "set",
+		DBAttribute:      int64(0),
+		KeyAttribute:     "session:1234",
+	}, span.Attributes)
+}
+
+func (suite *InstrumentationTestSuite) TestShouldTakeSpanFromGinContext() {
+	ginContext, _ := gin.CreateTestContext(httptest.NewRecorder())
+	request, _ := http.NewRequest(http.MethodGet, "/api/drafts", nil)
+	ginContext.Request = request.WithContext(suite.ctx)
+
+	_, err := suite.newStore(false).SetNX(ginContext, "lock:draft", "value", 1)
+	suite.parent.End()
+
+	suite.Nil(err)
+	span, _ := suite.exporter.Span("redis.set")
+	suite.Equal(suite.parent.SpanContext().SpanID, span.ParentSpanID)
+}
+
+func (suite *InstrumentationTestSuite) TestShouldRedactKeys() {
+	store := suite.newStore(true)
+
+	_ = store.Delete(suite.ctx, "session:1234:device")
+	_ = store.Delete(suite.ctx, "1234")
+
+	spans := suite.exporter.SpansNamed("redis.del")
+	suite.Equal("session:*:*", spans[0].Attributes[KeyAttribute])
+	suite.Equal("*", spans[1].Attributes[KeyAttribute])
+}
+
+func (suite *InstrumentationTestSuite) TestShouldNotMarkMissingKeyAsError() {
+	var value string
+	err := suite.newStore(false).Get(suite.ctx, "missing", &value)
+
+	suite.Equal(redis.Nil, err)
+	span, _ := suite.exporter.Span("redis.get")
+	suite.Nil(span.Attributes[ErrorAttribute])
+	suite.Equal(int32(trace.StatusCodeOK), span.Code)
+}
+
+func (suite *InstrumentationTestSuite) TestShouldMarkFailedCommandAsError() {
+	_, _ = suite.mockRedis.Lpush("drafts", "draft")
+	var value string
+
+	err := suite.newStore(false).Get(suite.ctx, "drafts", &value)
+
+	suite.NotNil(err)
+	span, _ := suite.exporter.Span("redis.get")
+	suite.Equal(true, span.Attributes[ErrorAttribute])
+	suite.Equal(int32(trace.StatusCodeUnknown), span.Code)
+	suite.Equal(err.Error(), span.Message)
+}
+
+func (suite *InstrumentationTestSuite) TestShouldTracePipelineAndScanPattern() {
+	suite.Require().Nil(suite.mockRedis.Set("session:1", "a"))
+	suite.Require().Nil(suite.mockRedis.Set("session:2", "b"))
+
+	err := suite.newStore(false).DeleteAll(suite.ctx, "session:*")
+
+	suite.Nil(err)
+	suite.Equal([]string{"redis.scan", "redis.pipeline"}, suite.exporter.Names())
+	suite.Equal("session:*", suite.exporter.Attributes("redis.scan")[KeyAttribute])
+	pipeline := suite.exporter.Attributes("redis.pipeline")
+	suite.Equal(int64(2), pipeline[PipelineSizeAttribute])
+	suite.Equal("pipeline", pipeline[CommandAttribute])
+	suite.Nil(pipeline[KeyAttribute])
+}
+
+func (suite *InstrumentationTestSuite) TestShouldNotTakeScriptsOrChannelsAsKeys() {
+	for _, test := range []struct {
+		cmd   redis.Cmder
+		key   string
+		isKey bool
+	}{
+		{cmd: redis.NewCmd("eval", "return redis.call('get', KEYS[1])", 1, "session:1234"), key: "session:1234", isKey: true},
+		{cmd: redis.NewCmd("evalsha", "e0e1f9fabfc9d4800c877a703b823ac0578ff8db", 2, "lock:draft", "lock:post", "owner"), key: "lock:draft", isKey: true},
+		{cmd: redis.NewCmd("eval", "return 1", 0)},
+		{cmd: redis.NewCmd("eval", "return ARGV[1]", 0, "argument")},
+		{cmd: redis.NewCmd("publish", "drafts", "draft published")},
+		{cmd: redis.NewCmd("subscribe", "drafts")},
+		{cmd: redis.NewCmd("get", "session:1234"), key: "session:1234", isKey: true},
+	} {
+		key, isKey := keyOf(test.cmd)
+
+		suite.Equal(test.key, key, test.cmd.String())
+		suite.Equal(test.isKey, isKey, test.cmd.String())
+	}
+}
+
+func (suite *InstrumentationTestSuite) TestShouldRecordLatencyPerCommandAndStatus() {
+	suite.Require().Nil(view.Register(LatencyView))
+	defer view.Unregister(LatencyView)
+	store := suite.newStore(false)
+	var value string
+
+	_ = store.Set(suite.ctx, "key", "value", 1)
+	_ = store.Get(suite.ctx, "key", &value)
+	_ = store.Get(suite.ctx, "missing", &value)
+
+	rows, err := view.RetrieveData(LatencyView.Name)
+	suite.Nil(err)
+	counts := map[string]int64{}
+	for _, row := range rows {
+		var command, status string
+		for _, t := range row.Tags {
+			switch t.Key {
+			case CommandKey:
+				command = t.Value
+			case StatusKey:
+				status = t.Value
+			}
+		}
+		counts[command+"/"+status] = row.Data.(*view.DistributionData).Count
+	}
+	suite.Equal(map[string]int64{"set/ok": 1, "get/ok": 1, "get/miss": 1}, counts)
+}
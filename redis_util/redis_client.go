@@ -7,7 +7,6 @@ import (
 	"github.com/go-redis/redis/v7"
 	"github.com/inclusi-blog/gola-utils/constants"
 	"github.com/inclusi-blog/gola-utils/logging"
-	"go.opencensus.io/trace"
 	"time"
 )
 
@@ -25,7 +24,7 @@ func NewRedisClient(host string, port string, db int, readTimeout int, dialTimeo
 		WriteTimeout: time.Duration(writeTimeout) * time.Second,
 		Password:     password,
 	})
-	return redisStore{rdb: rdb}, rdb.Ping().Err()
+	return newRedisStore(rdb, db, false)
 }
 
 func NewRedisClientWith(config RedisStoreConfig) (RedisStore, error) {
@@ -37,44 +36,51 @@ func NewRedisClientWith(config RedisStoreConfig) (RedisStore, error) {
 		WriteTimeout: time.Duration(config.WriteTimeoutInSeconds) * time.Second,
 		Password:     config.Password,
 	})
-	return redisStore{rdb: rdb}, rdb.Ping().Err()
+	return newRedisStore(rdb, config.Db, config.RedactKeys)
 }
 
-func (rs redisStore) Set(ctx context.Context, key string, value interface{}, expiryInMinutes int) error {
-	initTracing(ctx, "Set")
+// newRedisStore pings rdb before instrumenting it, so the ping is not traced.
+func newRedisStore(rdb *redis.Client, db int, redactKeys bool) (RedisStore, error) {
+	err := rdb.Ping().Err()
+	rdb.AddHook(NewInstrumentationHook(db, redactKeys))
+	return redisStore{rdb: rdb}, err
+}
+
+// client returns rdb running commands in the span of ctx, the request context for a gin.Context.
+func (rs redisStore) client(ctx context.Context) *redis.Client {
+	if ginContext, ok := ctx.(*gin.Context); ok && ginContext.Request != nil {
+		ctx = ginContext.Request.Context()
+	}
+	return rs.rdb.WithContext(ctx)
+}
 
+func (rs redisStore) Set(ctx context.Context, key string, value interface{}, expiryInMinutes int) error {
 	p, err := json.Marshal(value)
 	if err != nil {
 		return err
 	}
-	return rs.rdb.Set(key, p, time.Duration(expiryInMinutes)*time.Minute).Err()
+	return rs.client(ctx).Set(key, p, time.Duration(expiryInMinutes)*time.Minute).Err()
 }
 
 func (rs redisStore) SetInSeconds(ctx context.Context, key string, value interface{}, expiryInSeconds int) error {
-	initTracing(ctx, "SetInSeconds")
-
 	p, err := json.Marshal(value)
 	if err != nil {
 		return err
 	}
-	return rs.rdb.Set(key, p, time.Duration(expiryInSeconds)*time.Second).Err()
+	return rs.client(ctx).Set(key, p, time.Duration(expiryInSeconds)*time.Second).Err()
 }
 
 func (rs redisStore) SetNX(ctx context.Context, key string, value interface{}, expiryInMinutes int) (bool, error) {
-	initTracing(ctx, "SetNX")
-
 	p, err := json.Marshal(value)
 	if err != nil {
 		return false, err
 	}
-	cmd := rs.rdb.SetNX(key, p, time.Duration(expiryInMinutes)*time.Minute)
+	cmd := rs.client(ctx).SetNX(key, p, time.Duration(expiryInMinutes)*time.Minute)
 	return cmd.Val(), cmd.Err()
 }
 
 func (rs redisStore) Get(ctx context.Context, key string, dest interface{}) error {
-	initTracing(ctx, "Get")
-
-	p, err := rs.rdb.Get(key).Bytes()
+	p, err := rs.client(ctx).Get(key).Bytes()
 	if err != nil {
 		return err
 	}
@@ -82,21 +88,18 @@ func (rs redisStore) Get(ctx context.Context, key string, dest interface{}) erro
 }
 
 func (rs redisStore) Delete(ctx context.Context, key string) error {
-	initTracing(ctx, "Del")
-	return rs.rdb.Del(key).Err()
+	return rs.client(ctx).Del(key).Err()
 }
 
 func (rs redisStore) DeleteAll(ctx context.Context, pattern string) error {
-	initTracing(ctx, "DeleteAll")
-
 	cursor := uint64(constants.REDIS_SCAN_DEAFULT_CURSOR_VALUE)
-	return rs.scanAndDelete(cursor, pattern, constants.REDIS_SCAN_DEFAULT_COUNT)
+	return scanAndDelete(rs.client(ctx), cursor, pattern, constants.REDIS_SCAN_DEFAULT_COUNT)
 }
 
-func (rs redisStore) scanAndDelete(cursor uint64, pattern string, count int64) error {
+func scanAndDelete(rdb *redis.Client, cursor uint64, pattern string, count int64) error {
 	logger := logging.NewLoggerEntry()
 
-	keys, updatedCursor, err := rs.rdb.Scan(cursor, pattern, count).Result()
+	keys, updatedCursor, err := rdb.Scan(cursor, pattern, count).Result()
 	if err != nil {
 		logger.Errorf("RedisStore.scanAndDelete: Error in scanning keys. Error: %v", err)
 		return err
@@ -105,7 +108,7 @@ func (rs redisStore) scanAndDelete(cursor uint64, pattern string, count int64) e
 	keysLength := len(keys)
 	logger.Infof("RedisStore.scanAndDelete: %v keys found", keysLength)
 	if keysLength > 0 {
-		pipeline := rs.rdb.Pipeline()
+		pipeline := rdb.Pipeline()
 		for _, key := range keys {
 			pipeline.Del(key)
 		}
@@ -120,14 +123,5 @@ func (rs redisStore) scanAndDelete(cursor uint64, pattern string, count int64) e
 		logger.Debugf("RedisStore.scanAndDelete: Zero value returned for cursor. Stopping execution")
 		return nil
 	}
-	return rs.scanAndDelete(updatedCursor, pattern, count)
-}
-
-func initTracing(ctx context.Context, cmdName string) {
-	if ginContext, ok := ctx.(*gin.Context); ok {
-		ctx = ginContext.Request.Context()
-	}
-	_, span := trace.StartSpan(ctx, "redis.(*baseClient)."+cmdName)
-
-	defer span.End()
+	return scanAndDelete(rdb, updatedCursor, pattern, count)
 }
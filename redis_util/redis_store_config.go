@@ -9,4 +9,5 @@ type RedisStoreConfig struct {
 	DialTimeoutInSeconds  int    `json:"dial_timeout_in_seconds"`
 	Mode                  string `json:"mode"`
 	Password              string `json:"password"`
+	RedactKeys            bool   `json:"redact_keys"`
 }
@@ -7,7 +7,7 @@ package mocks
 import (
 	context "context"
 	gomock "github.com/golang/mock/gomock"
-	trace "go.opencensus.io/trace"
+	trace "github.com/inclusi-blog/gola-utils/trace"
 	http "net/http"
 	reflect "reflect"
 )
@@ -36,10 +36,10 @@ func (m *MockTrace) EXPECT() *MockTraceMockRecorder {
 }
 
 // Continue mocks base method
-func (m *MockTrace) Continue(ctx context.Context, httpRequest *http.Request) (*trace.Span, *http.Request) {
+func (m *MockTrace) Continue(ctx context.Context, httpRequest *http.Request) (trace.Span, *http.Request) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "Continue", ctx, httpRequest)
-	ret0, _ := ret[0].(*trace.Span)
+	ret0, _ := ret[0].(trace.Span)
 	ret1, _ := ret[1].(*http.Request)
 	return ret0, ret1
 }
@@ -51,11 +51,11 @@ func (mr *MockTraceMockRecorder) Continue(ctx, httpRequest interface{}) *gomock.
 }
 
 // DeriveTracingFromRemoteParent mocks base method
-func (m *MockTrace) DeriveTracingFromRemoteParent(spanName string, traceID trace.TraceID, spanID trace.SpanID) (context.Context, *trace.Span) {
+func (m *MockTrace) DeriveTracingFromRemoteParent(spanName, traceID, spanID string) (context.Context, trace.Span) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "DeriveTracingFromRemoteParent", spanName, traceID, spanID)
 	ret0, _ := ret[0].(context.Context)
-	ret1, _ := ret[1].(*trace.Span)
+	ret1, _ := ret[1].(trace.Span)
 	return ret0, ret1
 }
 
@@ -66,11 +66,11 @@ func (mr *MockTraceMockRecorder) DeriveTracingFromRemoteParent(spanName, traceID
 }
 
 // StartTracing mocks base method
-func (m *MockTrace) StartTracing(ctx context.Context, cmdName string) (context.Context, *trace.Span) {
+func (m *MockTrace) StartTracing(ctx context.Context, cmdName string) (context.Context, trace.Span) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "StartTracing", ctx, cmdName)
 	ret0, _ := ret[0].(context.Context)
-	ret1, _ := ret[1].(*trace.Span)
+	ret1, _ := ret[1].(trace.Span)
 	return ret0, ret1
 }
 
@@ -81,10 +81,10 @@ func (mr *MockTraceMockRecorder) StartTracing(ctx, cmdName interface{}) *gomock.
 }
 
 // CreateNewSpan mocks base method
-func (m *MockTrace) CreateNewSpan(ctx context.Context, spanName string) (*trace.Span, context.Context) {
+func (m *MockTrace) CreateNewSpan(ctx context.Context, spanName string) (trace.Span, context.Context) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "CreateNewSpan", ctx, spanName)
-	ret0, _ := ret[0].(*trace.Span)
+	ret0, _ := ret[0].(trace.Span)
 	ret1, _ := ret[1].(context.Context)
 	return ret0, ret1
 }
@@ -94,3 +94,94 @@ func (mr *MockTraceMockRecorder) CreateNewSpan(ctx, spanName interface{}) *gomoc
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateNewSpan", reflect.TypeOf((*MockTrace)(nil).CreateNewSpan), ctx, spanName)
 }
+
+// MockSpan is a mock of Span interface
+type MockSpan struct {
+	ctrl     *gomock.Controller
+	recorder *MockSpanMockRecorder
+}
+
+// MockSpanMockRecorder is the mock recorder for MockSpan
+type MockSpanMockRecorder struct {
+	mock *MockSpan
+}
+
+// NewMockSpan creates a new mock instance
+func NewMockSpan(ctrl *gomock.Controller) *MockSpan {
+	mock := &MockSpan{ctrl: ctrl}
+	mock.recorder = &MockSpanMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockSpan) EXPECT() *MockSpanMockRecorder {
+	return m.recorder
+}
+
+// End mocks base method
+func (m *MockSpan) End() {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "End")
+}
+
+// End indicates an expected call of End
+func (mr *MockSpanMockRecorder) End() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "End", reflect.TypeOf((*MockSpan)(nil).End))
+}
+
+// AddAttributes mocks base method
+func (m *MockSpan) AddAttributes(attributes ...trace.Attribute) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{}
+	for _, a := range attributes {
+		varargs = append(varargs, a)
+	}
+	m.ctrl.Call(m, "AddAttributes", varargs...)
+}
+
+// AddAttributes indicates an expected call of AddAttributes
+func (mr *MockSpanMockRecorder) AddAttributes(attributes ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddAttributes", reflect.TypeOf((*MockSpan)(nil).AddAttributes), attributes...)
+}
+
+// Annotate mocks base method
+func (m *MockSpan) Annotate(attributes []trace.Attribute, message string) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Annotate", attributes, message)
+}
+
+// Annotate indicates an expected call of Annotate
+func (mr *MockSpanMockRecorder) Annotate(attributes, message interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Annotate", reflect.TypeOf((*MockSpan)(nil).Annotate), attributes, message)
+}
+
+// SpanContext mocks base method
+func (m *MockSpan) SpanContext() trace.SpanContext {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SpanContext")
+	ret0, _ := ret[0].(trace.SpanContext)
+	return ret0
+}
+
+// SpanContext indicates an expected call of SpanContext
+func (mr *MockSpanMockRecorder) SpanContext() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SpanContext", reflect.TypeOf((*MockSpan)(nil).SpanContext))
+}
+
+// IsRecordingEvents mocks base method
+func (m *MockSpan) IsRecordingEvents() bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IsRecordingEvents")
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// IsRecordingEvents indicates an expected call of IsRecordingEvents
+func (mr *MockSpanMockRecorder) IsRecordingEvents() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsRecordingEvents", reflect.TypeOf((*MockSpan)(nil).IsRecordingEvents))
+}
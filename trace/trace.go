@@ -5,61 +5,191 @@ package trace
 import (
 	"context"
 	"github.com/inclusi-blog/gola-utils/constants"
+	"github.com/inclusi-blog/gola-utils/tracing"
 	"net/http"
 	"net/http/httptrace"
 
 	"github.com/gin-gonic/gin"
 	"go.opencensus.io/plugin/ochttp"
+	"go.opencensus.io/plugin/ochttp/propagation/b3"
 	openTrace "go.opencensus.io/trace"
 )
 
 type Trace interface {
-	Continue(ctx context.Context, httpRequest *http.Request) (*openTrace.Span, *http.Request)
-	DeriveTracingFromRemoteParent(spanName string, traceID openTrace.TraceID, spanID openTrace.SpanID) (context.Context, *openTrace.Span)
-	StartTracing(ctx context.Context, cmdName string) (context.Context, *openTrace.Span)
-	CreateNewSpan(ctx context.Context, spanName string) (*openTrace.Span, context.Context)
+	Continue(ctx context.Context, httpRequest *http.Request) (Span, *http.Request)
+	DeriveTracingFromRemoteParent(spanName string, traceID string, spanID string) (context.Context, Span)
+	StartTracing(ctx context.Context, cmdName string) (context.Context, Span)
+	CreateNewSpan(ctx context.Context, spanName string) (Span, context.Context)
 }
 
-type trace struct {
+// Span is a span of the current trace. It hides the tracing library, so callers do not
+// depend on it when spans are exported through OpenCensus or OTLP.
+type Span interface {
+	End()
+	AddAttributes(attributes ...Attribute)
+	Annotate(attributes []Attribute, message string)
+	SpanContext() SpanContext
+	IsRecordingEvents() bool
+}
+
+// SpanContext identifies a span across process boundaries.
+type SpanContext struct {
+	spanContext openTrace.SpanContext
+}
+
+func (c SpanContext) TraceID() string {
+	return c.spanContext.TraceID.String()
+}
+
+func (c SpanContext) SpanID() string {
+	return c.spanContext.SpanID.String()
+}
+
+func (c SpanContext) IsSampled() bool {
+	return c.spanContext.IsSampled()
+}
+
+// Attribute is a span attribute or annotation attribute, create it with StringAttribute,
+// Int64Attribute, Float64Attribute or BoolAttribute.
+type Attribute struct {
+	key   string
+	value interface{}
+}
+
+func StringAttribute(key string, value string) Attribute {
+	return Attribute{key: key, value: value}
 }
 
-func (t trace) Continue(ctx context.Context, httpRequest *http.Request) (*openTrace.Span, *http.Request) {
-	var span *openTrace.Span
+func Int64Attribute(key string, value int64) Attribute {
+	return Attribute{key: key, value: value}
+}
+
+func Float64Attribute(key string, value float64) Attribute {
+	return Attribute{key: key, value: value}
+}
+
+func BoolAttribute(key string, value bool) Attribute {
+	return Attribute{key: key, value: value}
+}
+
+func (a Attribute) Key() string {
+	return a.key
+}
 
+func (a Attribute) Value() interface{} {
+	return a.value
+}
+
+func (a Attribute) openCensus() openTrace.Attribute {
+	switch value := a.value.(type) {
+	case int64:
+		return openTrace.Int64Attribute(a.key, value)
+	case float64:
+		return openTrace.Float64Attribute(a.key, value)
+	case bool:
+		return openTrace.BoolAttribute(a.key, value)
+	default:
+		return openTrace.StringAttribute(a.key, value.(string))
+	}
+}
+
+type span struct {
+	span *openTrace.Span
+}
+
+func (s span) End() {
+	s.span.End()
+}
+
+func (s span) AddAttributes(attributes ...Attribute) {
+	s.span.AddAttributes(openCensusAttributes(attributes)...)
+}
+
+func (s span) Annotate(attributes []Attribute, message string) {
+	s.span.Annotate(openCensusAttributes(attributes), message)
+}
+
+func (s span) SpanContext() SpanContext {
+	return SpanContext{spanContext: s.span.SpanContext()}
+}
+
+func (s span) IsRecordingEvents() bool {
+	return s.span.IsRecordingEvents()
+}
+
+func openCensusAttributes(attributes []Attribute) []openTrace.Attribute {
+	openCensusAttributes := make([]openTrace.Attribute, 0, len(attributes))
+	for _, attribute := range attributes {
+		openCensusAttributes = append(openCensusAttributes, attribute.openCensus())
+	}
+	return openCensusAttributes
+}
+
+// FromContext returns the current span of ctx, or a span recording nothing when there
+// is none. The span of a gin context is taken from its request.
+func FromContext(ctx context.Context) Span {
+	if ginContext, ok := ctx.(*gin.Context); ok {
+		ctx = ginContext.Request.Context()
+	}
+	return span{span: openTrace.FromContext(ctx)}
+}
+
+// StartSpanWithRemoteParent starts a span with parent as its parent, ctx only carries it.
+func StartSpanWithRemoteParent(ctx context.Context, spanName string, parent SpanContext) (context.Context, Span) {
+	newContext, newSpan := openTrace.StartSpanWithRemoteParent(ctx, spanName, parent.spanContext)
+	return newContext, span{span: newSpan}
+}
+
+type trace struct {
+}
+
+func (t trace) Continue(ctx context.Context, httpRequest *http.Request) (Span, *http.Request) {
 	if ginContext, ok := ctx.(*gin.Context); ok {
 		ctx = ginContext.Request.Context()
 	}
 
-	span = openTrace.FromContext(ctx)
-	clientTrace := ochttp.NewSpanAnnotatingClientTrace(httpRequest, span)
+	currentSpan := openTrace.FromContext(ctx)
+	clientTrace := ochttp.NewSpanAnnotatingClientTrace(httpRequest, currentSpan)
 	newContext := httptrace.WithClientTrace(ctx, clientTrace)
-	return span, httpRequest.WithContext(newContext)
+	return span{span: currentSpan}, httpRequest.WithContext(newContext)
 }
 
-func (t trace) DeriveTracingFromRemoteParent(spanName string, traceID openTrace.TraceID, spanID openTrace.SpanID) (context.Context, *openTrace.Span) {
-	spanContext := openTrace.SpanContext{
-		TraceID:      traceID,
-		SpanID:       spanID,
-		TraceOptions: 0,
-		Tracestate:   nil,
+// DeriveTracingFromRemoteParent continues the trace of traceID and spanID, given in hex.
+// A new trace is started when they are not valid. The sampling decision of the parent
+// is not known, so the span is sampled like a root span.
+func (t trace) DeriveTracingFromRemoteParent(spanName string, traceID string, spanID string) (context.Context, Span) {
+	parsedTraceID, isTraceID := b3.ParseTraceID(traceID)
+	parsedSpanID, isSpanID := b3.ParseSpanID(spanID)
+	var newContextWithTrace context.Context
+	var newSpan *openTrace.Span
+	if isTraceID && isSpanID {
+		spanContext := openTrace.SpanContext{
+			TraceID:      parsedTraceID,
+			SpanID:       parsedSpanID,
+			TraceOptions: 0,
+			Tracestate:   nil,
+		}
+		newContextWithTrace, newSpan = openTrace.StartSpanWithRemoteParent(context.Background(), spanName, spanContext,
+			openTrace.WithSampler(tracing.RootSampler()))
+	} else {
+		newContextWithTrace, newSpan = openTrace.StartSpan(context.Background(), spanName)
 	}
-	newContextWithTrace, span := openTrace.StartSpanWithRemoteParent(context.Background(), spanName, spanContext)
-	newContextWithTrace = context.WithValue(newContextWithTrace, constants.TRACE_KEY, traceID.String())
+	newContextWithTrace = context.WithValue(newContextWithTrace, constants.TRACE_KEY, newSpan.SpanContext().TraceID.String())
 
-	return newContextWithTrace, span
+	return newContextWithTrace, span{span: newSpan}
 }
 
-func (t trace) StartTracing(ctx context.Context, cmdName string) (context.Context, *openTrace.Span) {
-	return openTrace.StartSpan(ctx, cmdName)
+func (t trace) StartTracing(ctx context.Context, cmdName string) (context.Context, Span) {
+	newContext, newSpan := openTrace.StartSpan(ctx, cmdName)
+	return newContext, span{span: newSpan}
 }
 
-func (t trace) CreateNewSpan(ctx context.Context, spanName string) (*openTrace.Span, context.Context) {
+func (t trace) CreateNewSpan(ctx context.Context, spanName string) (Span, context.Context) {
 	context, dataSpan := openTrace.StartSpanWithRemoteParent(ctx, spanName,
 		openTrace.FromContext(ctx).SpanContext())
-	return dataSpan, context
+	return span{span: dataSpan}, context
 }
 
 func New() Trace {
 	return trace{}
 }
-
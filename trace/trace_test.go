@@ -0,0 +1,75 @@
+package trace
+
+import (
+	"context"
+	"testing"
+
+	"github.com/inclusi-blog/gola-utils/constants"
+	"github.com/inclusi-blog/gola-utils/tracing/tracetest"
+	"github.com/stretchr/testify/suite"
+	openTrace "go.opencensus.io/trace"
+)
+
+type TraceTestSuite struct {
+	suite.Suite
+	exporter *tracetest.Exporter
+}
+
+func TestTraceTestSuite(t *testing.T) {
+	suite.Run(t, new(TraceTestSuite))
+}
+
+func (suite *TraceTestSuite) SetupTest() {
+	suite.exporter = tracetest.Start()
+}
+
+func (suite *TraceTestSuite) TearDownTest() {
+	suite.exporter.Stop()
+}
+
+func (suite *TraceTestSuite) TestShouldRecordAttributesAndAnnotations() {
+	ctx, parent := openTrace.StartSpan(context.Background(), "parent", openTrace.WithSampler(openTrace.AlwaysSample()))
+	span, _ := New().CreateNewSpan(ctx, "child")
+
+	span.AddAttributes(StringAttribute("path", "/api/drafts"), Int64Attribute("http.status_code", 200), BoolAttribute("error", false))
+	span.Annotate([]Attribute{Float64Attribute("latency", 1.5)}, "response")
+	span.End()
+	parent.End()
+
+	child, _ := suite.exporter.Span("child")
+	suite.Equal("child", child.Name)
+	suite.Equal(parent.SpanContext().SpanID, child.ParentSpanID)
+	suite.Equal(map[string]interface{}{"path": "/api/drafts", "http.status_code": int64(200), "error": false}, child.Attributes)
+	suite.Equal("response", child.Annotations[0].Message)
+	suite.Equal(map[string]interface{}{"latency": 1.5}, child.Annotations[0].Attributes)
+	suite.Equal(parent.SpanContext().TraceID.String(), span.SpanContext().TraceID())
+	suite.True(span.SpanContext().IsSampled())
+}
+
+func (suite *TraceTestSuite) TestShouldDeriveTracingFromRemoteParent() {
+	ctx, span := New().DeriveTracingFromRemoteParent("job", "463ac35c9f6413ad48485a3953bb6124", "a2fb4a1d1a96d312")
+	span.End()
+
+	suite.Equal("463ac35c9f6413ad48485a3953bb6124", span.SpanContext().TraceID())
+	suite.Equal("463ac35c9f6413ad48485a3953bb6124", ctx.Value(constants.TRACE_KEY))
+	suite.Equal(span.SpanContext().SpanID(), FromContext(ctx).SpanContext().SpanID())
+}
+
+func (suite *TraceTestSuite) TestShouldStartNewTraceForInvalidRemoteParent() {
+	ctx, span := New().DeriveTracingFromRemoteParent("job", "not-a-trace-id", "a2fb4a1d1a96d312")
+	span.End()
+
+	suite.Regexp("^[0-9a-f]{32}$", span.SpanContext().TraceID())
+	suite.Equal(span.SpanContext().TraceID(), ctx.Value(constants.TRACE_KEY))
+}
+
+func (suite *TraceTestSuite) TestShouldIgnoreMissingSpan() {
+	span := FromContext(context.Background())
+
+	span.AddAttributes(StringAttribute("path", "/api/drafts"))
+	span.Annotate(nil, "ignored")
+	span.End()
+
+	suite.False(span.IsRecordingEvents())
+	suite.Equal("00000000000000000000000000000000", span.SpanContext().TraceID())
+}
@@ -5,6 +5,7 @@ import (
 	"encoding/base64"
 	"github.com/gin-gonic/gin"
 	"github.com/inclusi-blog/gola-utils/constants"
+	openTrace "go.opencensus.io/trace"
 	"strings"
 )
 
@@ -30,6 +31,9 @@ func GetTraceId(ctx context.Context) string {
 			return strings.TrimLeft(traceIdString, "0")
 		}
 	}
+	if span := openTrace.FromContext(ctx); span != nil {
+		return strings.TrimLeft(span.SpanContext().TraceID.String(), "0")
+	}
 	return constants.NO_TRACE_ID
 }
 
@@ -3,10 +3,12 @@ package trace
 import (
 	"context"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/suite"
+	openTrace "go.opencensus.io/trace"
 )
 
 type TraceUtilsTestSuite struct {
@@ -78,3 +80,11 @@ func (suite *TraceUtilsTestSuite) TestShouldReturnTrimmedTraceIDFromGoContextIfT
 	gotTraceId := GetTraceId(goCtx)
 	suite.Equal("12345", gotTraceId)
 }
+
+func (suite *TraceUtilsTestSuite) TestShouldReturnTraceIDOfSpanFromGoContextIfNoTraceIdExists() {
+	goCtx, span := openTrace.StartSpan(context.TODO(), "test-span")
+	defer span.End()
+
+	gotTraceId := GetTraceId(goCtx)
+	suite.Equal(strings.TrimLeft(span.SpanContext().TraceID.String(), "0"), gotTraceId)
+}
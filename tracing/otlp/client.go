@@ -0,0 +1,123 @@
+package otlp
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/inclusi-blog/gola-utils/model"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+)
+
+const (
+	TracesPath    = "/v1/traces"
+	ExportMethod  = "/opentelemetry.proto.collector.trace.v1.TraceService/Export"
+	protobufType  = "application/x-protobuf"
+	maxErrorBytes = 512
+)
+
+// httpClient posts requests with a plain http.Client, an instrumented transport would
+// trace the exporter itself.
+type httpClient struct {
+	url     string
+	headers map[string]string
+	client  *http.Client
+}
+
+func newHTTPClient(config model.OTLPConfig, timeout time.Duration) (client, error) {
+	endpoint := config.Endpoint
+	if !strings.Contains(endpoint, "://") {
+		scheme := "https://"
+		if config.Insecure {
+			scheme = "http://"
+		}
+		endpoint = scheme + endpoint
+	}
+	endpointURL, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("not a valid otlp endpoint: %v", err)
+	}
+	if endpointURL.Path == "" || endpointURL.Path == "/" {
+		endpointURL.Path = TracesPath
+	}
+	return httpClient{url: endpointURL.String(), headers: config.Headers, client: &http.Client{Timeout: timeout}}, nil
+}
+
+func (c httpClient) export(ctx context.Context, request []byte) error {
+	httpRequest, err := http.NewRequest(http.MethodPost, c.url, bytes.NewReader(request))
+	if err != nil {
+		return err
+	}
+	httpRequest = httpRequest.WithContext(ctx)
+	httpRequest.Header.Set("Content-Type", protobufType)
+	for key, value := range c.headers {
+		httpRequest.Header.Set(key, value)
+	}
+	response, err := c.client.Do(httpRequest)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	body, _ := ioutil.ReadAll(io.LimitReader(response.Body, maxErrorBytes))
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		return fmt.Errorf("otlp collector responded %d: %s", response.StatusCode, body)
+	}
+	return nil
+}
+
+func (c httpClient) close() error {
+	c.client.CloseIdleConnections()
+	return nil
+}
+
+// rawCodec sends requests encoded by encodeRequest as they are, so no generated OTLP
+// types are needed.
+type rawCodec struct{}
+
+func (rawCodec) Marshal(v interface{}) ([]byte, error) {
+	return *v.(*[]byte), nil
+}
+
+func (rawCodec) Unmarshal(data []byte, v interface{}) error {
+	*v.(*[]byte) = append((*v.(*[]byte))[:0], data...)
+	return nil
+}
+
+func (rawCodec) Name() string {
+	return "proto"
+}
+
+type grpcClient struct {
+	connection *grpc.ClientConn
+	headers    metadata.MD
+}
+
+func newGRPCClient(config model.OTLPConfig) (client, error) {
+	credentialsOption := grpc.WithTransportCredentials(credentials.NewClientTLSFromCert(nil, ""))
+	if config.Insecure {
+		credentialsOption = grpc.WithInsecure()
+	}
+	connection, err := grpc.Dial(config.Endpoint, credentialsOption)
+	if err != nil {
+		return nil, err
+	}
+	return grpcClient{connection: connection, headers: metadata.New(config.Headers)}, nil
+}
+
+func (c grpcClient) export(ctx context.Context, request []byte) error {
+	var response []byte
+	ctx = metadata.NewOutgoingContext(ctx, c.headers)
+	return c.connection.Invoke(ctx, ExportMethod, &request, &response, grpc.ForceCodec(rawCodec{}))
+}
+
+func (c grpcClient) close() error {
+	return c.connection.Close()
+}
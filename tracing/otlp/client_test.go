@@ -0,0 +1,127 @@
+package otlp
+
+import (
+	"context"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/inclusi-blog/gola-utils/model"
+	"github.com/stretchr/testify/suite"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// serverCodec lets the test server receive raw requests, grpc.CustomCodec takes the legacy codec interface.
+type serverCodec struct {
+	rawCodec
+}
+
+func (serverCodec) String() string {
+	return "proto"
+}
+
+type ClientTestSuite struct {
+	suite.Suite
+}
+
+func TestClientTestSuite(t *testing.T) {
+	suite.Run(t, new(ClientTestSuite))
+}
+
+func (suite *ClientTestSuite) TestShouldPostRequestOverHTTP() {
+	var received *http.Request
+	var body []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r
+		body, _ = ioutil.ReadAll(r.Body)
+	}))
+	defer server.Close()
+	endpoint := strings.TrimPrefix(server.URL, "http://")
+	c, err := newHTTPClient(model.OTLPConfig{Endpoint: endpoint, Insecure: true, Headers: map[string]string{"Api-Key": "secret"}}, time.Second)
+	suite.Nil(err)
+
+	err = c.export(context.Background(), []byte("request"))
+
+	suite.Nil(err)
+	suite.Equal(http.MethodPost, received.Method)
+	suite.Equal(TracesPath, received.URL.Path)
+	suite.Equal("application/x-protobuf", received.Header.Get("Content-Type"))
+	suite.Equal("secret", received.Header.Get("Api-Key"))
+	suite.Equal([]byte("request"), body)
+	suite.Nil(c.close())
+}
+
+func (suite *ClientTestSuite) TestShouldKeepConfiguredPathAndScheme() {
+	c, err := newHTTPClient(model.OTLPConfig{Endpoint: "http://collector:4318/otlp/v1/traces"}, time.Second)
+	suite.Nil(err)
+	suite.Equal("http://collector:4318/otlp/v1/traces", c.(httpClient).url)
+
+	c, err = newHTTPClient(model.OTLPConfig{Endpoint: "collector:4318"}, time.Second)
+	suite.Nil(err)
+	suite.Equal("https://collector:4318/v1/traces", c.(httpClient).url)
+}
+
+func (suite *ClientTestSuite) TestShouldReturnErrorForFailedHTTPResponse() {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte("invalid spans"))
+	}))
+	defer server.Close()
+	c, _ := newHTTPClient(model.OTLPConfig{Endpoint: server.URL}, time.Second)
+
+	err := c.export(context.Background(), []byte("request"))
+
+	suite.EqualError(err, "otlp collector responded 400: invalid spans")
+}
+
+func (suite *ClientTestSuite) startGRPCServer(handler grpc.StreamHandler) string {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	suite.Require().Nil(err)
+	server := grpc.NewServer(grpc.CustomCodec(serverCodec{}), grpc.UnknownServiceHandler(handler))
+	go func() { _ = server.Serve(listener) }()
+	suite.T().Cleanup(server.Stop)
+	return listener.Addr().String()
+}
+
+func (suite *ClientTestSuite) TestShouldExportRequestOverGRPC() {
+	var method string
+	var received []byte
+	var headers metadata.MD
+	endpoint := suite.startGRPCServer(func(_ interface{}, stream grpc.ServerStream) error {
+		method, _ = grpc.MethodFromServerStream(stream)
+		headers, _ = metadata.FromIncomingContext(stream.Context())
+		if err := stream.RecvMsg(&received); err != nil {
+			return err
+		}
+		return stream.SendMsg(&[]byte{})
+	})
+	c, err := newGRPCClient(model.OTLPConfig{Endpoint: endpoint, Insecure: true, Headers: map[string]string{"api-key": "secret"}})
+	suite.Nil(err)
+	defer c.close()
+
+	err = c.export(context.Background(), []byte("request"))
+
+	suite.Nil(err)
+	suite.Equal(ExportMethod, method)
+	suite.Equal([]byte("request"), received)
+	suite.Equal([]string{"secret"}, headers.Get("api-key"))
+}
+
+func (suite *ClientTestSuite) TestShouldReturnErrorForFailedGRPCCall() {
+	endpoint := suite.startGRPCServer(func(_ interface{}, stream grpc.ServerStream) error {
+		return status.Error(codes.Unavailable, "collector overloaded")
+	})
+	c, _ := newGRPCClient(model.OTLPConfig{Endpoint: endpoint, Insecure: true})
+	defer c.close()
+
+	err := c.export(context.Background(), []byte("request"))
+
+	suite.Equal(codes.Unavailable, status.Code(err))
+}
@@ -0,0 +1,228 @@
+package otlp
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"go.opencensus.io/trace"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// Field numbers of the OTLP trace protocol, opentelemetry/proto/trace/v1/trace.proto.
+const (
+	requestResourceSpans = 1
+
+	resourceSpansResource   = 1
+	resourceSpansScopeSpans = 2
+
+	resourceAttributes = 1
+
+	scopeSpansScope = 1
+	scopeSpansSpans = 2
+
+	scopeName    = 1
+	scopeVersion = 2
+
+	spanTraceID                = 1
+	spanSpanID                 = 2
+	spanTraceState             = 3
+	spanParentSpanID           = 4
+	spanName                   = 5
+	spanKind                   = 6
+	spanStartTime              = 7
+	spanEndTime                = 8
+	spanAttributes             = 9
+	spanDroppedAttributesCount = 10
+	spanEvents                 = 11
+	spanDroppedEventsCount     = 12
+	spanLinks                  = 13
+	spanDroppedLinksCount      = 14
+	spanStatus                 = 15
+
+	eventTime       = 1
+	eventName       = 2
+	eventAttributes = 3
+
+	linkTraceID    = 1
+	linkSpanID     = 2
+	linkAttributes = 4
+
+	statusMessage = 2
+	statusCode    = 3
+
+	keyValueKey   = 1
+	keyValueValue = 2
+
+	anyValueString = 1
+	anyValueBool   = 2
+	anyValueInt    = 3
+	anyValueDouble = 4
+)
+
+const (
+	spanKindInternal = 1
+	spanKindServer   = 2
+	spanKindClient   = 3
+
+	statusCodeError = 2
+
+	ServiceNameAttribute = "service.name"
+	ScopeName            = "github.com/inclusi-blog/gola-utils/tracing"
+	MessageEventName     = "message"
+)
+
+// encodeRequest encodes spans as an ExportTraceServiceRequest of one resource.
+func encodeRequest(serviceName string, spans []*trace.SpanData) []byte {
+	scope := appendString(nil, scopeName, ScopeName)
+	scopeSpans := appendMessage(nil, scopeSpansScope, scope)
+	for _, span := range spans {
+		scopeSpans = appendMessage(scopeSpans, scopeSpansSpans, encodeSpan(span))
+	}
+	resource := appendMessage(nil, resourceAttributes, encodeKeyValue(ServiceNameAttribute, serviceName))
+	resourceSpans := appendMessage(nil, resourceSpansResource, resource)
+	resourceSpans = appendMessage(resourceSpans, resourceSpansScopeSpans, scopeSpans)
+	return appendMessage(nil, requestResourceSpans, resourceSpans)
+}
+
+func encodeSpan(span *trace.SpanData) []byte {
+	b := appendBytes(nil, spanTraceID, span.TraceID[:])
+	b = appendBytes(b, spanSpanID, span.SpanID[:])
+	b = appendString(b, spanTraceState, encodeTraceState(span))
+	if span.ParentSpanID != (trace.SpanID{}) {
+		b = appendBytes(b, spanParentSpanID, span.ParentSpanID[:])
+	}
+	b = appendString(b, spanName, span.Name)
+	b = appendVarint(b, spanKind, encodeSpanKind(span.SpanKind))
+	b = appendTime(b, spanStartTime, span.StartTime)
+	b = appendTime(b, spanEndTime, span.EndTime)
+	b = appendAttributes(b, spanAttributes, span.Attributes)
+	b = appendVarint(b, spanDroppedAttributesCount, uint64(span.DroppedAttributeCount))
+	for _, annotation := range span.Annotations {
+		event := appendTime(nil, eventTime, annotation.Time)
+		event = appendString(event, eventName, annotation.Message)
+		event = appendAttributes(event, eventAttributes, annotation.Attributes)
+		b = appendMessage(b, spanEvents, event)
+	}
+	for _, messageEvent := range span.MessageEvents {
+		b = appendMessage(b, spanEvents, encodeMessageEvent(messageEvent))
+	}
+	b = appendVarint(b, spanDroppedEventsCount, uint64(span.DroppedAnnotationCount+span.DroppedMessageEventCount))
+	for _, link := range span.Links {
+		encodedLink := appendBytes(nil, linkTraceID, link.TraceID[:])
+		encodedLink = appendBytes(encodedLink, linkSpanID, link.SpanID[:])
+		encodedLink = appendAttributes(encodedLink, linkAttributes, link.Attributes)
+		b = appendMessage(b, spanLinks, encodedLink)
+	}
+	b = appendVarint(b, spanDroppedLinksCount, uint64(span.DroppedLinkCount))
+	if span.Code != trace.StatusCodeOK {
+		status := appendString(nil, statusMessage, span.Message)
+		status = appendVarint(status, statusCode, statusCodeError)
+		b = appendMessage(b, spanStatus, status)
+	}
+	return b
+}
+
+func encodeTraceState(span *trace.SpanData) string {
+	var entries []string
+	for _, entry := range span.Tracestate.Entries() {
+		entries = append(entries, entry.Key+"="+entry.Value)
+	}
+	return strings.Join(entries, ",")
+}
+
+func encodeSpanKind(kind int) uint64 {
+	switch kind {
+	case trace.SpanKindServer:
+		return spanKindServer
+	case trace.SpanKindClient:
+		return spanKindClient
+	default:
+		return spanKindInternal
+	}
+}
+
+// encodeMessageEvent encodes OpenCensus message events as "message" events with the
+// message attributes of the OpenTelemetry RPC conventions.
+func encodeMessageEvent(messageEvent trace.MessageEvent) []byte {
+	messageType := "SENT"
+	if messageEvent.EventType == trace.MessageEventTypeRecv {
+		messageType = "RECEIVED"
+	}
+	event := appendTime(nil, eventTime, messageEvent.Time)
+	event = appendString(event, eventName, MessageEventName)
+	return appendAttributes(event, eventAttributes, map[string]interface{}{
+		"message.type":              messageType,
+		"message.id":                messageEvent.MessageID,
+		"message.uncompressed_size": messageEvent.UncompressedByteSize,
+		"message.compressed_size":   messageEvent.CompressedByteSize,
+	})
+}
+
+// appendAttributes appends attributes sorted by key, so equal spans encode equally.
+func appendAttributes(b []byte, number protowire.Number, attributes map[string]interface{}) []byte {
+	keys := make([]string, 0, len(attributes))
+	for key := range attributes {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		b = appendMessage(b, number, encodeKeyValue(key, attributes[key]))
+	}
+	return b
+}
+
+func encodeKeyValue(key string, value interface{}) []byte {
+	var anyValue []byte
+	switch value := value.(type) {
+	case bool:
+		anyValue = protowire.AppendTag(nil, anyValueBool, protowire.VarintType)
+		anyValue = protowire.AppendVarint(anyValue, protowire.EncodeBool(value))
+	case int64:
+		anyValue = protowire.AppendTag(nil, anyValueInt, protowire.VarintType)
+		anyValue = protowire.AppendVarint(anyValue, uint64(value))
+	case float64:
+		anyValue = protowire.AppendTag(nil, anyValueDouble, protowire.Fixed64Type)
+		anyValue = protowire.AppendFixed64(anyValue, math.Float64bits(value))
+	case string:
+		anyValue = protowire.AppendTag(nil, anyValueString, protowire.BytesType)
+		anyValue = protowire.AppendString(anyValue, value)
+	}
+	keyValue := appendString(nil, keyValueKey, key)
+	return appendMessage(keyValue, keyValueValue, anyValue)
+}
+
+func appendMessage(b []byte, number protowire.Number, message []byte) []byte {
+	b = protowire.AppendTag(b, number, protowire.BytesType)
+	return protowire.AppendBytes(b, message)
+}
+
+func appendBytes(b []byte, number protowire.Number, value []byte) []byte {
+	return appendMessage(b, number, value)
+}
+
+// appendString, appendVarint and appendTime skip zero values like proto3 does.
+func appendString(b []byte, number protowire.Number, value string) []byte {
+	if value == "" {
+		return b
+	}
+	b = protowire.AppendTag(b, number, protowire.BytesType)
+	return protowire.AppendString(b, value)
+}
+
+func appendVarint(b []byte, number protowire.Number, value uint64) []byte {
+	if value == 0 {
+		return b
+	}
+	b = protowire.AppendTag(b, number, protowire.VarintType)
+	return protowire.AppendVarint(b, value)
+}
+
+func appendTime(b []byte, number protowire.Number, value time.Time) []byte {
+	if value.IsZero() {
+		return b
+	}
+	b = protowire.AppendTag(b, number, protowire.Fixed64Type)
+	return protowire.AppendFixed64(b, uint64(value.UnixNano()))
+}
@@ -0,0 +1,193 @@
+package otlp
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+	"go.opencensus.io/trace"
+	"go.opencensus.io/trace/tracestate"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// message holds the decoded fields of an encoded message, bytes fields as []byte and
+// varint or fixed64 fields as uint64.
+type message map[protowire.Number][]interface{}
+
+func decode(b []byte) message {
+	decoded := message{}
+	for len(b) > 0 {
+		number, fieldType, n := protowire.ConsumeTag(b)
+		b = b[n:]
+		switch fieldType {
+		case protowire.BytesType:
+			value, n := protowire.ConsumeBytes(b)
+			decoded[number] = append(decoded[number], value)
+			b = b[n:]
+		case protowire.VarintType:
+			value, n := protowire.ConsumeVarint(b)
+			decoded[number] = append(decoded[number], value)
+			b = b[n:]
+		case protowire.Fixed64Type:
+			value, n := protowire.ConsumeFixed64(b)
+			decoded[number] = append(decoded[number], value)
+			b = b[n:]
+		default:
+			panic("unexpected field type")
+		}
+	}
+	return decoded
+}
+
+func (m message) message(number protowire.Number) message {
+	return decode(m[number][0].([]byte))
+}
+
+func (m message) messages(number protowire.Number) []message {
+	var messages []message
+	for _, value := range m[number] {
+		messages = append(messages, decode(value.([]byte)))
+	}
+	return messages
+}
+
+func (m message) string(number protowire.Number) string {
+	if len(m[number]) == 0 {
+		return ""
+	}
+	return string(m[number][0].([]byte))
+}
+
+func (m message) uint(number protowire.Number) uint64 {
+	if len(m[number]) == 0 {
+		return 0
+	}
+	return m[number][0].(uint64)
+}
+
+// attributes decodes the key values of field number to their Go values.
+func (m message) attributes(number protowire.Number) map[string]interface{} {
+	attributes := map[string]interface{}{}
+	for _, keyValue := range m.messages(number) {
+		value := keyValue.message(keyValueValue)
+		switch {
+		case value[anyValueString] != nil:
+			attributes[keyValue.string(keyValueKey)] = value.string(anyValueString)
+		case value[anyValueBool] != nil:
+			attributes[keyValue.string(keyValueKey)] = value.uint(anyValueBool) == 1
+		case value[anyValueInt] != nil:
+			attributes[keyValue.string(keyValueKey)] = int64(value.uint(anyValueInt))
+		case value[anyValueDouble] != nil:
+			attributes[keyValue.string(keyValueKey)] = math.Float64frombits(value.uint(anyValueDouble))
+		}
+	}
+	return attributes
+}
+
+// decodeSpans returns the resource attributes, the scope and the spans of request.
+func decodeSpans(request []byte) (map[string]interface{}, message, []message) {
+	resourceSpans := decode(request).message(requestResourceSpans)
+	scopeSpans := resourceSpans.message(resourceSpansScopeSpans)
+	resource := resourceSpans.message(resourceSpansResource).attributes(resourceAttributes)
+	return resource, scopeSpans.message(scopeSpansScope), scopeSpans.messages(scopeSpansSpans)
+}
+
+type EncodeTestSuite struct {
+	suite.Suite
+}
+
+func TestEncodeTestSuite(t *testing.T) {
+	suite.Run(t, new(EncodeTestSuite))
+}
+
+func (suite *EncodeTestSuite) TestShouldEncodeResourceAndScope() {
+	resource, scope, spans := decodeSpans(encodeRequest("draft-service", []*trace.SpanData{{Name: "a"}, {Name: "b"}}))
+
+	suite.Equal(map[string]interface{}{ServiceNameAttribute: "draft-service"}, resource)
+	suite.Equal(ScopeName, scope.string(scopeName))
+	suite.Len(spans, 2)
+}
+
+func (suite *EncodeTestSuite) TestShouldEncodeSpan() {
+	start := time.Unix(1600000000, 5)
+	state, _ := tracestate.New(nil, tracestate.Entry{Key: "vendor", Value: "value"})
+	span := &trace.SpanData{
+		SpanContext: trace.SpanContext{
+			TraceID:    trace.TraceID{0x46, 0x3a, 0xc3, 0x5c, 0x9f, 0x64, 0x13, 0xad, 0x48, 0x48, 0x5a, 0x39, 0x53, 0xbb, 0x61, 0x24},
+			SpanID:     trace.SpanID{0xa2, 0xfb, 0x4a, 0x1d, 0x1a, 0x96, 0xd3, 0x12},
+			Tracestate: state,
+		},
+		ParentSpanID: trace.SpanID{1, 2, 3, 4, 5, 6, 7, 8},
+		SpanKind:     trace.SpanKindServer,
+		Name:         "/api/drafts",
+		StartTime:    start,
+		EndTime:      start.Add(time.Second),
+		Attributes:   map[string]interface{}{"http.path": "/api/drafts", "http.status_code": int64(500), "error": true, "ratio": 0.5},
+		Annotations: []trace.Annotation{
+			{Time: start, Message: "log", Attributes: map[string]interface{}{"level": "error"}},
+		},
+		Status:                 trace.Status{Code: trace.StatusCodeInternal, Message: "failed"},
+		DroppedAttributeCount:  1,
+		DroppedAnnotationCount: 2,
+	}
+
+	_, _, spans := decodeSpans(encodeRequest("draft-service", []*trace.SpanData{span}))
+
+	encoded := spans[0]
+	suite.Equal(span.TraceID[:], encoded[spanTraceID][0])
+	suite.Equal(span.SpanID[:], encoded[spanSpanID][0])
+	suite.Equal(span.ParentSpanID[:], encoded[spanParentSpanID][0])
+	suite.Equal("vendor=value", encoded.string(spanTraceState))
+	suite.Equal("/api/drafts", encoded.string(spanName))
+	suite.Equal(uint64(spanKindServer), encoded.uint(spanKind))
+	suite.Equal(uint64(start.UnixNano()), encoded.uint(spanStartTime))
+	suite.Equal(uint64(start.Add(time.Second).UnixNano()), encoded.uint(spanEndTime))
+	suite.Equal(span.Attributes, encoded.attributes(spanAttributes))
+	suite.Equal(uint64(1), encoded.uint(spanDroppedAttributesCount))
+	suite.Equal(uint64(2), encoded.uint(spanDroppedEventsCount))
+	event := encoded.message(spanEvents)
+	suite.Equal("log", event.string(eventName))
+	suite.Equal(uint64(start.UnixNano()), event.uint(eventTime))
+	suite.Equal(map[string]interface{}{"level": "error"}, event.attributes(eventAttributes))
+	status := encoded.message(spanStatus)
+	suite.Equal(uint64(statusCodeError), status.uint(statusCode))
+	suite.Equal("failed", status.string(statusMessage))
+}
+
+func (suite *EncodeTestSuite) TestShouldOmitParentAndStatusOfSuccessfulRootSpan() {
+	_, _, spans := decodeSpans(encodeRequest("draft-service", []*trace.SpanData{{Name: "root", SpanKind: trace.SpanKindUnspecified}}))
+
+	suite.Nil(spans[0][spanParentSpanID])
+	suite.Nil(spans[0][spanStatus])
+	suite.Equal(uint64(spanKindInternal), spans[0].uint(spanKind))
+}
+
+func (suite *EncodeTestSuite) TestShouldEncodeMessageEventsAndLinks() {
+	span := &trace.SpanData{
+		Name:     "client",
+		SpanKind: trace.SpanKindClient,
+		MessageEvents: []trace.MessageEvent{
+			{EventType: trace.MessageEventTypeRecv, MessageID: 1, UncompressedByteSize: 20, CompressedByteSize: 10},
+		},
+		Links: []trace.Link{
+			{TraceID: trace.TraceID{1}, SpanID: trace.SpanID{2}, Attributes: map[string]interface{}{"reason": "retry"}},
+		},
+	}
+
+	_, _, spans := decodeSpans(encodeRequest("draft-service", []*trace.SpanData{span}))
+
+	suite.Equal(uint64(spanKindClient), spans[0].uint(spanKind))
+	event := spans[0].message(spanEvents)
+	suite.Equal(MessageEventName, event.string(eventName))
+	suite.Equal(map[string]interface{}{
+		"message.type":              "RECEIVED",
+		"message.id":                int64(1),
+		"message.uncompressed_size": int64(20),
+		"message.compressed_size":   int64(10),
+	}, event.attributes(eventAttributes))
+	link := spans[0].message(spanLinks)
+	suite.Equal(span.Links[0].TraceID[:], link[linkTraceID][0])
+	suite.Equal(span.Links[0].SpanID[:], link[linkSpanID][0])
+	suite.Equal(map[string]interface{}{"reason": "retry"}, link.attributes(linkAttributes))
+}
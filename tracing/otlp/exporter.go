@@ -0,0 +1,190 @@
+package otlp
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/inclusi-blog/gola-utils/logging"
+	"github.com/inclusi-blog/gola-utils/model"
+	"go.opencensus.io/trace"
+)
+
+const (
+	GRPC = "grpc"
+	HTTP = "http"
+
+	defaultTimeout       = 10 * time.Second
+	defaultBatchSize     = 512
+	defaultFlushInterval = 5 * time.Second
+	minQueueSize         = 2048
+)
+
+type client interface {
+	export(ctx context.Context, request []byte) error
+	close() error
+}
+
+// Exporter exports OpenCensus spans to an OTLP collector, with the names, attributes,
+// annotations and status the spans were recorded with. Spans are sent in batches from a
+// background goroutine, spans exceeding the queue are dropped and counted.
+//
+// OTLP is encoded here rather than through the OpenTelemetry SDK and its OpenCensus
+// bridge, their modules need a newer Go and gRPC than this module builds with. The
+// otlpconformance module checks the encoding against the generated OTLP types.
+type Exporter struct {
+	client        client
+	serviceName   string
+	timeout       time.Duration
+	batchSize     int
+	flushInterval time.Duration
+	spans         chan *trace.SpanData
+	flushes       chan chan struct{}
+	stop          chan struct{}
+	done          chan struct{}
+	stopOnce      sync.Once
+	stopErr       error
+	dropped       uint64
+}
+
+// NewExporter returns a started exporter for serviceName, register it with
+// trace.RegisterExporter or use tracing.InitOTLP.
+func NewExporter(serviceName string, config model.OTLPConfig) (*Exporter, error) {
+	if config.Endpoint == "" {
+		return nil, fmt.Errorf("otlp endpoint must not be empty")
+	}
+	timeout := durationOrDefault(config.TimeoutInMillis, defaultTimeout)
+	var c client
+	var err error
+	switch config.Protocol {
+	case "", GRPC:
+		c, err = newGRPCClient(config)
+	case HTTP:
+		c, err = newHTTPClient(config, timeout)
+	default:
+		return nil, fmt.Errorf("not a valid otlp protocol: %q", config.Protocol)
+	}
+	if err != nil {
+		return nil, err
+	}
+	batchSize := config.BatchSize
+	if batchSize <= 0 {
+		batchSize = defaultBatchSize
+	}
+	queueSize := batchSize * 4
+	if queueSize < minQueueSize {
+		queueSize = minQueueSize
+	}
+	exporter := &Exporter{
+		client:        c,
+		serviceName:   serviceName,
+		timeout:       timeout,
+		batchSize:     batchSize,
+		flushInterval: durationOrDefault(config.FlushIntervalInMillis, defaultFlushInterval),
+		spans:         make(chan *trace.SpanData, queueSize),
+		flushes:       make(chan chan struct{}),
+		stop:          make(chan struct{}),
+		done:          make(chan struct{}),
+	}
+	go exporter.run()
+	return exporter, nil
+}
+
+func durationOrDefault(millis int, fallback time.Duration) time.Duration {
+	if millis <= 0 {
+		return fallback
+	}
+	return time.Duration(millis) * time.Millisecond
+}
+
+func (e *Exporter) ExportSpan(span *trace.SpanData) {
+	select {
+	case <-e.done:
+		atomic.AddUint64(&e.dropped, 1)
+		return
+	default:
+	}
+	select {
+	case e.spans <- span:
+	default:
+		atomic.AddUint64(&e.dropped, 1)
+	}
+}
+
+// Flush sends every queued span and waits until they are sent.
+func (e *Exporter) Flush() {
+	flushed := make(chan struct{})
+	select {
+	case e.flushes <- flushed:
+		<-flushed
+	case <-e.done:
+	}
+}
+
+// Stop flushes the exporter and closes its connection, later spans are dropped. It
+// returns the error closing the connection failed with, on every call.
+func (e *Exporter) Stop() error {
+	e.stopOnce.Do(func() {
+		close(e.stop)
+		<-e.done
+		e.stopErr = e.client.close()
+	})
+	return e.stopErr
+}
+
+// Dropped returns the number of spans dropped because the queue was full.
+func (e *Exporter) Dropped() uint64 {
+	return atomic.LoadUint64(&e.dropped)
+}
+
+func (e *Exporter) run() {
+	defer close(e.done)
+	ticker := time.NewTicker(e.flushInterval)
+	defer ticker.Stop()
+	var batch []*trace.SpanData
+	for {
+		select {
+		case span := <-e.spans:
+			if batch = append(batch, span); len(batch) >= e.batchSize {
+				batch = e.send(batch)
+			}
+		case <-ticker.C:
+			batch = e.send(batch)
+		case flushed := <-e.flushes:
+			batch = e.send(e.drain(batch))
+			close(flushed)
+		case <-e.stop:
+			e.send(e.drain(batch))
+			return
+		}
+	}
+}
+
+func (e *Exporter) drain(batch []*trace.SpanData) []*trace.SpanData {
+	for {
+		select {
+		case span := <-e.spans:
+			batch = append(batch, span)
+		default:
+			return batch
+		}
+	}
+}
+
+// send exports batch in requests of at most batchSize spans, it returns the emptied batch.
+func (e *Exporter) send(batch []*trace.SpanData) []*trace.SpanData {
+	for start := 0; start < len(batch); start += e.batchSize {
+		end := start + e.batchSize
+		if end > len(batch) {
+			end = len(batch)
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), e.timeout)
+		if err := e.client.export(ctx, encodeRequest(e.serviceName, batch[start:end])); err != nil {
+			logging.NewLoggerEntry().Errorf("otlp exporter - dropping %d spans: %v", end-start, err)
+		}
+		cancel()
+	}
+	return batch[:0]
+}
@@ -0,0 +1,152 @@
+package otlp
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/inclusi-blog/gola-utils/model"
+	"github.com/stretchr/testify/suite"
+	"go.opencensus.io/trace"
+)
+
+type recordingClient struct {
+	mu       sync.Mutex
+	requests [][]byte
+	err      error
+	closed   bool
+	closeErr error
+	block    chan struct{}
+}
+
+func (c *recordingClient) export(_ context.Context, request []byte) error {
+	if c.block != nil {
+		<-c.block
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.requests = append(c.requests, request)
+	return c.err
+}
+
+func (c *recordingClient) close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.closed = true
+	return c.closeErr
+}
+
+func (c *recordingClient) spanNames() [][]string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var batches [][]string
+	for _, request := range c.requests {
+		_, _, spans := decodeSpans(request)
+		var names []string
+		for _, span := range spans {
+			names = append(names, span.string(spanName))
+		}
+		batches = append(batches, names)
+	}
+	return batches
+}
+
+type ExporterTestSuite struct {
+	suite.Suite
+	client *recordingClient
+}
+
+func TestExporterTestSuite(t *testing.T) {
+	suite.Run(t, new(ExporterTestSuite))
+}
+
+func (suite *ExporterTestSuite) SetupTest() {
+	suite.client = &recordingClient{}
+}
+
+func (suite *ExporterTestSuite) newExporter(batchSize int) *Exporter {
+	exporter := &Exporter{
+		client:        suite.client,
+		serviceName:   "draft-service",
+		timeout:       defaultTimeout,
+		batchSize:     batchSize,
+		flushInterval: defaultFlushInterval,
+		spans:         make(chan *trace.SpanData, 4),
+		flushes:       make(chan chan struct{}),
+		stop:          make(chan struct{}),
+		done:          make(chan struct{}),
+	}
+	go exporter.run()
+	return exporter
+}
+
+func (suite *ExporterTestSuite) TestShouldRejectInvalidConfig() {
+	_, err := NewExporter("draft-service", model.OTLPConfig{})
+	suite.EqualError(err, "otlp endpoint must not be empty")
+
+	_, err = NewExporter("draft-service", model.OTLPConfig{Endpoint: "localhost:4317", Protocol: "thrift"})
+	suite.EqualError(err, `not a valid otlp protocol: "thrift"`)
+}
+
+func (suite *ExporterTestSuite) TestShouldSendSpansInBatchesOnFlush() {
+	exporter := suite.newExporter(2)
+
+	exporter.ExportSpan(&trace.SpanData{Name: "a"})
+	exporter.ExportSpan(&trace.SpanData{Name: "b"})
+	exporter.ExportSpan(&trace.SpanData{Name: "c"})
+	exporter.Flush()
+
+	suite.Equal([][]string{{"a", "b"}, {"c"}}, suite.client.spanNames())
+	exporter.Stop()
+}
+
+func (suite *ExporterTestSuite) TestShouldKeepExportingAfterClientError() {
+	suite.client.err = errors.New("unavailable")
+	exporter := suite.newExporter(10)
+
+	exporter.ExportSpan(&trace.SpanData{Name: "a"})
+	exporter.Flush()
+	exporter.ExportSpan(&trace.SpanData{Name: "b"})
+	exporter.Flush()
+
+	suite.Equal([][]string{{"a"}, {"b"}}, suite.client.spanNames())
+	exporter.Stop()
+}
+
+func (suite *ExporterTestSuite) TestShouldDropSpansWhenQueueIsFull() {
+	suite.client.block = make(chan struct{})
+	exporter := suite.newExporter(1)
+
+	exporter.ExportSpan(&trace.SpanData{Name: "sending"})
+	for exporter.Dropped() == 0 {
+		exporter.ExportSpan(&trace.SpanData{Name: "queued"})
+	}
+	close(suite.client.block)
+	exporter.Stop()
+
+	suite.Equal(uint64(1), exporter.Dropped())
+}
+
+func (suite *ExporterTestSuite) TestShouldFlushAndCloseOnStop() {
+	exporter := suite.newExporter(10)
+
+	exporter.ExportSpan(&trace.SpanData{Name: "a"})
+	suite.Nil(exporter.Stop())
+	suite.Nil(exporter.Stop())
+	exporter.ExportSpan(&trace.SpanData{Name: "b"})
+	exporter.Flush()
+
+	suite.Equal([][]string{{"a"}}, suite.client.spanNames())
+	suite.True(suite.client.closed)
+	suite.Equal(uint64(1), exporter.Dropped())
+}
+
+func (suite *ExporterTestSuite) TestShouldReturnCloseErrorOnStop() {
+	suite.client.closeErr = errors.New("connection reset")
+	exporter := suite.newExporter(10)
+
+	suite.EqualError(exporter.Stop(), "connection reset")
+	suite.EqualError(exporter.Stop(), "connection reset")
+	suite.True(suite.client.closed)
+}
@@ -0,0 +1,169 @@
+package otlpconformance
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/inclusi-blog/gola-utils/model"
+	"github.com/inclusi-blog/gola-utils/tracing/otlp"
+	"github.com/stretchr/testify/suite"
+	"go.opencensus.io/trace"
+	"go.opencensus.io/trace/tracestate"
+	collectorpb "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	tracepb "go.opentelemetry.io/proto/otlp/trace/v1"
+	"google.golang.org/protobuf/proto"
+)
+
+type ConformanceTestSuite struct {
+	suite.Suite
+	requests chan []byte
+	server   *httptest.Server
+	exporter *otlp.Exporter
+}
+
+func TestConformanceTestSuite(t *testing.T) {
+	suite.Run(t, new(ConformanceTestSuite))
+}
+
+func (suite *ConformanceTestSuite) SetupTest() {
+	suite.requests = make(chan []byte, 1)
+	suite.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		suite.requests <- body
+	}))
+	exporter, err := otlp.NewExporter("draft-service", model.OTLPConfig{Endpoint: suite.server.URL, Protocol: otlp.HTTP})
+	suite.Require().Nil(err)
+	suite.exporter = exporter
+}
+
+func (suite *ConformanceTestSuite) TearDownTest() {
+	suite.exporter.Stop()
+	suite.server.Close()
+}
+
+// export sends span through the exporter and decodes the request with the OTLP types.
+func (suite *ConformanceTestSuite) export(span *trace.SpanData) *collectorpb.ExportTraceServiceRequest {
+	suite.exporter.ExportSpan(span)
+	suite.exporter.Flush()
+	request := &collectorpb.ExportTraceServiceRequest{}
+	suite.Require().Nil(proto.Unmarshal(<-suite.requests, request))
+	return request
+}
+
+func attributes(keyValues []*commonpb.KeyValue) map[string]interface{} {
+	decoded := make(map[string]interface{}, len(keyValues))
+	for _, keyValue := range keyValues {
+		switch value := keyValue.GetValue().GetValue().(type) {
+		case *commonpb.AnyValue_StringValue:
+			decoded[keyValue.GetKey()] = value.StringValue
+		case *commonpb.AnyValue_IntValue:
+			decoded[keyValue.GetKey()] = value.IntValue
+		case *commonpb.AnyValue_DoubleValue:
+			decoded[keyValue.GetKey()] = value.DoubleValue
+		case *commonpb.AnyValue_BoolValue:
+			decoded[keyValue.GetKey()] = value.BoolValue
+		}
+	}
+	return decoded
+}
+
+func (suite *ConformanceTestSuite) TestShouldDecodeRequestWithOTLPTypes() {
+	start := time.Date(2020, 10, 10, 15, 4, 5, 0, time.UTC)
+	state, _ := tracestate.New(nil, tracestate.Entry{Key: "vendor", Value: "draft"})
+	span := &trace.SpanData{
+		SpanContext: trace.SpanContext{
+			TraceID:    trace.TraceID{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16},
+			SpanID:     trace.SpanID{1, 2, 3, 4, 5, 6, 7, 8},
+			Tracestate: state,
+		},
+		ParentSpanID: trace.SpanID{8, 7, 6, 5, 4, 3, 2, 1},
+		SpanKind:     trace.SpanKindServer,
+		Name:         "/api/drafts",
+		StartTime:    start,
+		EndTime:      start.Add(time.Second),
+		Attributes: map[string]interface{}{
+			"http.method":      "POST",
+			"http.status_code": int64(500),
+			"error":            true,
+			"draft.ratio":      0.5,
+		},
+		Annotations: []trace.Annotation{
+			{Time: start, Message: "draft saved", Attributes: map[string]interface{}{"level": "info"}},
+		},
+		MessageEvents: []trace.MessageEvent{
+			{Time: start, EventType: trace.MessageEventTypeRecv, MessageID: 1, UncompressedByteSize: 10, CompressedByteSize: 5},
+		},
+		Links: []trace.Link{
+			{TraceID: trace.TraceID{16}, SpanID: trace.SpanID{8}, Attributes: map[string]interface{}{"reason": "retry"}},
+		},
+		Status:                 trace.Status{Code: trace.StatusCodeInternal, Message: "draft not saved"},
+		DroppedAttributeCount:  1,
+		DroppedAnnotationCount: 2,
+		DroppedLinkCount:       3,
+	}
+
+	request := suite.export(span)
+
+	suite.Require().Equal(1, len(request.GetResourceSpans()))
+	resourceSpans := request.GetResourceSpans()[0]
+	suite.Equal(map[string]interface{}{otlp.ServiceNameAttribute: "draft-service"}, attributes(resourceSpans.GetResource().GetAttributes()))
+	suite.Require().Equal(1, len(resourceSpans.GetInstrumentationLibrarySpans()))
+	scopeSpans := resourceSpans.GetInstrumentationLibrarySpans()[0]
+	suite.Equal(otlp.ScopeName, scopeSpans.GetInstrumentationLibrary().GetName())
+	suite.Require().Equal(1, len(scopeSpans.GetSpans()))
+
+	decoded := scopeSpans.GetSpans()[0]
+	suite.Equal(span.TraceID[:], decoded.GetTraceId())
+	suite.Equal(span.SpanID[:], decoded.GetSpanId())
+	suite.Equal("vendor=draft", decoded.GetTraceState())
+	suite.Equal(span.ParentSpanID[:], decoded.GetParentSpanId())
+	suite.Equal("/api/drafts", decoded.GetName())
+	suite.Equal(tracepb.Span_SPAN_KIND_SERVER, decoded.GetKind())
+	suite.Equal(uint64(start.UnixNano()), decoded.GetStartTimeUnixNano())
+	suite.Equal(uint64(start.Add(time.Second).UnixNano()), decoded.GetEndTimeUnixNano())
+	suite.Equal(span.Attributes, attributes(decoded.GetAttributes()))
+	suite.Equal(uint32(1), decoded.GetDroppedAttributesCount())
+	suite.Equal(uint32(2), decoded.GetDroppedEventsCount())
+	suite.Equal(uint32(3), decoded.GetDroppedLinksCount())
+	suite.Equal(tracepb.Status_STATUS_CODE_ERROR, decoded.GetStatus().GetCode())
+	suite.Equal("draft not saved", decoded.GetStatus().GetMessage())
+
+	suite.Require().Equal(2, len(decoded.GetEvents()))
+	annotation := decoded.GetEvents()[0]
+	suite.Equal(uint64(start.UnixNano()), annotation.GetTimeUnixNano())
+	suite.Equal("draft saved", annotation.GetName())
+	suite.Equal(map[string]interface{}{"level": "info"}, attributes(annotation.GetAttributes()))
+	messageEvent := decoded.GetEvents()[1]
+	suite.Equal(otlp.MessageEventName, messageEvent.GetName())
+	suite.Equal(map[string]interface{}{
+		"message.type":              "RECEIVED",
+		"message.id":                int64(1),
+		"message.uncompressed_size": int64(10),
+		"message.compressed_size":   int64(5),
+	}, attributes(messageEvent.GetAttributes()))
+
+	suite.Require().Equal(1, len(decoded.GetLinks()))
+	link := decoded.GetLinks()[0]
+	suite.Equal(span.Links[0].TraceID[:], link.GetTraceId())
+	suite.Equal(span.Links[0].SpanID[:], link.GetSpanId())
+	suite.Equal(map[string]interface{}{"reason": "retry"}, attributes(link.GetAttributes()))
+}
+
+func (suite *ConformanceTestSuite) TestShouldDecodeSuccessfulRootSpanWithoutParentAndStatus() {
+	span := &trace.SpanData{
+		SpanContext: trace.SpanContext{TraceID: trace.TraceID{1}, SpanID: trace.SpanID{2}},
+		Name:        "publish-draft",
+		StartTime:   time.Now(),
+		EndTime:     time.Now(),
+	}
+
+	decoded := suite.export(span).GetResourceSpans()[0].GetInstrumentationLibrarySpans()[0].GetSpans()[0]
+
+	suite.Empty(decoded.GetParentSpanId())
+	suite.Nil(decoded.GetStatus())
+	suite.Equal(tracepb.Span_SPAN_KIND_INTERNAL, decoded.GetKind())
+}
@@ -0,0 +1,5 @@
+// Package otlpconformance checks the OTLP requests of the otlp exporter against the
+// generated OTLP types. It is a module of its own, so the OTLP protobuf module and the
+// gRPC version it needs stay out of the dependencies of gola-utils. Run its tests with
+// "go test ./..." from this directory.
+package otlpconformance
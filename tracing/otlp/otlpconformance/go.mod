@@ -0,0 +1,13 @@
+module github.com/inclusi-blog/gola-utils/tracing/otlp/otlpconformance
+
+go 1.13
+
+require (
+	github.com/inclusi-blog/gola-utils v0.0.0-00010101000000-000000000000
+	github.com/stretchr/testify v1.5.1
+	go.opencensus.io v0.22.3
+	go.opentelemetry.io/proto/otlp v0.7.0
+	google.golang.org/protobuf v1.25.0
+)
+
+replace github.com/inclusi-blog/gola-utils => ../../..
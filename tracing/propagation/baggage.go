@@ -0,0 +1,112 @@
+package propagation
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/inclusi-blog/gola-utils/constants"
+)
+
+const BaggageHeader = "baggage"
+
+// sessionHeaders maps the baggage keys of the session tracing fields to the headers
+// that carried them before baggage was propagated.
+var sessionHeaders = map[string]string{
+	constants.TRACING_SESSION_ID:  constants.TRACING_SESSION_HEADER_KEY,
+	constants.TRACING_APP_VERSION: constants.TRACING_APP_VERSION_HEADER_KEY,
+	constants.TRACING_DEVICE_INFO: constants.TRACING_DEVICE_INFO_HEADER_KEY,
+}
+
+// Baggage holds the W3C baggage entries of a request, entry properties are dropped.
+type Baggage map[string]string
+
+type baggageKey struct{}
+
+// ParseBaggage parses the value of a W3C baggage header, invalid entries are skipped.
+func ParseBaggage(header string) Baggage {
+	baggage := Baggage{}
+	for _, member := range strings.Split(header, ",") {
+		member = strings.SplitN(member, ";", 2)[0]
+		keyValue := strings.SplitN(member, "=", 2)
+		if len(keyValue) != 2 {
+			continue
+		}
+		key := strings.TrimSpace(keyValue[0])
+		value, err := url.PathUnescape(strings.TrimSpace(keyValue[1]))
+		if key == "" || err != nil {
+			continue
+		}
+		baggage[key] = value
+	}
+	return baggage
+}
+
+// String formats the baggage as a W3C baggage header value, sorted by key.
+func (b Baggage) String() string {
+	keys := make([]string, 0, len(b))
+	for key := range b {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	members := make([]string, 0, len(keys))
+	for _, key := range keys {
+		members = append(members, key+"="+url.PathEscape(b[key]))
+	}
+	return strings.Join(members, ",")
+}
+
+// BaggageFromRequest returns the baggage of req. The Session-Tracing-ID, App-Version
+// and Device-Info headers fill the session_tracing_id, app_version and device_info
+// entries missing from the baggage header.
+func BaggageFromRequest(req *http.Request) Baggage {
+	baggage := ParseBaggage(req.Header.Get(BaggageHeader))
+	for key, header := range sessionHeaders {
+		if value := req.Header.Get(header); value != "" && baggage[key] == "" {
+			baggage[key] = value
+		}
+	}
+	return baggage
+}
+
+// ContextWithBaggage returns a copy of ctx carrying baggage.
+func ContextWithBaggage(ctx context.Context, baggage Baggage) context.Context {
+	return context.WithValue(ctx, baggageKey{}, baggage)
+}
+
+// BaggageFromContext returns the baggage stored by ContextWithBaggage. For a gin
+// context without stored baggage it is read from the request, and a plain
+// "Session-Tracing-ID" context value is still honoured.
+func BaggageFromContext(ctx context.Context) Baggage {
+	if ginContext, ok := ctx.(*gin.Context); ok && ginContext.Request != nil {
+		if baggage, ok := ginContext.Request.Context().Value(baggageKey{}).(Baggage); ok {
+			return baggage
+		}
+		return BaggageFromRequest(ginContext.Request)
+	}
+	if baggage, ok := ctx.Value(baggageKey{}).(Baggage); ok {
+		return baggage
+	}
+	baggage := Baggage{}
+	if sessionTracingID, ok := ctx.Value(constants.TRACING_SESSION_HEADER_KEY).(string); ok {
+		baggage[constants.TRACING_SESSION_ID] = sessionTracingID
+	}
+	return baggage
+}
+
+// InjectBaggage sets the baggage header of req, and the session tracing headers for
+// services that do not read baggage yet.
+func InjectBaggage(baggage Baggage, req *http.Request) {
+	if len(baggage) == 0 {
+		return
+	}
+	req.Header.Set(BaggageHeader, baggage.String())
+	for key, header := range sessionHeaders {
+		if value := baggage[key]; value != "" {
+			req.Header.Set(header, value)
+		}
+	}
+}
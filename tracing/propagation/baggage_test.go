@@ -0,0 +1,87 @@
+package propagation
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/inclusi-blog/gola-utils/constants"
+	"github.com/stretchr/testify/suite"
+)
+
+type BaggageTestSuite struct {
+	suite.Suite
+	request *http.Request
+}
+
+func TestBaggageTestSuite(t *testing.T) {
+	suite.Run(t, new(BaggageTestSuite))
+}
+
+func (suite *BaggageTestSuite) SetupTest() {
+	suite.request, _ = http.NewRequest("GET", "/api/resource", nil)
+}
+
+func (suite *BaggageTestSuite) TestShouldParseBaggage() {
+	baggage := ParseBaggage(" tenant = gola ,device_info=android%2011;ttl=60,invalid,=empty")
+
+	suite.Equal(Baggage{"tenant": "gola", constants.TRACING_DEVICE_INFO: "android 11"}, baggage)
+}
+
+func (suite *BaggageTestSuite) TestShouldFormatBaggageSortedAndEscaped() {
+	baggage := Baggage{"tenant": "gola", constants.TRACING_DEVICE_INFO: "android 11,pixel"}
+
+	suite.Equal("device_info=android%2011%2Cpixel,tenant=gola", baggage.String())
+	suite.Equal(baggage, ParseBaggage(baggage.String()))
+}
+
+func (suite *BaggageTestSuite) TestShouldFillSessionFieldsFromLegacyHeaders() {
+	suite.request.Header.Set(BaggageHeader, "session_tracing_id=from-baggage")
+	suite.request.Header.Set(constants.TRACING_SESSION_HEADER_KEY, "from-header")
+	suite.request.Header.Set(constants.TRACING_APP_VERSION_HEADER_KEY, "version1")
+
+	suite.Equal(Baggage{
+		constants.TRACING_SESSION_ID:  "from-baggage",
+		constants.TRACING_APP_VERSION: "version1",
+	}, BaggageFromRequest(suite.request))
+}
+
+func (suite *BaggageTestSuite) TestShouldInjectBaggageAndLegacyHeaders() {
+	InjectBaggage(Baggage{constants.TRACING_SESSION_ID: "QWERTY-1234", "tenant": "gola"}, suite.request)
+
+	suite.Equal("session_tracing_id=QWERTY-1234,tenant=gola", suite.request.Header.Get(BaggageHeader))
+	suite.Equal("QWERTY-1234", suite.request.Header.Get(constants.TRACING_SESSION_HEADER_KEY))
+	suite.Empty(suite.request.Header.Get(constants.TRACING_APP_VERSION_HEADER_KEY))
+}
+
+func (suite *BaggageTestSuite) TestShouldNotInjectEmptyBaggage() {
+	InjectBaggage(Baggage{}, suite.request)
+
+	suite.Empty(suite.request.Header)
+}
+
+func (suite *BaggageTestSuite) TestShouldReadBaggageFromContext() {
+	ctx := ContextWithBaggage(context.Background(), Baggage{"tenant": "gola"})
+
+	suite.Equal(Baggage{"tenant": "gola"}, BaggageFromContext(ctx))
+	suite.Equal(Baggage{}, BaggageFromContext(context.Background()))
+}
+
+func (suite *BaggageTestSuite) TestShouldReadSessionTracingIDContextValue() {
+	ctx := context.WithValue(context.Background(), constants.TRACING_SESSION_HEADER_KEY, "QWERTY-1234")
+
+	suite.Equal(Baggage{constants.TRACING_SESSION_ID: "QWERTY-1234"}, BaggageFromContext(ctx))
+}
+
+func (suite *BaggageTestSuite) TestShouldReadBaggageFromGinContext() {
+	ginContext, _ := gin.CreateTestContext(httptest.NewRecorder())
+	ginContext.Request = suite.request
+	suite.request.Header.Set(constants.TRACING_DEVICE_INFO_HEADER_KEY, "deviceInfo")
+
+	suite.Equal(Baggage{constants.TRACING_DEVICE_INFO: "deviceInfo"}, BaggageFromContext(ginContext))
+
+	ginContext.Request = suite.request.WithContext(ContextWithBaggage(context.Background(), Baggage{"tenant": "gola"}))
+	suite.Equal(Baggage{"tenant": "gola"}, BaggageFromContext(ginContext))
+}
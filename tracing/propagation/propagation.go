@@ -0,0 +1,165 @@
+package propagation
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"sync/atomic"
+
+	"github.com/inclusi-blog/gola-utils/constants"
+	"go.opencensus.io/plugin/ochttp/propagation/b3"
+	"go.opencensus.io/plugin/ochttp/propagation/tracecontext"
+	"go.opencensus.io/trace"
+	ocpropagation "go.opencensus.io/trace/propagation"
+)
+
+const (
+	B3       = "b3"
+	B3Single = "b3-single"
+	W3C      = "w3c"
+
+	B3SingleHeader    = "b3"
+	TraceParentHeader = "traceparent"
+	TraceStateHeader  = "tracestate"
+)
+
+// composite extracts the span context with the first format that finds one and
+// injects it with every format.
+type composite []ocpropagation.HTTPFormat
+
+var (
+	// current holds the composite used by Default.
+	current atomic.Value
+	// localOnly holds the func set by SetLocalOnly.
+	localOnly atomic.Value
+)
+
+func init() {
+	current.Store(composite{&b3.HTTPFormat{}})
+}
+
+// New returns an HTTPFormat for the given formats, in order of precedence for
+// inbound requests. Outbound requests carry the headers of all of them.
+func New(formats ...string) (ocpropagation.HTTPFormat, error) {
+	if len(formats) == 0 {
+		return nil, fmt.Errorf("no trace propagation format given")
+	}
+	var httpFormats composite
+	for _, format := range formats {
+		switch strings.ToLower(strings.TrimSpace(format)) {
+		case B3:
+			httpFormats = append(httpFormats, &b3.HTTPFormat{})
+		case B3Single:
+			httpFormats = append(httpFormats, b3SingleFormat{})
+		case W3C:
+			httpFormats = append(httpFormats, &tracecontext.HTTPFormat{})
+		default:
+			return nil, fmt.Errorf("not a valid trace propagation format: %q", format)
+		}
+	}
+	return httpFormats, nil
+}
+
+// Configure sets the formats used by Default, e.g. Configure(W3C, B3) accepts both
+// and sends both. Without formats it reads the comma separated TRACE_PROPAGATION
+// environment variable, and keeps the current formats when that is empty too.
+// B3 multi-header is used until Configure is called.
+func Configure(formats ...string) error {
+	if len(formats) == 0 {
+		env := os.Getenv(constants.TRACE_PROPAGATION_ENV)
+		if env == "" {
+			return nil
+		}
+		formats = strings.Split(env, ",")
+	}
+	format, err := New(formats...)
+	if err != nil {
+		return err
+	}
+	current.Store(format)
+	return nil
+}
+
+// SetLocalOnly makes Default propagate the traces isLocalOnly reports as not sampled.
+// They are sampled locally only to decide later whether to keep them, downstream
+// services make their own decision. Pass nil to propagate every trace as sampled.
+func SetLocalOnly(isLocalOnly func(trace.TraceID) bool) {
+	localOnly.Store(isLocalOnly)
+}
+
+type configuredFormat struct{}
+
+// Default returns the HTTPFormat set by Configure. It reads the configuration on
+// every call, so handlers and transports built before Configure follow it as well.
+func Default() ocpropagation.HTTPFormat {
+	return configuredFormat{}
+}
+
+func (configuredFormat) SpanContextFromRequest(req *http.Request) (trace.SpanContext, bool) {
+	return current.Load().(ocpropagation.HTTPFormat).SpanContextFromRequest(req)
+}
+
+func (configuredFormat) SpanContextToRequest(sc trace.SpanContext, req *http.Request) {
+	if isLocalOnly, _ := localOnly.Load().(func(trace.TraceID) bool); isLocalOnly != nil && sc.IsSampled() && isLocalOnly(sc.TraceID) {
+		sc.TraceOptions = 0
+	}
+	current.Load().(ocpropagation.HTTPFormat).SpanContextToRequest(sc, req)
+}
+
+// TraceIDFromRequest returns the trace ID propagated to req in one of the configured
+// formats, or "" when there is none.
+func TraceIDFromRequest(req *http.Request) string {
+	spanContext, ok := Default().SpanContextFromRequest(req)
+	if !ok {
+		return ""
+	}
+	return spanContext.TraceID.String()
+}
+
+func (c composite) SpanContextFromRequest(req *http.Request) (trace.SpanContext, bool) {
+	for _, format := range c {
+		if spanContext, ok := format.SpanContextFromRequest(req); ok {
+			return spanContext, true
+		}
+	}
+	return trace.SpanContext{}, false
+}
+
+func (c composite) SpanContextToRequest(sc trace.SpanContext, req *http.Request) {
+	for _, format := range c {
+		format.SpanContextToRequest(sc, req)
+	}
+}
+
+// b3SingleFormat implements the single "b3" header,
+// {TraceId}-{SpanId}-{SamplingState}-{ParentSpanId} with the last two optional.
+type b3SingleFormat struct{}
+
+func (b3SingleFormat) SpanContextFromRequest(req *http.Request) (trace.SpanContext, bool) {
+	parts := strings.Split(req.Header.Get(B3SingleHeader), "-")
+	if len(parts) < 2 || len(parts) > 4 {
+		return trace.SpanContext{}, false
+	}
+	traceID, ok := b3.ParseTraceID(parts[0])
+	if !ok {
+		return trace.SpanContext{}, false
+	}
+	spanID, ok := b3.ParseSpanID(parts[1])
+	if !ok {
+		return trace.SpanContext{}, false
+	}
+	spanContext := trace.SpanContext{TraceID: traceID, SpanID: spanID}
+	if len(parts) > 2 && (parts[2] == "1" || parts[2] == "d") {
+		spanContext.TraceOptions = trace.TraceOptions(1)
+	}
+	return spanContext, true
+}
+
+func (b3SingleFormat) SpanContextToRequest(sc trace.SpanContext, req *http.Request) {
+	sampled := "0"
+	if sc.IsSampled() {
+		sampled = "1"
+	}
+	req.Header.Set(B3SingleHeader, fmt.Sprintf("%s-%s-%s", sc.TraceID, sc.SpanID, sampled))
+}
@@ -0,0 +1,132 @@
+package propagation
+
+import (
+	"net/http"
+	"os"
+	"testing"
+
+	"github.com/inclusi-blog/gola-utils/constants"
+	"github.com/stretchr/testify/suite"
+	"go.opencensus.io/plugin/ochttp/propagation/b3"
+	"go.opencensus.io/trace"
+)
+
+var spanContext = trace.SpanContext{
+	TraceID:      trace.TraceID{0x4b, 0xf9, 0x2f, 0x35, 0x77, 0xb3, 0x4d, 0xa6, 0xa3, 0xce, 0x92, 0x9d, 0x0e, 0x0e, 0x47, 0x36},
+	SpanID:       trace.SpanID{0x00, 0xf0, 0x67, 0xaa, 0x0b, 0xa9, 0x02, 0xb7},
+	TraceOptions: trace.TraceOptions(1),
+}
+
+type PropagationTestSuite struct {
+	suite.Suite
+	request *http.Request
+}
+
+func TestPropagationTestSuite(t *testing.T) {
+	suite.Run(t, new(PropagationTestSuite))
+}
+
+func (suite *PropagationTestSuite) SetupTest() {
+	suite.request, _ = http.NewRequest("GET", "/api/resource", nil)
+}
+
+func (suite *PropagationTestSuite) TearDownTest() {
+	_ = os.Unsetenv(constants.TRACE_PROPAGATION_ENV)
+	suite.Nil(Configure(B3))
+}
+
+func (suite *PropagationTestSuite) TestShouldInjectEveryFormat() {
+	format, err := New(W3C, B3, B3Single)
+	suite.Nil(err)
+
+	format.SpanContextToRequest(spanContext, suite.request)
+
+	suite.Equal("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", suite.request.Header.Get(TraceParentHeader))
+	suite.Equal("4bf92f3577b34da6a3ce929d0e0e4736", suite.request.Header.Get(b3.TraceIDHeader))
+	suite.Equal("00f067aa0ba902b7", suite.request.Header.Get(b3.SpanIDHeader))
+	suite.Equal("4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-1", suite.request.Header.Get(B3SingleHeader))
+}
+
+func (suite *PropagationTestSuite) TestShouldExtractWithFirstMatchingFormat() {
+	format, err := New(W3C, B3)
+	suite.Nil(err)
+	suite.request.Header.Set(b3.TraceIDHeader, "463ac35c9f6413ad48485a3953bb6124")
+	suite.request.Header.Set(b3.SpanIDHeader, "a2fb4a1d1a96d312")
+
+	extracted, ok := format.SpanContextFromRequest(suite.request)
+	suite.True(ok)
+	suite.Equal("463ac35c9f6413ad48485a3953bb6124", extracted.TraceID.String())
+
+	suite.request.Header.Set(TraceParentHeader, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	extracted, ok = format.SpanContextFromRequest(suite.request)
+	suite.True(ok)
+	suite.Equal(spanContext, extracted)
+}
+
+func (suite *PropagationTestSuite) TestShouldExtractB3SingleHeader() {
+	format, err := New(B3Single)
+	suite.Nil(err)
+
+	suite.request.Header.Set(B3SingleHeader, "4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-d-05e3ac9a4f6e3b90")
+	extracted, ok := format.SpanContextFromRequest(suite.request)
+	suite.True(ok)
+	suite.Equal(spanContext, extracted)
+
+	suite.request.Header.Set(B3SingleHeader, "48485a3953bb6124-00f067aa0ba902b7")
+	extracted, ok = format.SpanContextFromRequest(suite.request)
+	suite.True(ok)
+	suite.Equal("000000000000000048485a3953bb6124", extracted.TraceID.String())
+	suite.False(extracted.IsSampled())
+
+	suite.request.Header.Set(B3SingleHeader, "0")
+	_, ok = format.SpanContextFromRequest(suite.request)
+	suite.False(ok)
+}
+
+func (suite *PropagationTestSuite) TestShouldRejectUnknownFormat() {
+	_, err := New(B3, "jaeger")
+	suite.EqualError(err, `not a valid trace propagation format: "jaeger"`)
+	suite.EqualError(Configure("jaeger"), `not a valid trace propagation format: "jaeger"`)
+}
+
+func (suite *PropagationTestSuite) TestShouldFollowConfiguredFormats() {
+	format := Default()
+	suite.request.Header.Set(TraceParentHeader, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	suite.Equal("", TraceIDFromRequest(suite.request))
+
+	suite.Nil(Configure(W3C))
+
+	suite.Equal("4bf92f3577b34da6a3ce929d0e0e4736", TraceIDFromRequest(suite.request))
+	outbound, _ := http.NewRequest("GET", "/api/other", nil)
+	format.SpanContextToRequest(spanContext, outbound)
+	suite.Equal("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", outbound.Header.Get(TraceParentHeader))
+	suite.Equal("", outbound.Header.Get(b3.TraceIDHeader))
+}
+
+func (suite *PropagationTestSuite) TestShouldConfigureFormatsFromEnv() {
+	_ = os.Setenv(constants.TRACE_PROPAGATION_ENV, "b3-single, w3c")
+
+	suite.Nil(Configure())
+
+	Default().SpanContextToRequest(spanContext, suite.request)
+	suite.NotEmpty(suite.request.Header.Get(B3SingleHeader))
+	suite.NotEmpty(suite.request.Header.Get(TraceParentHeader))
+}
+
+func (suite *PropagationTestSuite) TestShouldKeepFormatsWithoutConfig() {
+	suite.Nil(Configure())
+
+	Default().SpanContextToRequest(spanContext, suite.request)
+	suite.NotEmpty(suite.request.Header.Get(b3.TraceIDHeader))
+	suite.Empty(suite.request.Header.Get(TraceParentHeader))
+}
+
+func (suite *PropagationTestSuite) TestShouldPropagateLocalOnlyTraceAsNotSampled() {
+	SetLocalOnly(func(traceID trace.TraceID) bool { return traceID == spanContext.TraceID })
+	defer SetLocalOnly(nil)
+	suite.Nil(Configure(W3C))
+
+	Default().SpanContextToRequest(spanContext, suite.request)
+
+	suite.Equal("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00", suite.request.Header.Get(TraceParentHeader))
+}
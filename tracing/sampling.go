@@ -0,0 +1,269 @@
+package tracing
+
+import (
+	"fmt"
+	"math"
+	"path"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/inclusi-blog/gola-utils/model"
+	"go.opencensus.io/plugin/ochttp"
+	"go.opencensus.io/trace"
+)
+
+const (
+	AlwaysSample        = "always"
+	NeverSample         = "never"
+	ProbabilisticSample = "probabilistic"
+	RateLimitedSample   = "rate-limited"
+
+	maxDeferredSpans     = 256
+	deferredTraceTTL     = time.Minute
+	deferredPruneEvery   = 1024
+	errorSpanAttribute   = "error"
+	serverErrorThreshold = 500
+)
+
+type decider func(params trace.SamplingParameters) bool
+
+type samplingRule struct {
+	path    string
+	method  string
+	decider decider
+}
+
+type sampler struct {
+	fallback      decider
+	rules         []samplingRule
+	honorUpstream bool
+	sampleErrors  bool
+}
+
+// samplers holds the sampler configured by InitWithSampling, WithTracing reads it per request.
+var samplers atomic.Value
+
+func newSampler(config model.SamplingConfig) (*sampler, error) {
+	fallback, err := newDecider(config.Default)
+	if err != nil {
+		return nil, err
+	}
+	s := &sampler{fallback: fallback, honorUpstream: config.HonorUpstream, sampleErrors: config.SampleErrors}
+	for _, rule := range config.Rules {
+		if _, err := path.Match(rule.Path, ""); err != nil || rule.Path == "" {
+			return nil, fmt.Errorf("not a valid sampling rule path: %q", rule.Path)
+		}
+		ruleDecider, err := newDecider(rule.Sampler)
+		if err != nil {
+			return nil, err
+		}
+		s.rules = append(s.rules, samplingRule{path: rule.Path, method: strings.ToUpper(rule.Method), decider: ruleDecider})
+	}
+	return s, nil
+}
+
+func newDecider(config model.SamplerConfig) (decider, error) {
+	switch config.Type {
+	case "", AlwaysSample:
+		return func(trace.SamplingParameters) bool { return true }, nil
+	case NeverSample:
+		return func(trace.SamplingParameters) bool { return false }, nil
+	case ProbabilisticSample:
+		if config.Probability < 0 || config.Probability > 1 {
+			return nil, fmt.Errorf("sampling probability must be between 0 and 1, got %v", config.Probability)
+		}
+		probability := trace.ProbabilitySampler(config.Probability)
+		return func(params trace.SamplingParameters) bool { return probability(params).Sample }, nil
+	case RateLimitedSample:
+		if config.TracesPerSecond <= 0 {
+			return nil, fmt.Errorf("sampling rate must be positive, got %v traces per second", config.TracesPerSecond)
+		}
+		limiter := newRateLimiter(config.TracesPerSecond)
+		return func(trace.SamplingParameters) bool { return limiter.allow() }, nil
+	default:
+		return nil, fmt.Errorf("not a valid sampler type: %q", config.Type)
+	}
+}
+
+// forRequest samples the server span of a request with the first rule matching it.
+func (s *sampler) forRequest(method, requestPath string) trace.Sampler {
+	decide := s.fallback
+	for _, rule := range s.rules {
+		if matched, _ := path.Match(rule.path, requestPath); matched && (rule.method == "" || rule.method == method) {
+			decide = rule.decider
+			break
+		}
+	}
+	return s.sampler(decide, s.sampleErrors)
+}
+
+// defaultSampler samples the spans started outside of WithTracing. Spans with a parent,
+// including in-process children started with StartSpanWithRemoteParent, follow the
+// decision of their parent so sampled traces stay whole. Root spans use the fallback.
+func (s *sampler) defaultSampler() trace.Sampler {
+	root := s.sampler(s.fallback, false)
+	return func(params trace.SamplingParameters) trace.SamplingDecision {
+		if params.ParentContext.TraceID != (trace.TraceID{}) {
+			return trace.SamplingDecision{Sample: params.ParentContext.IsSampled()}
+		}
+		return root(params)
+	}
+}
+
+// sampler samples spans whose upstream caller sampled the trace, when honorUpstream is
+// set, and spans selected by decide. With deferErrors the other spans are recorded as
+// well and only exported when the span turns out to fail, see NewDeferredSamplingExporter.
+func (s *sampler) sampler(decide decider, deferErrors bool) trace.Sampler {
+	return func(params trace.SamplingParameters) trace.SamplingDecision {
+		if s.honorUpstream && params.HasRemoteParent && params.ParentContext.IsSampled() {
+			return trace.SamplingDecision{Sample: true}
+		}
+		if decide(params) {
+			return trace.SamplingDecision{Sample: true}
+		}
+		if deferErrors {
+			deferred.add(params.TraceID, params.SpanID)
+			return trace.SamplingDecision{Sample: true}
+		}
+		return trace.SamplingDecision{Sample: false}
+	}
+}
+
+// RootSampler samples spans like the default sampler samples root spans. It is meant for
+// spans continuing a trace whose sampling decision is unknown, see
+// trace.DeriveTracingFromRemoteParent. Without InitWithSampling it returns nil, which
+// leaves the decision to the OpenCensus default sampler.
+func RootSampler() trace.Sampler {
+	s := currentSampler()
+	if s == nil {
+		return nil
+	}
+	return s.sampler(s.fallback, false)
+}
+
+func currentSampler() *sampler {
+	s, _ := samplers.Load().(*sampler)
+	return s
+}
+
+// rateLimiter allows up to perSecond calls a second, with bursts of at most
+// perSecond calls, or one call for rates below one a second.
+type rateLimiter struct {
+	mutex     sync.Mutex
+	perSecond float64
+	balance   float64
+	last      time.Time
+	now       func() time.Time
+}
+
+func newRateLimiter(perSecond float64) *rateLimiter {
+	return &rateLimiter{perSecond: perSecond, balance: math.Max(perSecond, 1), last: time.Now(), now: time.Now}
+}
+
+func (l *rateLimiter) allow() bool {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	now := l.now()
+	l.balance = math.Min(math.Max(l.perSecond, 1), l.balance+now.Sub(l.last).Seconds()*l.perSecond)
+	l.last = now
+	if l.balance < 1 {
+		return false
+	}
+	l.balance--
+	return true
+}
+
+type deferredTrace struct {
+	root    trace.SpanID
+	spans   []*trace.SpanData
+	decided bool
+	keep    bool
+	expires time.Time
+}
+
+// deferredTraces holds the spans of traces recorded only in case their server span fails.
+type deferredTraces struct {
+	mutex   sync.Mutex
+	traces  map[trace.TraceID]*deferredTrace
+	inserts int
+}
+
+var deferred = &deferredTraces{traces: make(map[trace.TraceID]*deferredTrace)}
+
+func (d *deferredTraces) add(traceID trace.TraceID, root trace.SpanID) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	now := time.Now()
+	d.inserts++
+	if d.inserts%deferredPruneEvery == 0 {
+		for id, t := range d.traces {
+			if now.After(t.expires) {
+				delete(d.traces, id)
+			}
+		}
+	}
+	d.traces[traceID] = &deferredTrace{root: root, expires: now.Add(deferredTraceTTL)}
+}
+
+// localOnly reports whether traceID is recorded only in case its server span fails. Such
+// traces are sampled locally but propagated as not sampled.
+func (d *deferredTraces) localOnly(traceID trace.TraceID) bool {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	t, isDeferred := d.traces[traceID]
+	return isDeferred && !(t.decided && t.keep)
+}
+
+// release returns the spans to export once span ended: none while the server span of a
+// deferred trace is pending, all of them when it failed.
+func (d *deferredTraces) release(span *trace.SpanData) []*trace.SpanData {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	t, isDeferred := d.traces[span.TraceID]
+	if !isDeferred || (t.decided && t.keep) {
+		return []*trace.SpanData{span}
+	}
+	if t.decided {
+		return nil
+	}
+	if span.SpanID != t.root {
+		if len(t.spans) < maxDeferredSpans {
+			t.spans = append(t.spans, span)
+		}
+		return nil
+	}
+	t.decided, t.keep = true, failed(span)
+	spans := t.spans
+	t.spans = nil
+	if !t.keep {
+		return nil
+	}
+	return append(spans, span)
+}
+
+func failed(span *trace.SpanData) bool {
+	if statusCode, ok := span.Attributes[ochttp.StatusCodeAttribute].(int64); ok && statusCode >= serverErrorThreshold {
+		return true
+	}
+	isError, _ := span.Attributes[errorSpanAttribute].(bool)
+	return isError
+}
+
+type deferredSamplingExporter struct {
+	exporter trace.Exporter
+}
+
+// NewDeferredSamplingExporter holds back the spans of requests that were recorded only
+// because SampleErrors is set, and exports them once the server span of the request
+// ends with a 5xx status or the "error" attribute. Other exporters see every span.
+func NewDeferredSamplingExporter(exporter trace.Exporter) trace.Exporter {
+	return deferredSamplingExporter{exporter: exporter}
+}
+
+func (e deferredSamplingExporter) ExportSpan(span *trace.SpanData) {
+	for _, released := range deferred.release(span) {
+		e.exporter.ExportSpan(released)
+	}
+}
@@ -0,0 +1,181 @@
+package tracing
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/inclusi-blog/gola-utils/model"
+	"github.com/inclusi-blog/gola-utils/tracing/propagation"
+	"github.com/inclusi-blog/gola-utils/tracing/tracetest"
+	"github.com/stretchr/testify/suite"
+	"go.opencensus.io/plugin/ochttp"
+	"go.opencensus.io/trace"
+)
+
+type SamplingTestSuite struct {
+	suite.Suite
+	exporter *tracetest.Exporter
+	deferred trace.Exporter
+}
+
+func TestSamplingTestSuite(t *testing.T) {
+	suite.Run(t, new(SamplingTestSuite))
+}
+
+func (suite *SamplingTestSuite) SetupTest() {
+	suite.exporter = tracetest.NewExporter()
+	suite.deferred = NewDeferredSamplingExporter(suite.exporter)
+	trace.RegisterExporter(suite.deferred)
+	propagation.SetLocalOnly(deferred.localOnly)
+}
+
+func (suite *SamplingTestSuite) TearDownTest() {
+	trace.UnregisterExporter(suite.deferred)
+	samplers.Store((*sampler)(nil))
+	propagation.SetLocalOnly(nil)
+	trace.ApplyConfig(trace.Config{DefaultSampler: trace.AlwaysSample()})
+}
+
+func (suite *SamplingTestSuite) newSampler(config model.SamplingConfig) *sampler {
+	s, err := newSampler(config)
+	suite.Nil(err)
+	return s
+}
+
+func (suite *SamplingTestSuite) TestShouldRejectInvalidConfig() {
+	_, err := newSampler(model.SamplingConfig{Default: model.SamplerConfig{Type: "adaptive"}})
+	suite.EqualError(err, `not a valid sampler type: "adaptive"`)
+	_, err = newSampler(model.SamplingConfig{Default: model.SamplerConfig{Type: ProbabilisticSample, Probability: 1.5}})
+	suite.EqualError(err, "sampling probability must be between 0 and 1, got 1.5")
+	_, err = newSampler(model.SamplingConfig{Default: model.SamplerConfig{Type: RateLimitedSample}})
+	suite.EqualError(err, "sampling rate must be positive, got 0 traces per second")
+	_, err = newSampler(model.SamplingConfig{Rules: []model.SamplingRule{{Path: "/api/[drafts"}}})
+	suite.EqualError(err, `not a valid sampling rule path: "/api/[drafts"`)
+}
+
+func (suite *SamplingTestSuite) TestShouldReturnErrorForInvalidConfigBeforeConnecting() {
+	exporter, err := InitWithSampling("service", "localhost:55678", model.SamplingConfig{Default: model.SamplerConfig{Type: "adaptive"}})
+
+	suite.Nil(exporter)
+	suite.EqualError(err, `not a valid sampler type: "adaptive"`)
+}
+
+func (suite *SamplingTestSuite) TestShouldSampleWithFirstMatchingRule() {
+	s := suite.newSampler(model.SamplingConfig{
+		Default: model.SamplerConfig{Type: NeverSample},
+		Rules: []model.SamplingRule{
+			{Path: "/api/drafts/*", Method: "post", Sampler: model.SamplerConfig{Type: AlwaysSample}},
+			{Path: "/api/drafts/*", Sampler: model.SamplerConfig{Type: ProbabilisticSample, Probability: 0}},
+			{Path: "/api/*", Sampler: model.SamplerConfig{Type: AlwaysSample}},
+		},
+	})
+	params := trace.SamplingParameters{TraceID: trace.TraceID{1}}
+
+	suite.True(s.forRequest("POST", "/api/drafts/12")(params).Sample)
+	suite.False(s.forRequest("GET", "/api/drafts/12")(params).Sample)
+	suite.True(s.forRequest("GET", "/api/users")(params).Sample)
+	suite.False(s.forRequest("GET", "/metrics")(params).Sample)
+}
+
+func (suite *SamplingTestSuite) TestShouldHonorUpstreamSampledFlag() {
+	params := trace.SamplingParameters{
+		ParentContext:   trace.SpanContext{TraceOptions: trace.TraceOptions(1)},
+		HasRemoteParent: true,
+	}
+	honoring := suite.newSampler(model.SamplingConfig{Default: model.SamplerConfig{Type: NeverSample}, HonorUpstream: true})
+	ignoring := suite.newSampler(model.SamplingConfig{Default: model.SamplerConfig{Type: NeverSample}})
+
+	suite.True(honoring.forRequest("GET", "/api/users")(params).Sample)
+	suite.False(ignoring.forRequest("GET", "/api/users")(params).Sample)
+	params.ParentContext.TraceOptions = 0
+	suite.False(honoring.forRequest("GET", "/api/users")(params).Sample)
+}
+
+func (suite *SamplingTestSuite) TestShouldLimitTracesPerSecond() {
+	now := time.Now()
+	limiter := newRateLimiter(2)
+	limiter.last = now
+	limiter.now = func() time.Time { return now }
+
+	suite.True(limiter.allow())
+	suite.True(limiter.allow())
+	suite.False(limiter.allow())
+	now = now.Add(500 * time.Millisecond)
+	suite.True(limiter.allow())
+	suite.False(limiter.allow())
+	now = now.Add(time.Hour)
+	suite.True(limiter.allow())
+	suite.True(limiter.allow())
+	suite.False(limiter.allow())
+}
+
+func (suite *SamplingTestSuite) TestShouldExportDeferredTraceWhenRequestFails() {
+	samplers.Store(suite.newSampler(model.SamplingConfig{Default: model.SamplerConfig{Type: NeverSample}, SampleErrors: true}))
+	handler := WithTracing(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, child := trace.StartSpan(r.Context(), "query")
+		child.End()
+		if r.URL.Path == "/api/fail" {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}), "/healthz")
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/api/ok", nil))
+	suite.Empty(suite.exporter.Spans())
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/api/fail", nil))
+	suite.Equal([]string{"query", "/api/fail"}, suite.exporter.Names())
+	suite.Equal(int64(http.StatusInternalServerError), suite.exporter.Attributes("/api/fail")[ochttp.StatusCodeAttribute])
+}
+
+func (suite *SamplingTestSuite) TestShouldExportDeferredSpansEndingAfterFailedRequest() {
+	s := suite.newSampler(model.SamplingConfig{Default: model.SamplerConfig{Type: NeverSample}, SampleErrors: true})
+	ctx, root := trace.StartSpan(context.Background(), "/api/resource", trace.WithSampler(s.forRequest("GET", "/api/resource")))
+	_, late := trace.StartSpan(ctx, "async-job")
+	root.AddAttributes(trace.BoolAttribute("error", true))
+	root.End()
+	late.End()
+
+	suite.Equal([]string{"/api/resource", "async-job"}, suite.exporter.Names())
+}
+
+func (suite *SamplingTestSuite) TestShouldPassSampledTracesThrough() {
+	samplers.Store(suite.newSampler(model.SamplingConfig{SampleErrors: true}))
+	handler := WithTracing(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}), "/healthz")
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/api/ok", nil))
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/healthz", nil))
+
+	suite.Equal([]string{"/api/ok"}, suite.exporter.Names())
+}
+
+func (suite *SamplingTestSuite) TestShouldFollowParentDecisionForInProcessRemoteChildren() {
+	s := suite.newSampler(model.SamplingConfig{Default: model.SamplerConfig{Type: NeverSample}})
+	trace.ApplyConfig(trace.Config{DefaultSampler: s.defaultSampler()})
+	ctx, root := trace.StartSpan(context.Background(), "root", trace.WithSampler(trace.AlwaysSample()))
+	_, child := trace.StartSpanWithRemoteParent(ctx, "child", root.SpanContext())
+	_, unsampledRoot := trace.StartSpan(context.Background(), "unsampled")
+	_, unsampledChild := trace.StartSpanWithRemoteParent(ctx, "unsampled-child", unsampledRoot.SpanContext())
+
+	suite.True(root.SpanContext().IsSampled())
+	suite.True(child.SpanContext().IsSampled())
+	suite.False(unsampledRoot.SpanContext().IsSampled())
+	suite.False(unsampledChild.SpanContext().IsSampled())
+}
+
+func (suite *SamplingTestSuite) TestShouldPropagateDeferredTraceAsNotSampled() {
+	s := suite.newSampler(model.SamplingConfig{Default: model.SamplerConfig{Type: NeverSample}, SampleErrors: true})
+	_, root := trace.StartSpan(context.Background(), "/api/resource", trace.WithSampler(s.forRequest("GET", "/api/resource")))
+	request := httptest.NewRequest("GET", "/downstream", nil)
+
+	propagation.Default().SpanContextToRequest(root.SpanContext(), request)
+
+	suite.True(root.SpanContext().IsSampled())
+	suite.Equal("0", request.Header.Get("X-B3-Sampled"))
+	root.AddAttributes(trace.BoolAttribute("error", true))
+	root.End()
+	propagation.Default().SpanContextToRequest(root.SpanContext(), request)
+	suite.Equal("1", request.Header.Get("X-B3-Sampled"))
+}
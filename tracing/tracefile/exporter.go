@@ -0,0 +1,105 @@
+// Package tracefile writes spans to a JSON file that the Zipkin or Jaeger UI can open,
+// to inspect traces locally without an agent or collector.
+package tracefile
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/inclusi-blog/gola-utils/logging"
+	"go.opencensus.io/trace"
+)
+
+const (
+	Zipkin = "zipkin"
+	Jaeger = "jaeger"
+)
+
+type format struct {
+	prefix string
+	suffix string
+	encode func(serviceName string, span *trace.SpanData) interface{}
+}
+
+var formats = map[string]format{
+	Zipkin: {prefix: "[", suffix: "\n]\n", encode: zipkinSpan},
+	Jaeger: {prefix: `{"data":[`, suffix: "\n]}\n", encode: jaegerTrace},
+}
+
+// Exporter writes every exported span to its file. The file is a complete JSON document
+// after each span, so it can be opened while the service is running.
+type Exporter struct {
+	mu          sync.Mutex
+	file        *os.File
+	format      format
+	serviceName string
+	offset      int64
+	empty       bool
+}
+
+// NewExporter truncates or creates the file at path and writes the spans of serviceName
+// to it in format, Zipkin by default.
+func NewExporter(path, formatName, serviceName string) (*Exporter, error) {
+	if formatName == "" {
+		formatName = Zipkin
+	}
+	f, ok := formats[formatName]
+	if !ok {
+		return nil, fmt.Errorf("not a valid trace file format: %q", formatName)
+	}
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := file.WriteString(f.prefix + f.suffix); err != nil {
+		_ = file.Close()
+		return nil, err
+	}
+	return &Exporter{file: file, format: f, serviceName: serviceName, offset: int64(len(f.prefix)), empty: true}, nil
+}
+
+// ExportSpan overwrites the closing suffix of the document with span and a new suffix.
+func (e *Exporter) ExportSpan(span *trace.SpanData) {
+	encoded, err := json.Marshal(e.format.encode(e.serviceName, span))
+	if err != nil {
+		logging.NewLoggerEntry().Errorf("trace file exporter - error while encoding span %s: %v", span.Name, err)
+		return
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	separator := ","
+	if e.empty {
+		separator = ""
+	}
+	entry := separator + "\n" + string(encoded)
+	if _, err := e.file.WriteAt([]byte(entry+e.format.suffix), e.offset); err != nil {
+		logging.NewLoggerEntry().Errorf("trace file exporter - error while writing span %s: %v", span.Name, err)
+		return
+	}
+	e.offset += int64(len(entry))
+	e.empty = false
+}
+
+// Close closes the file, spans exported afterwards are dropped with an error log.
+func (e *Exporter) Close() error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.file.Close()
+}
+
+// Flush commits the written spans to disk.
+func (e *Exporter) Flush() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if err := e.file.Sync(); err != nil {
+		logging.NewLoggerEntry().Errorf("trace file exporter - error while flushing spans: %v", err)
+	}
+}
+
+// Stop flushes and closes the file, like Close.
+func (e *Exporter) Stop() error {
+	e.Flush()
+	return e.Close()
+}
@@ -0,0 +1,109 @@
+package tracefile
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+	"go.opencensus.io/trace"
+)
+
+var start = time.Unix(1600000000, 0)
+
+func testSpan(name string) *trace.SpanData {
+	return &trace.SpanData{
+		SpanContext: trace.SpanContext{
+			TraceID: trace.TraceID{0x46, 0x3a, 0xc3, 0x5c, 0x9f, 0x64, 0x13, 0xad, 0x48, 0x48, 0x5a, 0x39, 0x53, 0xbb, 0x61, 0x24},
+			SpanID:  trace.SpanID{0xa2, 0xfb, 0x4a, 0x1d, 0x1a, 0x96, 0xd3, 0x12},
+		},
+		Name:      name,
+		StartTime: start,
+		EndTime:   start.Add(1500 * time.Microsecond),
+	}
+}
+
+type ExporterTestSuite struct {
+	suite.Suite
+	path string
+}
+
+func TestExporterTestSuite(t *testing.T) {
+	suite.Run(t, new(ExporterTestSuite))
+}
+
+func (suite *ExporterTestSuite) SetupTest() {
+	dir, err := ioutil.TempDir("", "tracefile")
+	suite.Require().Nil(err)
+	suite.path = filepath.Join(dir, "traces.json")
+}
+
+func (suite *ExporterTestSuite) TearDownTest() {
+	_ = os.RemoveAll(filepath.Dir(suite.path))
+}
+
+func (suite *ExporterTestSuite) read(v interface{}) {
+	content, err := ioutil.ReadFile(suite.path)
+	suite.Require().Nil(err)
+	suite.Require().Nil(json.Unmarshal(content, v), string(content))
+}
+
+func (suite *ExporterTestSuite) TestShouldRejectUnknownFormat() {
+	_, err := NewExporter(suite.path, "thrift", "draft-service")
+
+	suite.EqualError(err, `not a valid trace file format: "thrift"`)
+}
+
+func (suite *ExporterTestSuite) TestShouldKeepZipkinFileValidAfterEverySpan() {
+	exporter, err := NewExporter(suite.path, "", "draft-service")
+	suite.Require().Nil(err)
+	defer exporter.Close()
+
+	var spans []zipkinModel
+	suite.read(&spans)
+	suite.Empty(spans)
+
+	exporter.ExportSpan(testSpan("query"))
+	suite.read(&spans)
+	suite.Len(spans, 1)
+
+	exporter.ExportSpan(testSpan("/api/drafts"))
+	spans = nil
+	suite.read(&spans)
+	suite.Equal("query", spans[0].Name)
+	suite.Equal("/api/drafts", spans[1].Name)
+	suite.Equal("draft-service", spans[1].LocalEndpoint.ServiceName)
+}
+
+func (suite *ExporterTestSuite) TestShouldKeepJaegerFileValidAfterEverySpan() {
+	exporter, err := NewExporter(suite.path, Jaeger, "draft-service")
+	suite.Require().Nil(err)
+	defer exporter.Close()
+
+	exporter.ExportSpan(testSpan("query"))
+	exporter.ExportSpan(testSpan("/api/drafts"))
+
+	var document struct {
+		Data []jaegerTraceModel `json:"data"`
+	}
+	suite.read(&document)
+	suite.Len(document.Data, 2)
+	suite.Equal("query", document.Data[0].Spans[0].OperationName)
+	suite.Equal("/api/drafts", document.Data[1].Spans[0].OperationName)
+	suite.Equal("draft-service", document.Data[1].Processes[jaegerProcessID].ServiceName)
+}
+
+func (suite *ExporterTestSuite) TestShouldDropSpansAfterClose() {
+	exporter, err := NewExporter(suite.path, Zipkin, "draft-service")
+	suite.Require().Nil(err)
+
+	suite.Nil(exporter.Close())
+	exporter.ExportSpan(testSpan("query"))
+
+	var spans []zipkinModel
+	suite.read(&spans)
+	suite.Empty(spans)
+}
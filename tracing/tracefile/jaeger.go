@@ -0,0 +1,121 @@
+package tracefile
+
+import (
+	"sort"
+
+	"go.opencensus.io/trace"
+)
+
+const (
+	jaegerProcessID = "p1"
+	spanKindTag     = "span.kind"
+	eventField      = "event"
+)
+
+// jaegerTraceModel is a trace of the Jaeger query API JSON, which the Jaeger UI opens.
+// Every span is written as a trace of its own, entries of one trace ID are shown together.
+type jaegerTraceModel struct {
+	TraceID   string                   `json:"traceID"`
+	Spans     []jaegerSpan             `json:"spans"`
+	Processes map[string]jaegerProcess `json:"processes"`
+}
+
+type jaegerSpan struct {
+	TraceID       string            `json:"traceID"`
+	SpanID        string            `json:"spanID"`
+	OperationName string            `json:"operationName"`
+	References    []jaegerReference `json:"references"`
+	StartTime     int64             `json:"startTime"`
+	Duration      int64             `json:"duration"`
+	Tags          []jaegerKeyValue  `json:"tags"`
+	Logs          []jaegerLog       `json:"logs"`
+	ProcessID     string            `json:"processID"`
+}
+
+type jaegerReference struct {
+	RefType string `json:"refType"`
+	TraceID string `json:"traceID"`
+	SpanID  string `json:"spanID"`
+}
+
+type jaegerKeyValue struct {
+	Key   string      `json:"key"`
+	Type  string      `json:"type"`
+	Value interface{} `json:"value"`
+}
+
+type jaegerLog struct {
+	Timestamp int64            `json:"timestamp"`
+	Fields    []jaegerKeyValue `json:"fields"`
+}
+
+type jaegerProcess struct {
+	ServiceName string           `json:"serviceName"`
+	Tags        []jaegerKeyValue `json:"tags"`
+}
+
+func jaegerTrace(serviceName string, span *trace.SpanData) interface{} {
+	model := jaegerSpan{
+		TraceID:       span.TraceID.String(),
+		SpanID:        span.SpanID.String(),
+		OperationName: span.Name,
+		References:    []jaegerReference{},
+		StartTime:     microseconds(span.StartTime),
+		Duration:      span.EndTime.Sub(span.StartTime).Microseconds(),
+		Tags:          jaegerKeyValues(span.Attributes),
+		Logs:          []jaegerLog{},
+		ProcessID:     jaegerProcessID,
+	}
+	if span.ParentSpanID != (trace.SpanID{}) {
+		model.References = append(model.References, jaegerReference{RefType: "CHILD_OF", TraceID: model.TraceID, SpanID: span.ParentSpanID.String()})
+	}
+	switch span.SpanKind {
+	case trace.SpanKindServer:
+		model.Tags = append(model.Tags, jaegerKeyValue{Key: spanKindTag, Type: "string", Value: "server"})
+	case trace.SpanKindClient:
+		model.Tags = append(model.Tags, jaegerKeyValue{Key: spanKindTag, Type: "string", Value: "client"})
+	}
+	if span.Code != trace.StatusCodeOK {
+		model.Tags = append(model.Tags, jaegerKeyValue{Key: errorTag, Type: "bool", Value: true})
+		model.Logs = append(model.Logs, jaegerLog{
+			Timestamp: microseconds(span.EndTime),
+			Fields:    []jaegerKeyValue{{Key: eventField, Type: "string", Value: errorTag}, {Key: "message", Type: "string", Value: statusMessage(span.Status)}},
+		})
+	}
+	for _, annotation := range span.Annotations {
+		model.Logs = append(model.Logs, jaegerEvent(microseconds(annotation.Time), annotation.Message, annotation.Attributes))
+	}
+	for _, messageEvent := range span.MessageEvents {
+		model.Logs = append(model.Logs, jaegerEvent(microseconds(messageEvent.Time), messageEventName, messageEventAttributes(messageEvent)))
+	}
+	sort.SliceStable(model.Logs, func(i, j int) bool { return model.Logs[i].Timestamp < model.Logs[j].Timestamp })
+	return jaegerTraceModel{
+		TraceID:   model.TraceID,
+		Spans:     []jaegerSpan{model},
+		Processes: map[string]jaegerProcess{jaegerProcessID: {ServiceName: serviceName, Tags: []jaegerKeyValue{}}},
+	}
+}
+
+func jaegerEvent(timestamp int64, message string, attributes map[string]interface{}) jaegerLog {
+	fields := []jaegerKeyValue{{Key: eventField, Type: "string", Value: message}}
+	return jaegerLog{Timestamp: timestamp, Fields: append(fields, jaegerKeyValues(attributes)...)}
+}
+
+// jaegerKeyValues converts attributes to key values sorted by key.
+func jaegerKeyValues(attributes map[string]interface{}) []jaegerKeyValue {
+	keyValues := []jaegerKeyValue{}
+	for key, value := range attributes {
+		keyValue := jaegerKeyValue{Key: key, Type: "string", Value: value}
+		switch value.(type) {
+		case bool:
+			keyValue.Type = "bool"
+		case int64:
+			keyValue.Type = "int64"
+		case float64:
+			keyValue.Type = "float64"
+		}
+		keyValues = append(keyValues, keyValue)
+	}
+	sort.Slice(keyValues, func(i, j int) bool { return keyValues[i].Key < keyValues[j].Key })
+	return keyValues
+}
@@ -0,0 +1,60 @@
+package tracefile
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+	"go.opencensus.io/trace"
+)
+
+type JaegerTestSuite struct {
+	suite.Suite
+}
+
+func TestJaegerTestSuite(t *testing.T) {
+	suite.Run(t, new(JaegerTestSuite))
+}
+
+func (suite *JaegerTestSuite) TestShouldEncodeSpanAsTrace() {
+	span := testSpan("/api/drafts")
+	span.ParentSpanID = trace.SpanID{1, 2, 3, 4, 5, 6, 7, 8}
+	span.SpanKind = trace.SpanKindClient
+	span.Attributes = map[string]interface{}{"http.status_code": int64(500), "http.path": "/api/drafts", "retried": true, "ratio": 0.5}
+	span.Annotations = []trace.Annotation{{Time: start.Add(time.Millisecond), Message: "log", Attributes: map[string]interface{}{"level": "error"}}}
+	span.Status = trace.Status{Code: trace.StatusCodeInternal, Message: "failed"}
+
+	model := jaegerTrace("draft-service", span).(jaegerTraceModel)
+
+	suite.Equal("463ac35c9f6413ad48485a3953bb6124", model.TraceID)
+	suite.Equal(map[string]jaegerProcess{"p1": {ServiceName: "draft-service", Tags: []jaegerKeyValue{}}}, model.Processes)
+	suite.Equal(jaegerSpan{
+		TraceID:       "463ac35c9f6413ad48485a3953bb6124",
+		SpanID:        "a2fb4a1d1a96d312",
+		OperationName: "/api/drafts",
+		References:    []jaegerReference{{RefType: "CHILD_OF", TraceID: "463ac35c9f6413ad48485a3953bb6124", SpanID: "0102030405060708"}},
+		StartTime:     1600000000000000,
+		Duration:      1500,
+		Tags: []jaegerKeyValue{
+			{Key: "http.path", Type: "string", Value: "/api/drafts"},
+			{Key: "http.status_code", Type: "int64", Value: int64(500)},
+			{Key: "ratio", Type: "float64", Value: 0.5},
+			{Key: "retried", Type: "bool", Value: true},
+			{Key: "span.kind", Type: "string", Value: "client"},
+			{Key: "error", Type: "bool", Value: true},
+		},
+		Logs: []jaegerLog{
+			{Timestamp: 1600000000001000, Fields: []jaegerKeyValue{{Key: "event", Type: "string", Value: "log"}, {Key: "level", Type: "string", Value: "error"}}},
+			{Timestamp: 1600000000001500, Fields: []jaegerKeyValue{{Key: "event", Type: "string", Value: "error"}, {Key: "message", Type: "string", Value: "failed"}}},
+		},
+		ProcessID: "p1",
+	}, model.Spans[0])
+}
+
+func (suite *JaegerTestSuite) TestShouldEncodeRootSpanWithEmptyLists() {
+	model := jaegerTrace("draft-service", testSpan("job")).(jaegerTraceModel)
+
+	suite.Equal([]jaegerReference{}, model.Spans[0].References)
+	suite.Equal([]jaegerKeyValue{}, model.Spans[0].Tags)
+	suite.Equal([]jaegerLog{}, model.Spans[0].Logs)
+}
@@ -0,0 +1,117 @@
+package tracefile
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"go.opencensus.io/trace"
+)
+
+const (
+	errorTag         = "error"
+	messageEventName = "message"
+)
+
+// zipkinModel is a span of the Zipkin v2 JSON API.
+type zipkinModel struct {
+	TraceID       string             `json:"traceId"`
+	ID            string             `json:"id"`
+	ParentID      string             `json:"parentId,omitempty"`
+	Name          string             `json:"name"`
+	Kind          string             `json:"kind,omitempty"`
+	Timestamp     int64              `json:"timestamp"`
+	Duration      int64              `json:"duration"`
+	LocalEndpoint zipkinEndpoint     `json:"localEndpoint"`
+	Annotations   []zipkinAnnotation `json:"annotations,omitempty"`
+	Tags          map[string]string  `json:"tags,omitempty"`
+}
+
+type zipkinEndpoint struct {
+	ServiceName string `json:"serviceName"`
+}
+
+type zipkinAnnotation struct {
+	Timestamp int64  `json:"timestamp"`
+	Value     string `json:"value"`
+}
+
+func zipkinSpan(serviceName string, span *trace.SpanData) interface{} {
+	model := zipkinModel{
+		TraceID:       span.TraceID.String(),
+		ID:            span.SpanID.String(),
+		Name:          span.Name,
+		Timestamp:     microseconds(span.StartTime),
+		Duration:      span.EndTime.Sub(span.StartTime).Microseconds(),
+		LocalEndpoint: zipkinEndpoint{ServiceName: serviceName},
+		Tags:          map[string]string{},
+	}
+	if span.ParentSpanID != (trace.SpanID{}) {
+		model.ParentID = span.ParentSpanID.String()
+	}
+	switch span.SpanKind {
+	case trace.SpanKindServer:
+		model.Kind = "SERVER"
+	case trace.SpanKindClient:
+		model.Kind = "CLIENT"
+	}
+	for key, value := range span.Attributes {
+		model.Tags[key] = fmt.Sprint(value)
+	}
+	if span.Code != trace.StatusCodeOK {
+		model.Tags[errorTag] = statusMessage(span.Status)
+	}
+	// Zipkin annotations are plain strings, so attributes are appended to the message.
+	for _, annotation := range span.Annotations {
+		model.Annotations = append(model.Annotations, zipkinAnnotation{
+			Timestamp: microseconds(annotation.Time),
+			Value:     withAttributes(annotation.Message, annotation.Attributes),
+		})
+	}
+	for _, messageEvent := range span.MessageEvents {
+		model.Annotations = append(model.Annotations, zipkinAnnotation{
+			Timestamp: microseconds(messageEvent.Time),
+			Value:     withAttributes(messageEventName, messageEventAttributes(messageEvent)),
+		})
+	}
+	return model
+}
+
+func microseconds(t time.Time) int64 {
+	return t.UnixNano() / int64(time.Microsecond)
+}
+
+func statusMessage(status trace.Status) string {
+	if status.Message != "" {
+		return status.Message
+	}
+	return fmt.Sprintf("status code %d", status.Code)
+}
+
+// withAttributes appends the attributes to message as key=value pairs sorted by key.
+func withAttributes(message string, attributes map[string]interface{}) string {
+	if len(attributes) == 0 {
+		return message
+	}
+	pairs := make([]string, 0, len(attributes))
+	for key, value := range attributes {
+		pairs = append(pairs, fmt.Sprintf("%s=%v", key, value))
+	}
+	sort.Strings(pairs)
+	return message + " " + strings.Join(pairs, " ")
+}
+
+// messageEventAttributes describes message events with the attributes of the OTLP export.
+func messageEventAttributes(messageEvent trace.MessageEvent) map[string]interface{} {
+	messageType := "SENT"
+	if messageEvent.EventType == trace.MessageEventTypeRecv {
+		messageType = "RECEIVED"
+	}
+	return map[string]interface{}{
+		"message.type":              messageType,
+		"message.id":                messageEvent.MessageID,
+		"message.uncompressed_size": messageEvent.UncompressedByteSize,
+		"message.compressed_size":   messageEvent.CompressedByteSize,
+	}
+}
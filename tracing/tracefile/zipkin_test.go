@@ -0,0 +1,57 @@
+package tracefile
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+	"go.opencensus.io/trace"
+)
+
+type ZipkinTestSuite struct {
+	suite.Suite
+}
+
+func TestZipkinTestSuite(t *testing.T) {
+	suite.Run(t, new(ZipkinTestSuite))
+}
+
+func (suite *ZipkinTestSuite) TestShouldEncodeSpan() {
+	span := testSpan("/api/drafts")
+	span.ParentSpanID = trace.SpanID{1, 2, 3, 4, 5, 6, 7, 8}
+	span.SpanKind = trace.SpanKindServer
+	span.Attributes = map[string]interface{}{"http.status_code": int64(500), "http.path": "/api/drafts"}
+	span.Annotations = []trace.Annotation{{Time: start.Add(time.Millisecond), Message: "log", Attributes: map[string]interface{}{"level": "error", "msg": "failed"}}}
+	span.MessageEvents = []trace.MessageEvent{{Time: start, EventType: trace.MessageEventTypeSent, MessageID: 1, UncompressedByteSize: 20}}
+	span.Status = trace.Status{Code: trace.StatusCodeInternal}
+
+	model := zipkinSpan("draft-service", span).(zipkinModel)
+
+	suite.Equal(zipkinModel{
+		TraceID:       "463ac35c9f6413ad48485a3953bb6124",
+		ID:            "a2fb4a1d1a96d312",
+		ParentID:      "0102030405060708",
+		Name:          "/api/drafts",
+		Kind:          "SERVER",
+		Timestamp:     1600000000000000,
+		Duration:      1500,
+		LocalEndpoint: zipkinEndpoint{ServiceName: "draft-service"},
+		Annotations: []zipkinAnnotation{
+			{Timestamp: 1600000000001000, Value: "log level=error msg=failed"},
+			{Timestamp: 1600000000000000, Value: "message message.compressed_size=0 message.id=1 message.type=SENT message.uncompressed_size=20"},
+		},
+		Tags: map[string]string{"http.status_code": "500", "http.path": "/api/drafts", "error": "status code 13"},
+	}, model)
+}
+
+func (suite *ZipkinTestSuite) TestShouldOmitParentAndKindOfInternalRootSpan() {
+	span := testSpan("job")
+	span.Status = trace.Status{Code: trace.StatusCodeUnknown, Message: "timeout"}
+
+	model := zipkinSpan("draft-service", span).(zipkinModel)
+
+	suite.Empty(model.ParentID)
+	suite.Empty(model.Kind)
+	suite.Empty(model.Annotations)
+	suite.Equal(map[string]string{"error": "timeout"}, model.Tags)
+}
@@ -0,0 +1,188 @@
+// Package tracetest records exported spans in memory, so tests can assert on the spans,
+// annotations and attributes that code under test produced.
+package tracetest
+
+import (
+	"sort"
+	"strings"
+	"sync"
+
+	"go.opencensus.io/trace"
+)
+
+// Exporter keeps every span exported to it, in the order the spans ended.
+type Exporter struct {
+	mu    sync.Mutex
+	spans []*trace.SpanData
+}
+
+// NewExporter returns an exporter that is not registered, wrap or register it yourself.
+func NewExporter() *Exporter {
+	return &Exporter{}
+}
+
+// Start returns an exporter registered with OpenCensus, unregister it with Stop.
+func Start() *Exporter {
+	exporter := NewExporter()
+	trace.RegisterExporter(exporter)
+	return exporter
+}
+
+func (e *Exporter) ExportSpan(span *trace.SpanData) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.spans = append(e.spans, span)
+}
+
+// Stop unregisters the exporter, the recorded spans are kept.
+func (e *Exporter) Stop() {
+	trace.UnregisterExporter(e)
+}
+
+// Reset forgets the recorded spans.
+func (e *Exporter) Reset() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.spans = nil
+}
+
+func (e *Exporter) Spans() []*trace.SpanData {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return append([]*trace.SpanData(nil), e.spans...)
+}
+
+// Names returns the names of the recorded spans in the order they ended.
+func (e *Exporter) Names() []string {
+	var names []string
+	for _, span := range e.Spans() {
+		names = append(names, span.Name)
+	}
+	return names
+}
+
+// Span returns the first recorded span named name.
+func (e *Exporter) Span(name string) (*trace.SpanData, bool) {
+	spans := e.SpansNamed(name)
+	if len(spans) == 0 {
+		return nil, false
+	}
+	return spans[0], true
+}
+
+func (e *Exporter) SpansNamed(name string) []*trace.SpanData {
+	var spans []*trace.SpanData
+	for _, span := range e.Spans() {
+		if span.Name == name {
+			spans = append(spans, span)
+		}
+	}
+	return spans
+}
+
+// Annotations returns the annotations of the spans named name.
+func (e *Exporter) Annotations(name string) []trace.Annotation {
+	var annotations []trace.Annotation
+	for _, span := range e.SpansNamed(name) {
+		annotations = append(annotations, span.Annotations...)
+	}
+	return annotations
+}
+
+// AnnotationMessages returns the messages of the annotations of the spans named name.
+func (e *Exporter) AnnotationMessages(name string) []string {
+	var messages []string
+	for _, annotation := range e.Annotations(name) {
+		messages = append(messages, annotation.Message)
+	}
+	return messages
+}
+
+// Attributes returns the attributes of the first span named name, nil when there is none.
+func (e *Exporter) Attributes(name string) map[string]interface{} {
+	span, ok := e.Span(name)
+	if !ok {
+		return nil
+	}
+	return span.Attributes
+}
+
+// Children returns the recorded spans whose parent is parent, ordered by start time.
+func (e *Exporter) Children(parent *trace.SpanData) []*trace.SpanData {
+	var children []*trace.SpanData
+	for _, span := range e.Spans() {
+		if span.TraceID == parent.TraceID && span.ParentSpanID == parent.SpanID {
+			children = append(children, span)
+		}
+	}
+	sortByStartTime(children)
+	return children
+}
+
+// Node is a recorded span with the recorded spans started as its children.
+type Node struct {
+	Span     *trace.SpanData
+	Children []*Node
+}
+
+// Trees reconstructs the recorded spans as trees. Spans whose parent was not recorded,
+// such as spans continuing a remote trace, are roots. Roots and children are ordered by
+// start time.
+func (e *Exporter) Trees() []*Node {
+	spans := e.Spans()
+	sortByStartTime(spans)
+	nodes := make(map[trace.SpanContext]*Node, len(spans))
+	for _, span := range spans {
+		nodes[spanKey(span.TraceID, span.SpanID)] = &Node{Span: span}
+	}
+	var roots []*Node
+	for _, span := range spans {
+		node := nodes[spanKey(span.TraceID, span.SpanID)]
+		if parent, ok := nodes[spanKey(span.TraceID, span.ParentSpanID)]; ok && parent != node {
+			parent.Children = append(parent.Children, node)
+		} else {
+			roots = append(roots, node)
+		}
+	}
+	return roots
+}
+
+func spanKey(traceID trace.TraceID, spanID trace.SpanID) trace.SpanContext {
+	return trace.SpanContext{TraceID: traceID, SpanID: spanID}
+}
+
+func sortByStartTime(spans []*trace.SpanData) {
+	sort.SliceStable(spans, func(i, j int) bool {
+		return spans[i].StartTime.Before(spans[j].StartTime)
+	})
+}
+
+// Find returns the first node named name in the tree, depth first.
+func (n *Node) Find(name string) (*Node, bool) {
+	if n.Span.Name == name {
+		return n, true
+	}
+	for _, child := range n.Children {
+		if found, ok := child.Find(name); ok {
+			return found, true
+		}
+	}
+	return nil, false
+}
+
+// String renders the span names of the tree, one per line and children indented by two
+// spaces, to compare trees in assertions.
+func (n *Node) String() string {
+	var builder strings.Builder
+	n.write(&builder, 0)
+	return builder.String()
+}
+
+func (n *Node) write(builder *strings.Builder, depth int) {
+	builder.WriteString(strings.Repeat("  ", depth))
+	builder.WriteString(n.Span.Name)
+	builder.WriteString("\n")
+	for _, child := range n.Children {
+		child.write(builder, depth+1)
+	}
+}
@@ -0,0 +1,105 @@
+package tracetest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+	"go.opencensus.io/trace"
+)
+
+type TraceTestTestSuite struct {
+	suite.Suite
+	exporter *Exporter
+}
+
+func TestTraceTestTestSuite(t *testing.T) {
+	suite.Run(t, new(TraceTestTestSuite))
+}
+
+func (suite *TraceTestTestSuite) SetupTest() {
+	suite.exporter = Start()
+}
+
+func (suite *TraceTestTestSuite) TearDownTest() {
+	suite.exporter.Stop()
+}
+
+func startSpan(ctx context.Context, name string) (context.Context, *trace.Span) {
+	return trace.StartSpan(ctx, name, trace.WithSampler(trace.AlwaysSample()))
+}
+
+func (suite *TraceTestTestSuite) TestShouldFindSpansAnnotationsAndAttributes() {
+	ctx, root := startSpan(context.Background(), "/api/drafts")
+	_, query := startSpan(ctx, "query")
+	query.AddAttributes(trace.StringAttribute("db.statement", "SELECT 1"))
+	query.Annotate([]trace.Attribute{trace.StringAttribute("level", "info")}, "fetched drafts")
+	query.End()
+	root.End()
+
+	suite.Equal([]string{"query", "/api/drafts"}, suite.exporter.Names())
+	span, ok := suite.exporter.Span("query")
+	suite.True(ok)
+	suite.Equal(query.SpanContext().SpanID, span.SpanID)
+	suite.Equal(map[string]interface{}{"db.statement": "SELECT 1"}, suite.exporter.Attributes("query"))
+	suite.Equal([]string{"fetched drafts"}, suite.exporter.AnnotationMessages("query"))
+	suite.Equal(map[string]interface{}{"level": "info"}, suite.exporter.Annotations("query")[0].Attributes)
+	_, ok = suite.exporter.Span("missing")
+	suite.False(ok)
+	suite.Nil(suite.exporter.Attributes("missing"))
+}
+
+func (suite *TraceTestTestSuite) TestShouldReconstructTrees() {
+	ctx, root := startSpan(context.Background(), "/api/drafts")
+	childCtx, first := startSpan(ctx, "authorize")
+	_, nested := startSpan(childCtx, "introspect")
+	nested.End()
+	first.End()
+	_, second := startSpan(ctx, "query")
+	second.End()
+	root.End()
+	_, other := startSpan(context.Background(), "job")
+	other.End()
+
+	trees := suite.exporter.Trees()
+
+	suite.Len(trees, 2)
+	suite.Equal("/api/drafts\n  authorize\n    introspect\n  query\n", trees[0].String())
+	suite.Equal("job\n", trees[1].String())
+	node, ok := trees[0].Find("introspect")
+	suite.True(ok)
+	suite.Equal(nested.SpanContext().SpanID, node.Span.SpanID)
+	rootSpan, _ := suite.exporter.Span("/api/drafts")
+	suite.Equal([]string{"authorize", "query"}, names(suite.exporter.Children(rootSpan)))
+}
+
+func (suite *TraceTestTestSuite) TestShouldTreatSpansOfUnrecordedParentsAsRoots() {
+	parent := trace.SpanContext{TraceID: trace.TraceID{1}, SpanID: trace.SpanID{2}, TraceOptions: 1}
+	_, remote := trace.StartSpanWithRemoteParent(context.Background(), "/api/drafts", parent)
+	remote.End()
+
+	trees := suite.exporter.Trees()
+
+	suite.Len(trees, 1)
+	suite.Equal("/api/drafts", trees[0].Span.Name)
+}
+
+func (suite *TraceTestTestSuite) TestShouldStopRecordingAfterStopAndForgetOnReset() {
+	_, span := startSpan(context.Background(), "recorded")
+	span.End()
+	suite.exporter.Stop()
+	_, span = startSpan(context.Background(), "ignored")
+	span.End()
+
+	suite.Equal([]string{"recorded"}, suite.exporter.Names())
+	suite.exporter.Reset()
+	suite.Empty(suite.exporter.Spans())
+}
+
+func names(spans []*trace.SpanData) []string {
+	var spanNames []string
+	for _, span := range spans {
+		spanNames = append(spanNames, span.Name)
+	}
+	return spanNames
+}
@@ -6,8 +6,14 @@ import (
 	"github.com/inclusi-blog/gola-utils/constants"
 	"github.com/inclusi-blog/gola-utils/logging"
 	"github.com/inclusi-blog/gola-utils/model"
+	"github.com/inclusi-blog/gola-utils/tracing/otlp"
+	"github.com/inclusi-blog/gola-utils/tracing/propagation"
+	"github.com/inclusi-blog/gola-utils/tracing/tracefile"
 	"github.com/sirupsen/logrus"
 	"net/http"
+	"net/url"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/jmoiron/sqlx"
@@ -24,7 +30,39 @@ var (
 	SqlOpenFunc                                   = sql.Open
 )
 
-func Init(serviceName string, ocAgent string) (*ocagent.Exporter, error) {
+// FileExporterScheme selects the file exporter in Init instead of the agent, for example
+// "file:///tmp/traces.json?format=jaeger". The format is zipkin by default.
+const FileExporterScheme = "file://"
+
+// Exporter is the exporter started by Init, the agent exporter or the file exporter.
+// Stop it on shutdown to flush the spans not exported yet.
+type Exporter interface {
+	trace.Exporter
+	Flush()
+	Stop() error
+}
+
+// Init exports spans to the ocAgent address, or to a file as described at FileExporterScheme.
+func Init(serviceName string, ocAgent string) (Exporter, error) {
+	return InitWithSampling(serviceName, ocAgent, model.SamplingConfig{})
+}
+
+// InitWithSampling works like Init and samples traces as configured instead of always.
+// Rules apply to the requests traced by WithTracing, other root spans use the default
+// sampler. An invalid config is returned as error before connecting to the agent.
+func InitWithSampling(serviceName string, ocAgent string, config model.SamplingConfig) (Exporter, error) {
+	s, err := newSampler(config)
+	if err != nil {
+		return nil, err
+	}
+	if strings.HasPrefix(ocAgent, FileExporterScheme) {
+		fileExporter, err := newFileExporter(serviceName, ocAgent)
+		if err != nil {
+			return nil, err
+		}
+		register(fileExporter, s)
+		return fileExporter, nil
+	}
 	oce, err := ocagent.NewExporter(
 		ocagent.WithInsecure(),
 		ocagent.WithReconnectionPeriod(1*time.Second),
@@ -35,24 +73,89 @@ func Init(serviceName string, ocAgent string) (*ocagent.Exporter, error) {
 		}))
 	if err != nil {
 		logrus.Errorf("Error occurred while connecting to oc agent exporter %v", err)
+		return nil, err
+	}
+	register(oce, s)
+
+	return oce, nil
+}
+
+// InitOTLP works like InitWithSampling and exports spans to an OTLP collector over gRPC
+// or HTTP. Span names, attributes and annotations are exported as recorded, so it can be
+// used instead of or alongside Init while collectors are migrated. Stop the returned
+// exporter on shutdown to flush the queued spans.
+func InitOTLP(serviceName string, config model.OTLPConfig, sampling model.SamplingConfig) (Exporter, error) {
+	s, err := newSampler(sampling)
+	if err != nil {
+		return nil, err
+	}
+	exporter, err := otlp.NewExporter(serviceName, config)
+	if err != nil {
+		return nil, err
+	}
+	register(exporter, s)
+	return exporter, nil
+}
+
+// exporters fans spans out to the exporters of Init and InitOTLP. It is registered once, so
+// deferred sampling and annotation summaries handle each span once for all of them.
+type exporters struct {
+	mu   sync.RWMutex
+	list []trace.Exporter
+}
+
+var (
+	registered   = &exporters{}
+	registerOnce sync.Once
+)
+
+func (e *exporters) ExportSpan(span *trace.SpanData) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	for _, exporter := range e.list {
+		exporter.ExportSpan(span)
 	}
-	trace.RegisterExporter(oce)
+}
+
+func (e *exporters) add(exporter trace.Exporter) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.list = append(e.list, exporter)
+}
+
+// newFileExporter creates the exporter of a file address, the path may be relative.
+func newFileExporter(serviceName, address string) (*tracefile.Exporter, error) {
+	fileURL, err := url.Parse(address)
+	if err != nil {
+		return nil, fmt.Errorf("not a valid trace file address: %v", err)
+	}
+	return tracefile.NewExporter(fileURL.Host+fileURL.Path, fileURL.Query().Get("format"), serviceName)
+}
+
+func register(exporter trace.Exporter, s *sampler) {
+	registerOnce.Do(func() {
+		trace.RegisterExporter(NewDeferredSamplingExporter(logging.NewAnnotationSummaryExporter(registered)))
+		propagation.SetLocalOnly(deferred.localOnly)
+	})
+	registered.add(exporter)
+	samplers.Store(s)
 	trace.ApplyConfig(trace.Config{
-		DefaultSampler:             trace.AlwaysSample(),
+		DefaultSampler:             s.defaultSampler(),
 		MaxAnnotationEventsPerSpan: constants.TRACE_CONFIG_MAX_ANNOTATIONS,
 	})
-
-	return oce, err
 }
 
 func WithTracing(app http.Handler, healthz string) http.Handler {
 	return &ochttp.Handler{
-		Handler: app,
+		Handler:     app,
+		Propagation: propagation.Default(),
 		GetStartOptions: func(r *http.Request) trace.StartOptions {
 			startOptions := trace.StartOptions{}
 
 			if r.URL.Path == healthz {
 				startOptions.Sampler = trace.NeverSample()
+			} else if s := currentSampler(); s != nil {
+				startOptions.Sampler = s.forRequest(r.Method, r.URL.Path)
 			}
 			return startOptions
 		}}
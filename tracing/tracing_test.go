@@ -1,11 +1,18 @@
 package tracing
 
 import (
+	"context"
 	"database/sql"
 	"errors"
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/inclusi-blog/gola-utils/model"
+	"github.com/inclusi-blog/gola-utils/tracing/tracefile"
+	"github.com/inclusi-blog/gola-utils/tracing/tracetest"
 	"github.com/stretchr/testify/suite"
+	"go.opencensus.io/trace"
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"testing"
 )
 
@@ -207,3 +214,75 @@ func (suite *TracingTestSuite) TestInitSqlPostgresDBWithInstrumentationShouldRet
 	suite.Equal(dbErr, err)
 	suite.Nil(dbConn)
 }
+
+func (suite *TracingTestSuite) TestInitOTLPShouldReturnErrorForInvalidConfig() {
+	exporter, err := InitOTLP("draft-service", model.OTLPConfig{Endpoint: "localhost:4317"},
+		model.SamplingConfig{Default: model.SamplerConfig{Type: "adaptive"}})
+	suite.EqualError(err, `not a valid sampler type: "adaptive"`)
+	suite.Nil(exporter)
+
+	exporter, err = InitOTLP("draft-service", model.OTLPConfig{Endpoint: "localhost:4317", Protocol: "thrift"}, model.SamplingConfig{})
+	suite.EqualError(err, `not a valid otlp protocol: "thrift"`)
+	suite.Nil(exporter)
+}
+
+func (suite *TracingTestSuite) TestShouldExportSpansToEveryAddedExporter() {
+	fanOut := &exporters{}
+	ocAgent, otlp := tracetest.NewExporter(), tracetest.NewExporter()
+	fanOut.add(ocAgent)
+	fanOut.add(otlp)
+
+	fanOut.ExportSpan(&trace.SpanData{Name: "/api/drafts"})
+
+	suite.Equal([]string{"/api/drafts"}, ocAgent.Names())
+	suite.Equal([]string{"/api/drafts"}, otlp.Names())
+}
+
+func (suite *TracingTestSuite) TestInitShouldReturnErrorForInvalidTraceFile() {
+	exporter, err := Init("draft-service", "file:///tmp/traces.json?format=thrift")
+
+	suite.EqualError(err, `not a valid trace file format: "thrift"`)
+	suite.Nil(exporter)
+}
+
+func (suite *TracingTestSuite) TestInitShouldReturnFileExporterToStop() {
+	dir, err := ioutil.TempDir("", "tracing")
+	suite.Require().Nil(err)
+	defer os.RemoveAll(dir)
+	defer trace.ApplyConfig(trace.Config{DefaultSampler: trace.AlwaysSample()})
+
+	exporter, err := Init("draft-service", "file://"+filepath.Join(dir, "traces.json"))
+	suite.Require().Nil(err)
+	defer func() {
+		registered.mu.Lock()
+		defer registered.mu.Unlock()
+		registered.list = nil
+	}()
+	_, span := trace.StartSpan(context.Background(), "/api/drafts", trace.WithSampler(trace.AlwaysSample()))
+	span.End()
+
+	suite.IsType(&tracefile.Exporter{}, exporter)
+	suite.Nil(exporter.Stop())
+	suite.Error(exporter.Stop())
+	written, _ := ioutil.ReadFile(filepath.Join(dir, "traces.json"))
+	suite.Contains(string(written), `"name":"/api/drafts"`)
+}
+
+func (suite *TracingTestSuite) TestShouldCreateFileExporterForRelativeAndAbsolutePaths() {
+	dir, err := ioutil.TempDir("", "tracing")
+	suite.Require().Nil(err)
+	defer os.RemoveAll(dir)
+	workingDir, _ := os.Getwd()
+	defer os.Chdir(workingDir)
+	suite.Require().Nil(os.Chdir(dir))
+
+	relative, err := newFileExporter("draft-service", "file://traces.json?format=jaeger")
+	suite.Nil(err)
+	defer relative.Close()
+	absolute, err := newFileExporter("draft-service", "file://"+filepath.Join(dir, "absolute.json"))
+	suite.Nil(err)
+	defer absolute.Close()
+
+	suite.FileExists(filepath.Join(dir, "traces.json"))
+	suite.FileExists(filepath.Join(dir, "absolute.json"))
+}